@@ -0,0 +1,57 @@
+package main
+
+import (
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// Keys, coming faster than that after the last frame, don't render new frames.
+// The frame after the last of them is drawn by a tick, so the final cursor position is shown.
+const inputFrameInterval = 40 * time.Millisecond
+
+// Sent, when frames, skipped during fast input, are due.
+type frameTick struct{}
+
+// Last rendered frame, shared by copies of the model.
+type frames struct {
+	view     string
+	at       time.Time
+	interval time.Duration
+	skip     bool // input is streaming, the last frame is shown again
+	ticking  bool // frameTick is on its way
+}
+
+// Decides, whether the frame after a key is skipped. Returns tick, drawing the skipped frame later.
+func (f *frames) key() tea.Cmd {
+	wait := f.interval - time.Since(f.at)
+	if f.view == "" || wait <= 0 {
+		f.skip = false
+		return nil
+	}
+	f.skip = true
+	if f.ticking {
+		return nil
+	}
+	f.ticking = true
+	return tea.Tick(wait, func(time.Time) tea.Msg { return frameTick{} })
+}
+
+// Any other message renders the next frame.
+func (f *frames) flush() {
+	f.skip = false
+}
+
+func (f *frames) tick() {
+	f.ticking = false
+	f.skip = false
+}
+
+// Returns the last frame, if the next one is skipped, otherwise renders and remembers it.
+func (f *frames) render(draw func() string) (string, bool) {
+	if f.skip {
+		return f.view, false
+	}
+	f.view, f.at = draw(), time.Now()
+	return f.view, true
+}
@@ -3,10 +3,18 @@ package main
 import (
 	"flag"
 	"fmt"
+	"log/slog"
 	"os"
+	"path/filepath"
+	"time"
 
 	tea "github.com/charmbracelet/bubbletea"
 
+	"github.com/LeperGnome/bt/internal/config"
+	"github.com/LeperGnome/bt/internal/control"
+	"github.com/LeperGnome/bt/internal/i18n"
+	"github.com/LeperGnome/bt/internal/remote"
+	"github.com/LeperGnome/bt/internal/share"
 	"github.com/LeperGnome/bt/internal/state"
 	"github.com/LeperGnome/bt/internal/tree"
 	ui "github.com/LeperGnome/bt/internal/ui"
@@ -17,10 +25,12 @@ type model struct {
 	windowWidth  int
 	appState     *state.State
 	renderer     *ui.Renderer
+	share        *share.Server // nil - view is not shared
+	frames       *frames
 }
 
 func (m model) Init() tea.Cmd {
-	return listenFSEvents(m.appState.NodeChanges)
+	return tea.Batch(listenFSEvents(m.appState.NodeChanges), m.appState.LoadPreview())
 }
 
 func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
@@ -28,20 +38,41 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	case tea.WindowSizeMsg:
 		m.windowHeight = msg.Height
 		m.windowWidth = msg.Width
+		m.appState.SetWindowSize(msg.Height, msg.Width)
 	case tea.KeyMsg:
-		return m, m.appState.ProcessKey(msg)
+		slog.Debug("key", "key", msg.String(), "op", m.appState.OpBuf.Repr())
+		return m, tea.Batch(m.appState.ProcessKey(msg), m.frames.key())
+	case frameTick:
+		m.frames.tick()
+		return m, nil
 	case tree.NodeChange:
-		m.appState.ProcessNodeChange(msg)
-		return m, listenFSEvents(m.appState.NodeChanges)
+		m.frames.flush()
+		return m, tea.Batch(m.appState.ProcessNodeChange(msg), listenFSEvents(m.appState.NodeChanges))
+	default:
+		m.frames.flush()
+		return m, m.appState.ProcessMsg(msg)
 	}
+	m.frames.flush()
 	return m, nil
 }
 func (m model) View() string {
-	return m.renderer.Render(m.appState, m.windowHeight, m.windowWidth)
+	view, fresh := m.frames.render(func() string {
+		start := time.Now()
+		view := m.renderer.Render(m.appState, m.windowHeight, m.windowWidth)
+		slog.Debug("render", "took", time.Since(start), "height", m.windowHeight, "width", m.windowWidth)
+		return view
+	})
+	if fresh && m.share != nil {
+		m.share.Publish(view)
+	}
+	return view
 }
 
-func newModel(root string, pad int, style ui.Stylesheet) (model, error) {
-	s, err := state.InitState(root)
+// Frame rate cap for reduced motion, quick key repeats are coalesced into fewer redraws.
+const reducedMotionFPS = 15
+
+func newModel(fsys tree.FS, root string, pad int, style ui.Stylesheet) (model, error) {
+	s, err := state.InitStateFS(fsys, root)
 	if err != nil {
 		return model{}, err
 	}
@@ -49,6 +80,7 @@ func newModel(root string, pad int, style ui.Stylesheet) (model, error) {
 	return model{
 		appState: s,
 		renderer: renderer,
+		frames:   &frames{interval: inputFrameInterval},
 	}, nil
 }
 
@@ -59,28 +91,229 @@ func listenFSEvents(eventsChan <-chan tree.NodeChange) tea.Cmd {
 }
 
 func main() {
+	cfg, err := config.Load()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error loading config: %v\n", err)
+		os.Exit(1)
+	}
+	// hooks run shell commands, so they come from the user config, whatever a project file says
+	hooks := cfg.Hooks
+	i18n.SetLocale(i18n.Detect(cfg.Locale))
+
 	paddingPtr := flag.Uint("pad", 5, "Edge padding for top and bottom")
 	inlinePtr := flag.Bool("i", false, "In-place render (without alternate screen)")
+	cwdFilePtr := flag.String("cwd-file", "", "Write current directory to this file, when exiting with 'Q'")
+	sharePtr := flag.String("share", "", "Serve read-only live view on this address, e.g. 127.0.0.1:8765")
+	simplePtr := flag.Bool("simple", false, "Simple output for screen readers and old terminals: no colors, ASCII only, [*] marks cursor")
+	pipeFdPtr := flag.Uint("pipe-fd", 0, "Write paths to this file descriptor as they are selected with space (1 - stdout)")
+	pipeNullPtr := flag.Bool("pipe-null", false, "Terminate paths, written to -pipe-fd or printed by -pick, with NUL instead of newline")
+	pickPtr := flag.Bool("pick", false, "Pick files with enter (or space), print their paths to stdout on exit")
+	printPtr := flag.Bool("print", false, "Print tree to stdout and exit, like tree command")
+	jsonPtr := flag.Bool("json", false, "Print tree as JSON (path, type, size, mtime) and exit")
+	depthPtr := flag.Uint("depth", 0, "Depth of tree for -print and -json (0 - unlimited), levels expanded on start otherwise")
+	expandAllPtr := flag.Bool("expand-all", false, "Start with the whole tree expanded, up to 1000 directories")
+	selectPtr := flag.String("select", "", "Start with the cursor on this path, its directory becomes the root, if it's outside")
+	listenPtr := flag.String("listen", "", "Listen on this unix socket for paths to reveal, see 'bt reveal'")
+	debugPtr := flag.String("debug", "", "Write debug log to this file: keys, directory reads, operations, render timings")
+	pprofPtr := flag.String("pprof", "", "Serve pprof on this address, e.g. 127.0.0.1:6060")
+	readOnlyPtr := flag.Bool("read-only", false, "Refuse operations, changing files: delete, move, rename, paste, chmod, ...")
 	flag.Parse()
+
+	if ok, err := runSubcommand(flag.Args()); ok {
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	if *debugPtr != "" {
+		f, err := startDebugLog(*debugPtr)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error opening debug log: %v\n", err)
+			os.Exit(1)
+		}
+		defer f.Close()
+	}
+	if *pprofPtr != "" {
+		if err := startPprof(*pprofPtr); err != nil {
+			fmt.Fprintf(os.Stderr, "Error starting pprof: %v\n", err)
+			os.Exit(1)
+		}
+	}
+
 	rootPath := flag.Arg(0)
 	if rootPath == "" {
 		rootPath = "."
 	}
+	var fsys tree.FS = tree.OS
+	if remote.IsURL(rootPath) {
+		rfs, root, err := dialRemote(rootPath)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error connecting to %s: %v\n", rootPath, err)
+			os.Exit(1)
+		}
+		defer rfs.Close()
+		fsys, rootPath = rfs, root
+	} else if _, err := cfg.ApplyProject(rootPath); err != nil {
+		fmt.Fprintf(os.Stderr, "Error loading project config: %v\n", err)
+		os.Exit(1)
+	}
+	i18n.SetLocale(i18n.Detect(cfg.Locale))
 
-	m, err := newModel(rootPath, int(*paddingPtr), ui.DefaultStylesheet)
+	if (*printPtr || *jsonPtr) && fsys != tree.OS {
+		fmt.Fprintln(os.Stderr, "Error: -print and -json work only with local directories")
+		os.Exit(1)
+	}
+	if *printPtr || *jsonPtr {
+		if err := printTree(rootPath, int(*depthPtr), *jsonPtr, os.Stdout); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	theme, err := ui.ResolveTheme(cfg.Theme, cfg.Colors)
 	if err != nil {
-		fmt.Printf("Error on init: %v", err)
+		fmt.Fprintf(os.Stderr, "Error loading theme: %v\n", err)
 		os.Exit(1)
 	}
 
+	style := ui.NewStylesheet(theme)
+	if *simplePtr || cfg.Simple {
+		style = ui.NewSimpleStylesheet()
+	}
+	m, err := newModel(fsys, rootPath, int(*paddingPtr), style)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error on init: %v\n", err)
+		os.Exit(1)
+	}
+	m.appState.Open = cfg.Open
+	m.appState.Previewers = cfg.Previewers
+	m.appState.PreviewLimit = cfg.PreviewLimit
+	m.appState.SendCommands = cfg.SendCommands
+	m.appState.Protected = cfg.Protected
+	m.appState.ReadOnly = *readOnlyPtr
+	m.appState.Hooks = hooks
+	m.appState.PreviewToggle = cfg.Preview
+	m.appState.LineNumbers = cfg.LineNumbers
+	m.appState.WrapLines = cfg.Wrap
+	m.appState.Columns = cfg.Columns
+	m.appState.Pick = *pickPtr
+	m.appState.SetNameOrder(tree.NameOrder{Natural: cfg.Sort.Natural, CaseSensitive: cfg.Sort.CaseSensitive})
+	m.renderer.ReducedMotion = cfg.ReducedMotion
+	m.renderer.Names, err = ui.ResolveNameColors(os.Getenv("LS_COLORS"), cfg.LsColors)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error loading config: %v\n", err)
+		os.Exit(1)
+	}
+	if cfg.SplitRatio != 0 {
+		m.appState.SetSplitRatio(cfg.SplitRatio)
+	}
+	switch {
+	case *expandAllPtr:
+		m.appState.ExpandRoot(0)
+	case *depthPtr > 0:
+		m.appState.ExpandRoot(int(*depthPtr))
+	}
+	if *selectPtr != "" {
+		if _, err := fsys.Lstat(*selectPtr); err != nil {
+			fmt.Fprintf(os.Stderr, "Error on init: %v\n", err)
+			os.Exit(1)
+		}
+		m.appState.ProcessMsg(state.RevealRequest{Path: *selectPtr})
+	}
+
+	if *sharePtr != "" {
+		m.share = share.New()
+		if err := m.share.Start(*sharePtr); err != nil {
+			fmt.Fprintf(os.Stderr, "Error starting share: %v\n", err)
+			os.Exit(1)
+		}
+	}
+
 	opts := []tea.ProgramOption{}
+	if cfg.ReducedMotion {
+		opts = append(opts, tea.WithFPS(reducedMotionFPS))
+		m.frames.interval = time.Second / reducedMotionFPS
+	}
+	sep := byte('\n')
+	if *pipeNullPtr {
+		sep = 0
+	}
+	if *pipeFdPtr != 0 {
+		out, err := openPipe(*pipeFdPtr)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error opening pipe: %v\n", err)
+			os.Exit(1)
+		}
+		m.appState.SelectionSink = pipeSink(out, sep)
+	}
+	if *pipeFdPtr == 1 || *pickPtr {
+		// stdout is taken by paths, drawing on the terminal directly
+		tty, err := os.OpenFile("/dev/tty", os.O_WRONLY, 0)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error opening terminal: %v\n", err)
+			os.Exit(1)
+		}
+		defer tty.Close()
+		opts = append(opts, tea.WithOutput(tty))
+	}
 	if !*inlinePtr {
 		opts = append(opts, tea.WithAltScreen())
 	}
 
 	p := tea.NewProgram(m, opts...)
-	if _, err := p.Run(); err != nil {
-		fmt.Printf("Error: %v", err)
+	if *listenPtr != "" {
+		srv, err := control.Listen(*listenPtr, func(path string) {
+			p.Send(state.RevealRequest{Path: path})
+		})
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error listening: %v\n", err)
+			os.Exit(1)
+		}
+		defer srv.Close()
+	}
+	final, err := p.Run()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 		os.Exit(1)
 	}
+	if err := final.(model).appState.SaveSession(); err != nil {
+		fmt.Fprintf(os.Stderr, "Error saving session: %v\n", err)
+	}
+	if *cwdFilePtr != "" {
+		if err := writeCwdFile(*cwdFilePtr, final.(model).appState); err != nil {
+			fmt.Fprintf(os.Stderr, "Error writing cwd file: %v\n", err)
+			os.Exit(1)
+		}
+	}
+	if *pickPtr {
+		picked := final.(model).appState.PickedPaths()
+		if len(picked) == 0 {
+			os.Exit(1) // nothing is picked, same as a cancelled picker
+		}
+		write := pipeSink(os.Stdout, sep)
+		for _, p := range picked {
+			if err := write(p); err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				os.Exit(1)
+			}
+		}
+	}
+}
+
+// Writes current directory to path, if user asked to cd on exit.
+// File is always truncated, so shell wrapper doesn't pick up stale value.
+func writeCwdFile(path string, s *state.State) error {
+	dir := ""
+	// remote path means nothing to local shell
+	if s.CdOnExit && s.Tree.Local() {
+		abs, err := filepath.Abs(s.Tree.CurrentDir.Path)
+		if err != nil {
+			return err
+		}
+		dir = abs
+	}
+	return os.WriteFile(path, []byte(dir), 0o644)
 }
@@ -0,0 +1,31 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// Returns file for the descriptor, that was passed to bt by the shell, e.g. `3>file`.
+func openPipe(fd uint) (*os.File, error) {
+	f := os.NewFile(uintptr(fd), fmt.Sprintf("fd%d", fd))
+	if f == nil {
+		return nil, fmt.Errorf("bad file descriptor %d", fd)
+	}
+	if _, err := f.Stat(); err != nil {
+		return nil, fmt.Errorf("file descriptor %d is not open: %w", fd, err)
+	}
+	return f, nil
+}
+
+// Returns selection sink, that writes absolute paths to out, each followed by sep.
+func pipeSink(out *os.File, sep byte) func(string) error {
+	return func(path string) error {
+		abs, err := filepath.Abs(path)
+		if err != nil {
+			return err
+		}
+		_, err = out.Write(append([]byte(abs), sep))
+		return err
+	}
+}
@@ -0,0 +1,87 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"time"
+
+	"github.com/LeperGnome/bt/internal/tree"
+)
+
+type printEntry struct {
+	Path     string       `json:"path"`
+	Type     string       `json:"type"` // dir, file, symlink or other
+	Size     int64        `json:"size"`
+	ModTime  time.Time    `json:"mtime"`
+	Target   string       `json:"target,omitempty"` // symlink target
+	Children []printEntry `json:"children,omitempty"`
+}
+
+// Prints tree of root down to depth levels (0 - unlimited), like `tree` command or as JSON.
+func printTree(root string, depth int, asJSON bool, out io.Writer) error {
+	node, err := tree.Walk(root, depth, func(path string, err error) {
+		fmt.Fprintf(os.Stderr, "bt: %v\n", err)
+	})
+	if err != nil {
+		return err
+	}
+	if asJSON {
+		return writeJSON(out, toPrintEntry(node))
+	}
+	w := bufio.NewWriter(out)
+	fmt.Fprintln(w, root)
+	dirs, files := printChildren(w, node, "")
+	fmt.Fprintf(w, "\n%d directories, %d files\n", dirs, files)
+	return w.Flush()
+}
+
+// Prints children of n with tree-like indentation. Returns counts of printed directories and files.
+func printChildren(w io.Writer, n *tree.Node, prefix string) (int, int) {
+	dirs, files := 0, 0
+	for i, ch := range n.Children {
+		branch, indent := "├── ", "│   "
+		if i == len(n.Children)-1 {
+			branch, indent = "└── ", "    "
+		}
+		name := ch.Info.Name()
+		if ch.Info.Mode()&fs.ModeSymlink != 0 {
+			if target, err := os.Readlink(ch.Path); err == nil {
+				name += " -> " + target
+			}
+		}
+		fmt.Fprintln(w, prefix+branch+name)
+		if !ch.IsDir() {
+			files++
+			continue
+		}
+		dirs++
+		d, f := printChildren(w, ch, prefix+indent)
+		dirs, files = dirs+d, files+f
+	}
+	return dirs, files
+}
+
+func toPrintEntry(n *tree.Node) printEntry {
+	e := printEntry{
+		Path:    n.Path,
+		Type:    "other",
+		Size:    n.Info.Size(),
+		ModTime: n.Info.ModTime(),
+	}
+	switch mode := n.Info.Mode(); {
+	case mode&fs.ModeSymlink != 0:
+		e.Type = "symlink"
+		e.Target, _ = os.Readlink(n.Path)
+	case mode.IsDir():
+		e.Type = "dir"
+	case mode.IsRegular():
+		e.Type = "file"
+	}
+	for _, ch := range n.Children {
+		e.Children = append(e.Children, toPrintEntry(ch))
+	}
+	return e
+}
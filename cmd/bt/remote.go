@@ -0,0 +1,40 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"golang.org/x/term"
+
+	"github.com/LeperGnome/bt/internal/remote"
+)
+
+// Connects to the remote address and returns its file system with the resolved root.
+func dialRemote(addr string) (*remote.FS, string, error) {
+	u, err := remote.Parse(addr)
+	if err != nil {
+		return nil, "", err
+	}
+	fsys, err := remote.Dial(u, askPassword)
+	if err != nil {
+		return nil, "", err
+	}
+	root := u.Path
+	if root == "" {
+		root = "." // home directory
+	}
+	root, err = fsys.EvalSymlinks(root)
+	if err != nil {
+		fsys.Close()
+		return nil, "", err
+	}
+	return fsys, root, nil
+}
+
+// Asks for password on the terminal, before UI is started.
+func askPassword(question string) (string, error) {
+	fmt.Fprint(os.Stderr, question)
+	password, err := term.ReadPassword(int(os.Stdin.Fd()))
+	fmt.Fprintln(os.Stderr)
+	return string(password), err
+}
@@ -0,0 +1,149 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/LeperGnome/bt/internal/control"
+	"github.com/LeperGnome/bt/internal/state"
+)
+
+type actionEntry struct {
+	ID          state.ActionID `json:"id"`
+	Description string         `json:"description"`
+	Keys        []string       `json:"keys"`
+}
+
+type keyEntry struct {
+	Key    string         `json:"key"`
+	Action state.ActionID `json:"action"`
+}
+
+// Runs subcommand if args start with one. Returns false if args are not a subcommand.
+func runSubcommand(args []string) (bool, error) {
+	if len(args) == 0 {
+		return false, nil
+	}
+	switch args[0] {
+	case "reveal":
+		if len(args) != 3 {
+			return true, fmt.Errorf("usage: bt reveal <socket> <path>")
+		}
+		return true, control.Reveal(args[1], args[2])
+	case "shell-init":
+		if len(args) != 2 {
+			return true, fmt.Errorf("usage: bt shell-init <bash|zsh|fish>")
+		}
+		return true, shellInit(args[1], os.Stdout)
+	}
+	if len(args) < 2 {
+		return false, nil
+	}
+	switch args[0] + " " + args[1] {
+	case "keymap export":
+		return true, keymapExport(args[2:], os.Stdout)
+	case "actions list":
+		return true, actionsList(args[2:], os.Stdout)
+	}
+	return false, nil
+}
+
+func keymapExport(args []string, out io.Writer) error {
+	fset := flag.NewFlagSet("keymap export", flag.ContinueOnError)
+	asJSON := fset.Bool("json", false, "Output as JSON")
+	if err := fset.Parse(args); err != nil {
+		return err
+	}
+	km := state.DefaultKeymap
+
+	entries := []keyEntry{}
+	for _, id := range state.Actions {
+		for _, k := range km[id] {
+			entries = append(entries, keyEntry{Key: k, Action: id})
+		}
+	}
+	if *asJSON {
+		return writeJSON(out, entries)
+	}
+	w := tabwriter.NewWriter(out, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "KEY\tACTION")
+	for _, e := range entries {
+		fmt.Fprintf(w, "%s\t%s\n", e.Key, e.Action)
+	}
+	return w.Flush()
+}
+
+func actionsList(args []string, out io.Writer) error {
+	fset := flag.NewFlagSet("actions list", flag.ContinueOnError)
+	asJSON := fset.Bool("json", false, "Output as JSON")
+	if err := fset.Parse(args); err != nil {
+		return err
+	}
+	km := state.DefaultKeymap
+
+	entries := []actionEntry{}
+	for _, id := range state.Actions {
+		keys := km[id]
+		if keys == nil {
+			keys = []string{}
+		}
+		entries = append(entries, actionEntry{ID: id, Description: id.Description(), Keys: keys})
+	}
+	if *asJSON {
+		return writeJSON(out, entries)
+	}
+	w := tabwriter.NewWriter(out, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "ACTION\tKEYS\tDESCRIPTION")
+	for _, e := range entries {
+		fmt.Fprintf(w, "%s\t%s\t%s\n", e.ID, strings.Join(e.Keys, ", "), e.Description)
+	}
+	return w.Flush()
+}
+
+func writeJSON(out io.Writer, v any) error {
+	enc := json.NewEncoder(out)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
+
+const posixShellWrapper = `bt() {
+	local tmp dir
+	tmp="$(mktemp)"
+	command bt -cwd-file="$tmp" "$@"
+	dir="$(cat "$tmp")"
+	rm -f "$tmp"
+	if [ -n "$dir" ] && [ -d "$dir" ] && [ "$dir" != "$PWD" ]; then
+		cd "$dir" || return
+	fi
+}
+`
+
+const fishShellWrapper = `function bt
+	set -l tmp (mktemp)
+	command bt -cwd-file=$tmp $argv
+	set -l dir (cat $tmp)
+	rm -f $tmp
+	if test -n "$dir"; and test -d "$dir"; and test "$dir" != "$PWD"
+		cd $dir
+	end
+end
+`
+
+// Prints shell function, that wraps bt and changes directory after exiting with 'Q'.
+func shellInit(shell string, out io.Writer) error {
+	switch shell {
+	case "bash", "zsh":
+		_, err := io.WriteString(out, posixShellWrapper)
+		return err
+	case "fish":
+		_, err := io.WriteString(out, fishShellWrapper)
+		return err
+	default:
+		return fmt.Errorf("unsupported shell '%s', expected one of: bash, zsh, fish", shell)
+	}
+}
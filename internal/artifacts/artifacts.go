@@ -0,0 +1,90 @@
+package artifacts
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+)
+
+// Project type with files, that identify it, and directories, that its tools regenerate.
+type Kind struct {
+	Name    string
+	Markers []string
+	Dirs    []string
+}
+
+var Kinds = []Kind{
+	{Name: "node", Markers: []string{"package.json"}, Dirs: []string{"node_modules", "dist", ".next", ".nuxt", ".parcel-cache"}},
+	{Name: "python", Markers: []string{"pyproject.toml", "setup.py", "requirements.txt"}, Dirs: []string{".venv", "venv", "__pycache__", ".pytest_cache", ".mypy_cache", ".tox", "build", "dist"}},
+	{Name: "rust", Markers: []string{"Cargo.toml"}, Dirs: []string{"target"}},
+	{Name: "maven", Markers: []string{"pom.xml"}, Dirs: []string{"target"}},
+	{Name: "gradle", Markers: []string{"build.gradle", "build.gradle.kts"}, Dirs: []string{"build", ".gradle"}},
+}
+
+// Names, that are dependency or cache directories regardless of project files next to them.
+var alwaysDirs = []string{"node_modules", ".venv", "__pycache__", ".pytest_cache", ".mypy_cache", ".tox", ".gradle", "bower_components"}
+
+// Reports, whether directory name is an artifact, given names of its siblings.
+func Match(name string, siblings []string) bool {
+	if slices.Contains(alwaysDirs, name) {
+		return true
+	}
+	for _, k := range Kinds {
+		if slices.Contains(k.Dirs, name) && slices.ContainsFunc(k.Markers, func(m string) bool { return slices.Contains(siblings, m) }) {
+			return true
+		}
+	}
+	return false
+}
+
+// Same as Match, but looks for project markers next to path on disk.
+func IsArtifactDir(path string) bool {
+	name := filepath.Base(path)
+	if slices.Contains(alwaysDirs, name) {
+		return true
+	}
+	dir := filepath.Dir(path)
+	for _, k := range Kinds {
+		if slices.Contains(k.Dirs, name) && hasMarker(dir, k) {
+			return true
+		}
+	}
+	return false
+}
+
+// Returns project kinds, detected in dir.
+func Detect(dir string) []Kind {
+	kinds := []Kind{}
+	for _, k := range Kinds {
+		if hasMarker(dir, k) {
+			kinds = append(kinds, k)
+		}
+	}
+	return kinds
+}
+
+// Returns existing artifact directories of detected projects directly in dir.
+func Find(dir string) []string {
+	found := []string{}
+	for _, k := range Detect(dir) {
+		for _, name := range k.Dirs {
+			p := filepath.Join(dir, name)
+			if slices.Contains(found, p) {
+				continue
+			}
+			if info, err := os.Lstat(p); err == nil && info.IsDir() {
+				found = append(found, p)
+			}
+		}
+	}
+	return found
+}
+
+func hasMarker(dir string, k Kind) bool {
+	for _, m := range k.Markers {
+		if _, err := os.Stat(filepath.Join(dir, m)); err == nil {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,115 @@
+package bookmarks
+
+import (
+	"encoding/json"
+	"errors"
+	"path/filepath"
+	"slices"
+
+	"github.com/LeperGnome/bt/internal/persist"
+)
+
+// Schema version of the bookmarks file.
+const schema = 1
+
+var migrations = map[int]persist.Migration{
+	// before versioning, store was written as is
+	0: func(data json.RawMessage) (json.RawMessage, error) { return data, nil },
+}
+
+// Store keeps bookmarks, keyed by a single character, and persists them to a file.
+// A key is either a directory bookmark or a file anchor, never both.
+type Store struct {
+	Dirs    map[string]string `json:"dirs"`
+	Anchors map[string]Anchor `json:"anchors"`
+	file    *persist.File     // nil - not persisted
+}
+
+// Anchor points to a line of a file.
+type Anchor struct {
+	Path string `json:"path"`
+	Line int    `json:"line"` // 1-based
+	Note string `json:"note,omitempty"`
+}
+
+// Loads bookmarks from path. Missing file results in an empty store.
+// Damaged file is quarantined and store starts empty. File of a newer schema
+// is left untouched and store is not persisted.
+func Load(path string) (*Store, error) {
+	s := &Store{Dirs: map[string]string{}, Anchors: map[string]Anchor{}}
+	if path == "" {
+		return s, nil
+	}
+	s.file = &persist.File{Path: path, Schema: schema, Migrations: migrations}
+	_, err := s.file.Load(s)
+	var newer *persist.NewerSchemaError
+	if errors.As(err, &newer) {
+		s.file = nil
+	}
+	if s.Dirs == nil {
+		s.Dirs = map[string]string{}
+	}
+	if s.Anchors == nil {
+		s.Anchors = map[string]Anchor{}
+	}
+	return s, err
+}
+
+// Bookmarks directory under key and saves the store.
+func (s *Store) SetDir(key, dir string) error {
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		return err
+	}
+	s.Dirs[key] = abs
+	delete(s.Anchors, key)
+	return s.save()
+}
+
+// Anchors file line under key and saves the store.
+func (s *Store) SetAnchor(key string, a Anchor) error {
+	abs, err := filepath.Abs(a.Path)
+	if err != nil {
+		return err
+	}
+	a.Path = abs
+	s.Anchors[key] = a
+	delete(s.Dirs, key)
+	return s.save()
+}
+
+func (s *Store) Anchor(key string) (Anchor, bool) {
+	a, ok := s.Anchors[key]
+	return a, ok
+}
+
+func (s *Store) Dir(key string) (string, bool) {
+	dir, ok := s.Dirs[key]
+	return dir, ok
+}
+
+func (s *Store) Delete(key string) error {
+	delete(s.Dirs, key)
+	delete(s.Anchors, key)
+	return s.save()
+}
+
+// Returns sorted keys of directory bookmarks and anchors.
+func (s *Store) Keys() []string {
+	keys := make([]string, 0, len(s.Dirs)+len(s.Anchors))
+	for k := range s.Dirs {
+		keys = append(keys, k)
+	}
+	for k := range s.Anchors {
+		keys = append(keys, k)
+	}
+	slices.Sort(keys)
+	return keys
+}
+
+func (s *Store) save() error {
+	if s.file == nil {
+		return nil
+	}
+	return s.file.Save(s)
+}
@@ -0,0 +1,221 @@
+package cleanup
+
+import (
+	"cmp"
+	"context"
+	"crypto/sha256"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/LeperGnome/bt/internal/artifacts"
+	t "github.com/LeperGnome/bt/internal/tree"
+)
+
+type Reason int
+
+// Reasons in order of priority: path is reported only for the first matching one.
+const (
+	ReasonCache Reason = iota
+	ReasonDuplicate
+	ReasonLarge
+	ReasonOld
+)
+
+const (
+	LargeSize   int64 = 100 << 20
+	OldAge            = 365 * 24 * time.Hour
+	MinOldSize  int64 = 1 << 20
+	MinDupeSize int64 = 1 << 10
+)
+
+// Directory names, that only hold regenerable caches.
+var cacheDirs = []string{"__pycache__", ".pytest_cache", ".mypy_cache", ".ruff_cache", ".cache", ".sass-cache"}
+
+// File name patterns of temporary and cache files.
+var cacheFiles = []string{"*.pyc", "*.tmp", "*.swp", "*~", ".DS_Store", "Thumbs.db"}
+
+type Candidate struct {
+	Path string
+	Size int64
+	Note string // e.g. path of the kept original for duplicates
+}
+
+type Group struct {
+	Reason     Reason
+	Candidates []Candidate
+}
+
+// Space, freed by deleting all candidates in group.
+func (g Group) Savings() int64 {
+	var total int64
+	for _, c := range g.Candidates {
+		total += c.Size
+	}
+	return total
+}
+
+type Report struct {
+	Root   string
+	Groups []Group // only non-empty, ordered by reason
+}
+
+// Walks root and collects deletion candidates. Version control directories and
+// files of other users are never inspected.
+func Scan(ctx context.Context, root string) (*Report, error) {
+	byReason := map[Reason][]Candidate{}
+	bySize := map[int64][]string{}
+	linked := map[t.FileID]struct{}{} // files, already met by another hardlink
+	now := time.Now()
+
+	err := filepath.WalkDir(root, func(p string, d fs.DirEntry, err error) error {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return ctxErr
+		}
+		if err != nil {
+			if d != nil && d.IsDir() && p != root {
+				return fs.SkipDir
+			}
+			return nil
+		}
+		name := d.Name()
+		if d.IsDir() {
+			if p == root {
+				return nil
+			}
+			if name == ".git" || name == ".hg" || name == ".svn" {
+				return fs.SkipDir
+			}
+			if info, err := d.Info(); err == nil && ownedByOther(info) {
+				return fs.SkipDir
+			}
+			if slices.Contains(cacheDirs, name) || artifacts.IsArtifactDir(p) {
+				size, err := t.DirSize(ctx, p)
+				if err != nil {
+					return err
+				}
+				byReason[ReasonCache] = append(byReason[ReasonCache], Candidate{Path: p, Size: size})
+				return fs.SkipDir
+			}
+			return nil
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil || ownedByOther(info) {
+			return nil
+		}
+		size := info.Size()
+		switch {
+		case matchesAny(name, cacheFiles):
+			byReason[ReasonCache] = append(byReason[ReasonCache], Candidate{Path: p, Size: size})
+			return nil
+		case size >= LargeSize:
+			byReason[ReasonLarge] = append(byReason[ReasonLarge], Candidate{Path: p, Size: size})
+		case size >= MinOldSize && now.Sub(info.ModTime()) > OldAge:
+			byReason[ReasonOld] = append(byReason[ReasonOld], Candidate{Path: p, Size: size, Note: info.ModTime().Format(time.DateOnly)})
+		}
+		if size >= MinDupeSize {
+			// hardlinks share content, deleting one of them frees nothing
+			if id, ok := t.FileIDOf(info); ok {
+				if _, dup := linked[id]; dup {
+					return nil
+				}
+				linked[id] = struct{}{}
+			}
+			bySize[size] = append(bySize[size], p)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	dupes, err := findDuplicates(ctx, bySize)
+	if err != nil {
+		return nil, err
+	}
+	byReason[ReasonDuplicate] = dupes
+	// duplicates take priority over large and old files
+	for _, r := range []Reason{ReasonLarge, ReasonOld} {
+		byReason[r] = slices.DeleteFunc(byReason[r], func(c Candidate) bool {
+			return slices.ContainsFunc(dupes, func(d Candidate) bool { return d.Path == c.Path })
+		})
+	}
+
+	report := &Report{Root: root}
+	for _, r := range []Reason{ReasonCache, ReasonDuplicate, ReasonLarge, ReasonOld} {
+		cands := byReason[r]
+		if len(cands) == 0 {
+			continue
+		}
+		slices.SortFunc(cands, func(a, b Candidate) int {
+			if c := cmp.Compare(b.Size, a.Size); c != 0 {
+				return c
+			}
+			return strings.Compare(a.Path, b.Path)
+		})
+		report.Groups = append(report.Groups, Group{Reason: r, Candidates: cands})
+	}
+	return report, nil
+}
+
+// Hashes files of equal size. All copies but the first (by path) become candidates.
+func findDuplicates(ctx context.Context, bySize map[int64][]string) ([]Candidate, error) {
+	dupes := []Candidate{}
+	for size, paths := range bySize {
+		if len(paths) < 2 {
+			continue
+		}
+		byHash := map[[sha256.Size]byte][]string{}
+		for _, p := range paths {
+			if err := ctx.Err(); err != nil {
+				return nil, err
+			}
+			sum, err := hashFile(p)
+			if err != nil {
+				continue
+			}
+			byHash[sum] = append(byHash[sum], p)
+		}
+		for _, same := range byHash {
+			if len(same) < 2 {
+				continue
+			}
+			slices.Sort(same)
+			for _, p := range same[1:] {
+				dupes = append(dupes, Candidate{Path: p, Size: size, Note: same[0]})
+			}
+		}
+	}
+	return dupes, nil
+}
+
+func hashFile(path string) ([sha256.Size]byte, error) {
+	var sum [sha256.Size]byte
+	f, err := os.Open(path)
+	if err != nil {
+		return sum, err
+	}
+	defer f.Close()
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return sum, err
+	}
+	copy(sum[:], h.Sum(nil))
+	return sum, nil
+}
+
+func matchesAny(name string, patterns []string) bool {
+	for _, p := range patterns {
+		if ok, _ := filepath.Match(p, name); ok {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,9 @@
+//go:build !unix
+
+package cleanup
+
+import "io/fs"
+
+func ownedByOther(info fs.FileInfo) bool {
+	return false
+}
@@ -0,0 +1,18 @@
+//go:build unix
+
+package cleanup
+
+import (
+	"io/fs"
+	"os"
+	"syscall"
+)
+
+// Reports, whether file belongs to another user. Such files are never suggested.
+func ownedByOther(info fs.FileInfo) bool {
+	st, ok := info.Sys().(*syscall.Stat_t)
+	if !ok {
+		return false
+	}
+	return int(st.Uid) != os.Getuid()
+}
@@ -0,0 +1,88 @@
+package clipboard
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+)
+
+// Clipboard puts text into the system clipboard.
+type Clipboard interface {
+	Copy(text string) error
+}
+
+// OSC52 asks the terminal to set clipboard with an escape sequence.
+// Works over SSH, if the terminal supports it.
+type OSC52 struct {
+	Out  io.Writer
+	Tmux bool // wrap sequence, so tmux passes it to the outer terminal
+}
+
+func (c OSC52) Copy(text string) error {
+	seq := fmt.Sprintf("\x1b]52;c;%s\x07", base64.StdEncoding.EncodeToString([]byte(text)))
+	if c.Tmux {
+		seq = "\x1bPtmux;\x1b" + seq + "\x1b\\"
+	}
+	_, err := io.WriteString(c.Out, seq)
+	return err
+}
+
+// Native pipes text into the clipboard tool of the OS.
+type Native struct {
+	Cmd []string
+}
+
+func (c Native) Copy(text string) error {
+	cmd := exec.Command(c.Cmd[0], c.Cmd[1:]...)
+	cmd.Stdin = strings.NewReader(text)
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("%s: %w %s", c.Cmd[0], err, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
+
+var ErrNoClipboard = errors.New("no clipboard tool found")
+
+// Returns native clipboard tool, available on this system.
+func FindNative() (Native, error) {
+	candidates := [][]string{}
+	switch runtime.GOOS {
+	case "darwin":
+		candidates = append(candidates, []string{"pbcopy"})
+	case "windows":
+		candidates = append(candidates, []string{"clip.exe"})
+	default:
+		if os.Getenv("WAYLAND_DISPLAY") != "" {
+			candidates = append(candidates, []string{"wl-copy"})
+		}
+		if os.Getenv("DISPLAY") != "" {
+			candidates = append(candidates, []string{"xclip", "-selection", "clipboard"}, []string{"xsel", "--clipboard", "--input"})
+		}
+		// WSL
+		candidates = append(candidates, []string{"clip.exe"})
+	}
+	for _, c := range candidates {
+		if _, err := exec.LookPath(c[0]); err == nil {
+			return Native{Cmd: c}, nil
+		}
+	}
+	return Native{}, ErrNoClipboard
+}
+
+// Returns native clipboard for local sessions and OSC52 over SSH
+// or when no clipboard tool is installed.
+func Default() Clipboard {
+	osc := OSC52{Out: os.Stdout, Tmux: os.Getenv("TMUX") != ""}
+	if os.Getenv("SSH_TTY") != "" || os.Getenv("SSH_CONNECTION") != "" {
+		return osc
+	}
+	if native, err := FindNative(); err == nil {
+		return native
+	}
+	return osc
+}
@@ -0,0 +1,226 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/LeperGnome/bt/pkg/paths"
+)
+
+type Config struct {
+	// Locale for UI messages, e.g. "en" or "ru". Empty - detect from environment.
+	Locale string `yaml:"locale"`
+	// What Enter does with directories and files.
+	Open Open `yaml:"open"`
+	// Show preview pane on start. Hidden preview doesn't read files at all,
+	// which keeps navigation fast on slow filesystems.
+	Preview bool `yaml:"preview"`
+	// Show line numbers of text files in preview.
+	LineNumbers bool `yaml:"line_numbers"`
+	// Wrap long lines in preview instead of cutting them at the pane width.
+	Wrap bool `yaml:"wrap"`
+	// Bytes of a file, read for its preview. Text files are read on in chunks, as they're scrolled. 0 - 10000.
+	PreviewLimit int64 `yaml:"preview_limit"`
+	// List the current directory in columns, like ls -C, when preview is hidden and the terminal is wide.
+	Columns bool `yaml:"columns"`
+	// Avoid animations and frequent redraws, for motion sensitive users and clean recordings.
+	ReducedMotion bool `yaml:"reduced_motion"`
+	// No colors and no Unicode glyphs, cursor and marks are spelled out, for screen readers and old terminals.
+	Simple bool `yaml:"simple"`
+	// Share of width, taken by the tree, when preview is shown. 0 - half.
+	SplitRatio float64 `yaml:"split_ratio"`
+	// Color theme preset: default, light or mono.
+	Theme string `yaml:"theme"`
+	// Overrides of theme colors by name, e.g. directory: "#6D74AC".
+	Colors map[string]string `yaml:"colors"`
+	// Colors of names in LS_COLORS format, e.g. "*.log=2:ex=32", applied on top of $LS_COLORS.
+	// "off" ignores both, names get theme colors only.
+	LsColors string `yaml:"ls_colors"`
+	// Order of names in directories.
+	Sort Sort `yaml:"sort"`
+	// Paths, that need their name typed to be deleted or moved, in addition to the tree root
+	// and home directory. Directories, containing them, are protected as well.
+	Protected []string `yaml:"protected_paths"`
+	// How files are previewed. The first previewer, matching a file, is used.
+	Previewers []Previewer `yaml:"previewers"`
+	// Programs, offered by tab, when selected paths are sent to one as arguments, e.g. "git add".
+	SendCommands []string `yaml:"send_commands"`
+	// Commands, run on selection, opening, deletion and entering directories.
+	Hooks Hooks `yaml:"hooks"`
+}
+
+// Order of names. Default compares names char by char, ignoring case.
+type Sort struct {
+	Natural       bool `yaml:"natural"`        // file2 before file10
+	CaseSensitive bool `yaml:"case_sensitive"` // B before a
+}
+
+// Smaller previews show next to nothing and make scrolling read the file in tiny chunks.
+const minPreviewLimit = 1024
+
+func (c Config) validate() error {
+	if err := c.Open.validate(); err != nil {
+		return err
+	}
+	if c.PreviewLimit != 0 && c.PreviewLimit < minPreviewLimit {
+		return fmt.Errorf("preview_limit: must be at least %d bytes", minPreviewLimit)
+	}
+	return validatePreviewers(c.Previewers)
+}
+
+// Returns path to user config file, respecting $XDG_CONFIG_HOME.
+func Path() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "bt", "config.yaml"), nil
+}
+
+// Returns directory for persistent bt data, respecting $XDG_DATA_HOME.
+func DataDir() (string, error) {
+	if dir := os.Getenv("XDG_DATA_HOME"); dir != "" {
+		return filepath.Join(dir, "bt"), nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, ".local", "share", "bt"), nil
+}
+
+// Name of project config, that overrides user config for a tree.
+const ProjectFile = ".bt.yaml"
+
+// Loads user config. Missing config file is not an error.
+func Load() (Config, error) {
+	cfg := Config{}
+	path, err := Path()
+	if err != nil {
+		return cfg, nil
+	}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return cfg, nil
+	}
+	if err != nil {
+		return cfg, err
+	}
+	if err := yaml.Unmarshal(data, &cfg); err != nil {
+		return cfg, err
+	}
+	if err := cfg.validate(); err != nil {
+		return cfg, err
+	}
+	return cfg, nil
+}
+
+// Settings, a project file may change: how the tree looks and what Enter does.
+// Commands (hooks, previewers, openers, send commands), name colors and protected paths
+// come from the user config only, since trees bt is opened in aren't necessarily trusted.
+type project struct {
+	Locale     string            `yaml:"locale"`
+	Theme      string            `yaml:"theme"`
+	Colors     map[string]string `yaml:"colors"`
+	Open       projectOpen       `yaml:"open"`
+	Preview    bool              `yaml:"preview"`
+	SplitRatio float64           `yaml:"split_ratio"`
+	Sort       Sort              `yaml:"sort"`
+}
+
+type projectOpen struct {
+	Dir  string            `yaml:"dir"`
+	File string            `yaml:"file"`
+	Ext  map[string]string `yaml:"ext"`
+}
+
+// Keys of the user config, a project file is refused for.
+var userOnlyKeys = []string{"hooks", "previewers", "send_commands", "protected_paths", "ls_colors"}
+
+// Applies project config, found in root or the nearest of its ancestors up to the home directory,
+// on top of c. Values, set in the project file, take precedence; maps are merged by key.
+// Returns path of the applied file, empty if there is none.
+func (c *Config) ApplyProject(root string) (string, error) {
+	path, err := findProjectFile(root)
+	if path == "" || err != nil {
+		return "", err
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return path, err
+	}
+	if err := checkProject(data); err != nil {
+		return path, fmt.Errorf("%s: %w", path, err)
+	}
+	p := project{
+		Locale:     c.Locale,
+		Theme:      c.Theme,
+		Colors:     c.Colors,
+		Open:       projectOpen{Dir: c.Open.Dir, File: c.Open.File, Ext: c.Open.Ext},
+		Preview:    c.Preview,
+		SplitRatio: c.SplitRatio,
+		Sort:       c.Sort,
+	}
+	// decoding into already filled settings keeps fields, absent in the file
+	if err := yaml.Unmarshal(data, &p); err != nil {
+		return path, fmt.Errorf("%s: %w", path, err)
+	}
+	c.Locale, c.Theme, c.Colors = p.Locale, p.Theme, p.Colors
+	c.Open.Dir, c.Open.File, c.Open.Ext = p.Open.Dir, p.Open.File, p.Open.Ext
+	c.Preview, c.SplitRatio, c.Sort = p.Preview, p.SplitRatio, p.Sort
+	if err := c.validate(); err != nil {
+		return path, fmt.Errorf("%s: %w", path, err)
+	}
+	return path, nil
+}
+
+// Refuses project file, that sets any of the user-only settings.
+func checkProject(data []byte) error {
+	var keys struct {
+		Top  map[string]yaml.Node `yaml:",inline"`
+		Open struct {
+			With yaml.Node `yaml:"with"`
+		} `yaml:"open"`
+	}
+	if err := yaml.Unmarshal(data, &keys); err != nil {
+		return err
+	}
+	for _, k := range userOnlyKeys {
+		if _, ok := keys.Top[k]; ok {
+			return fmt.Errorf("%s can be set in the user config only", k)
+		}
+	}
+	if !keys.Open.With.IsZero() {
+		return fmt.Errorf("open.with can be set in the user config only")
+	}
+	return nil
+}
+
+// Looks for project file in root and its ancestors, up to the home directory.
+// Roots outside of the home directory are looked in only.
+func findProjectFile(root string) (string, error) {
+	dir, err := filepath.Abs(root)
+	if err != nil {
+		return "", err
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		home = ""
+	}
+	for {
+		path := filepath.Join(dir, ProjectFile)
+		if _, err := os.Stat(path); err == nil {
+			return path, nil
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir || dir == home || home == "" || !paths.Within(home, parent) {
+			return "", nil
+		}
+		dir = parent
+	}
+}
@@ -0,0 +1,10 @@
+package config
+
+// Hooks are shell commands, run in background on events. The path is passed in $BT_PATH,
+// event name in $BT_EVENT and tree root in $BT_ROOT. Empty command - no hook.
+type Hooks struct {
+	OnSelect   string `yaml:"on_select"`    // cursor moved to another entry
+	OnOpen     string `yaml:"on_open"`      // file opened with enter, edit or open
+	OnDelete   string `yaml:"on_delete"`    // path deleted
+	OnEnterDir string `yaml:"on_enter_dir"` // directory became current
+}
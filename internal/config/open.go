@@ -0,0 +1,86 @@
+package config
+
+import (
+	"fmt"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// Enter behaviors.
+const (
+	OpenExpand  = "expand"  // collapse / expand directory in place
+	OpenEnter   = "enter"   // make directory current
+	OpenPreview = "preview" // show and focus file preview
+	OpenEdit    = "edit"    // open file in $EDITOR
+	OpenSystem  = "system"  // open file with system default application
+)
+
+// Open configures what Enter does with the selected node.
+type Open struct {
+	Dir  string            `yaml:"dir"`  // expand (default) or enter
+	File string            `yaml:"file"` // preview (default), edit or system
+	Ext  map[string]string `yaml:"ext"`  // file behavior by extension, e.g. pdf: system
+	With []Opener          `yaml:"with"` // commands, opening files instead of the default application
+}
+
+// Opener opens matching files with a command. The first matching opener is used.
+type Opener struct {
+	Match    []string `yaml:"match"`    // name globs, e.g. "*.go", or MIME types, e.g. "video/*"
+	Command  string   `yaml:"command"`  // {} is replaced with the file path, or path is appended; $VARS are expanded
+	Terminal bool     `yaml:"terminal"` // run in the terminal, like an editor, instead of in background
+}
+
+// Returns behavior for directories.
+func (o Open) ForDir() string {
+	if o.Dir == "" {
+		return OpenExpand
+	}
+	return o.Dir
+}
+
+// Returns behavior for file with the given name, extension overrides take precedence.
+func (o Open) ForFile(name string) string {
+	ext := strings.ToLower(strings.TrimPrefix(filepath.Ext(name), "."))
+	for k, v := range o.Ext {
+		if ext != "" && strings.ToLower(strings.TrimPrefix(k, ".")) == ext {
+			return v
+		}
+	}
+	if o.File == "" {
+		return OpenPreview
+	}
+	return o.File
+}
+
+func (o Open) validate() error {
+	if o.Dir != "" && o.Dir != OpenExpand && o.Dir != OpenEnter {
+		return fmt.Errorf("open.dir: unknown behavior %q, expected %s or %s", o.Dir, OpenExpand, OpenEnter)
+	}
+	files := map[string]string{"open.file": o.File}
+	for k, v := range o.Ext {
+		files["open.ext."+k] = v
+	}
+	for field, v := range files {
+		switch v {
+		case "", OpenPreview, OpenEdit, OpenSystem:
+		default:
+			return fmt.Errorf("%s: unknown behavior %q, expected %s, %s or %s", field, v, OpenPreview, OpenEdit, OpenSystem)
+		}
+	}
+	for i, o := range o.With {
+		field := fmt.Sprintf("open.with[%d]", i)
+		if len(o.Match) == 0 {
+			return fmt.Errorf("%s.match: at least one pattern expected", field)
+		}
+		for _, m := range o.Match {
+			if _, err := path.Match(m, ""); err != nil {
+				return fmt.Errorf("%s.match: bad pattern %q", field, m)
+			}
+		}
+		if strings.TrimSpace(o.Command) == "" {
+			return fmt.Errorf("%s.command: required", field)
+		}
+	}
+	return nil
+}
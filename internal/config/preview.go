@@ -0,0 +1,62 @@
+package config
+
+import (
+	"fmt"
+	"path"
+	"time"
+)
+
+// Preview kinds, a previewer chain is made of.
+const (
+	PreviewText      = "text"      // plain text, markdown is rendered
+	PreviewHighlight = "highlight" // source code with syntax highlighting
+	PreviewCommand   = "command"   // output of an external command
+	PreviewSummary   = "summary"   // format, size and metadata of known binary formats
+	PreviewHex       = "hex"       // hex dump, works for any file
+)
+
+// DefaultPreviewChain is used for files, not matched by any previewer.
+var DefaultPreviewChain = []string{PreviewText, PreviewSummary, PreviewHex}
+
+// Previewer decides how matching files are previewed. Kinds of the chain are tried
+// in order, until one of them can show the file.
+type Previewer struct {
+	Match   []string      `yaml:"match"`    // name globs, e.g. "*.go", or MIME types, e.g. "image/*"
+	Chain   []string      `yaml:"chain"`    // text, highlight, command, summary or hex
+	Command string        `yaml:"command"`  // for command kind, {} is replaced with the file path
+	MaxSize int64         `yaml:"max_size"` // bytes, larger files skip this previewer; 0 - no limit
+	Timeout time.Duration `yaml:"timeout"`  // command is killed after that, e.g. 5s; 0 - 2 seconds
+}
+
+func validatePreviewers(ps []Previewer) error {
+	for i, p := range ps {
+		field := fmt.Sprintf("previewers[%d]", i)
+		if len(p.Match) == 0 {
+			return fmt.Errorf("%s.match: at least one pattern expected", field)
+		}
+		for _, m := range p.Match {
+			if _, err := path.Match(m, ""); err != nil {
+				return fmt.Errorf("%s.match: bad pattern %q", field, m)
+			}
+		}
+		if p.Timeout < 0 {
+			return fmt.Errorf("%s.timeout: must not be negative", field)
+		}
+		if len(p.Chain) == 0 {
+			return fmt.Errorf("%s.chain: at least one kind expected", field)
+		}
+		for _, k := range p.Chain {
+			switch k {
+			case PreviewText, PreviewHighlight, PreviewSummary, PreviewHex:
+			case PreviewCommand:
+				if p.Command == "" {
+					return fmt.Errorf("%s.command: required by command kind", field)
+				}
+			default:
+				return fmt.Errorf("%s.chain: unknown kind %q, expected %s, %s, %s, %s or %s",
+					field, k, PreviewText, PreviewHighlight, PreviewCommand, PreviewSummary, PreviewHex)
+			}
+		}
+	}
+	return nil
+}
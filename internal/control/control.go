@@ -0,0 +1,85 @@
+package control
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Server accepts "reveal this path" requests from external tools on a unix socket.
+// Protocol is one path per line, e.g.: echo "$PWD/main.go" | nc -U /tmp/bt.sock
+type Server struct {
+	ln   net.Listener
+	path string
+}
+
+// Starts listening on socket path in background, calling reveal for every received path.
+// Stale socket of a crashed instance is replaced, live one is an error, and so is any other file.
+func Listen(path string, reveal func(string)) (*Server, error) {
+	if info, err := os.Lstat(path); err == nil {
+		if info.Mode()&fs.ModeSocket == 0 {
+			return nil, fmt.Errorf("'%s' exists and is not a socket", path)
+		}
+		if conn, err := net.DialTimeout("unix", path, time.Second); err == nil {
+			conn.Close()
+			return nil, fmt.Errorf("'%s' is already used by another instance", path)
+		}
+		if err := os.Remove(path); err != nil {
+			return nil, err
+		}
+	}
+	ln, err := net.Listen("unix", path)
+	if err != nil {
+		return nil, err
+	}
+	s := &Server{ln: ln, path: path}
+	go s.serve(reveal)
+	return s, nil
+}
+
+func (s *Server) serve(reveal func(string)) {
+	for {
+		conn, err := s.ln.Accept()
+		if errors.Is(err, net.ErrClosed) {
+			return
+		}
+		if err != nil {
+			continue
+		}
+		go func() {
+			defer conn.Close()
+			scanner := bufio.NewScanner(conn)
+			for scanner.Scan() {
+				if path := strings.TrimSpace(scanner.Text()); path != "" {
+					reveal(path)
+				}
+			}
+		}()
+	}
+}
+
+// Stops listening and removes the socket.
+func (s *Server) Close() error {
+	return s.ln.Close() // unix listener unlinks the socket file
+}
+
+// Asks instance, listening on socket, to reveal path. Relative path is resolved against working directory.
+func Reveal(socket, path string) error {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return err
+	}
+	conn, err := net.DialTimeout("unix", socket, time.Second)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	_, err = fmt.Fprintln(conn, abs)
+	return err
+}
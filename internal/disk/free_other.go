@@ -0,0 +1,9 @@
+//go:build !unix
+
+// Package disk reports space of filesystems.
+package disk
+
+// Free space is not known on this platform.
+func Free(path string) (uint64, bool) {
+	return 0, false
+}
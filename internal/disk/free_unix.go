@@ -0,0 +1,15 @@
+//go:build unix
+
+// Package disk reports space of filesystems.
+package disk
+
+import "syscall"
+
+// Returns bytes, available to the user on the filesystem of path.
+func Free(path string) (uint64, bool) {
+	var st syscall.Statfs_t
+	if err := syscall.Statfs(path, &st); err != nil {
+		return 0, false
+	}
+	return uint64(st.Bavail) * uint64(st.Bsize), true
+}
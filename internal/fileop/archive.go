@@ -0,0 +1,393 @@
+package fileop
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/LeperGnome/bt/pkg/paths"
+)
+
+// Archive formats, chosen by the archive name.
+const (
+	FormatZip   = "zip"
+	FormatTarGz = "tar.gz"
+)
+
+// Returns format of the archive by its name.
+func ArchiveFormat(name string) (string, error) {
+	lower := strings.ToLower(name)
+	switch {
+	case strings.HasSuffix(lower, ".zip"):
+		return FormatZip, nil
+	case strings.HasSuffix(lower, ".tar.gz"), strings.HasSuffix(lower, ".tgz"):
+		return FormatTarGz, nil
+	}
+	return "", fmt.Errorf("%s: unknown archive format, expected .zip, .tar.gz or .tgz", filepath.Base(name))
+}
+
+// Entry writer of an archive format.
+type archiveWriter interface {
+	add(name string, info fs.FileInfo, link string) (io.Writer, error) // returns writer of the content
+	Close() error
+}
+
+// Packs paths with everything under them into dst archive, which must not exist.
+// Entries are named relative to the parent of each path. Partial archive is removed on failure.
+func Archive(ctx context.Context, srcs []string, dst string, report ReportFunc) (err error) {
+	format, err := ArchiveFormat(dst)
+	if err != nil {
+		return err
+	}
+	srcs, dst = longPaths(srcs), paths.Long(dst)
+	o := &op{ctx: ctx, report: report}
+	for _, src := range srcs {
+		if err := o.scan(src); err != nil {
+			return err
+		}
+	}
+	f, err := os.OpenFile(dst, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o644)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			os.Remove(dst)
+		}
+	}()
+	var w archiveWriter
+	if format == FormatZip {
+		w = zipWriter{zip.NewWriter(f)}
+	} else {
+		w = newTarGzWriter(f)
+	}
+	o.started = time.Now()
+	o.tick(true)
+	for _, src := range srcs {
+		if err := o.pack(w, src); err != nil {
+			w.Close()
+			f.Close()
+			return err
+		}
+	}
+	if err := w.Close(); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	o.tick(true)
+	return nil
+}
+
+func (o *op) pack(w archiveWriter, src string) error {
+	base := filepath.Dir(src)
+	return filepath.WalkDir(src, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if err := o.ctx.Err(); err != nil {
+			return err
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(base, p)
+		if err != nil {
+			return err
+		}
+		name := filepath.ToSlash(rel)
+		link := ""
+		if info.Mode()&fs.ModeSymlink != 0 {
+			if link, err = os.Readlink(p); err != nil {
+				return err
+			}
+		} else if !info.Mode().IsRegular() && !info.IsDir() {
+			return &fs.PathError{Op: "archive", Path: p, Err: fs.ErrInvalid}
+		}
+		content, err := w.add(name, info, link)
+		if err != nil {
+			return err
+		}
+		if info.Mode().IsRegular() {
+			if err := o.copyData(content, p); err != nil {
+				return err
+			}
+		}
+		if !info.IsDir() {
+			o.p.Files++
+			o.tick(false)
+		}
+		return nil
+	})
+}
+
+// Copies content of the file at path to w, counting bytes.
+func (o *op) copyData(w io.Writer, path string) error {
+	in, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	buf := make([]byte, chunkSize)
+	for {
+		if err := o.ctx.Err(); err != nil {
+			return err
+		}
+		n, err := io.CopyBuffer(w, io.LimitReader(in, chunkSize), buf)
+		o.p.Bytes += n
+		o.tick(false)
+		if err != nil {
+			return err
+		}
+		if n < chunkSize {
+			return nil
+		}
+	}
+}
+
+type zipWriter struct {
+	*zip.Writer
+}
+
+func (z zipWriter) add(name string, info fs.FileInfo, link string) (io.Writer, error) {
+	h, err := zip.FileInfoHeader(info)
+	if err != nil {
+		return nil, err
+	}
+	h.Name = name
+	if info.IsDir() {
+		h.Name += "/"
+	} else if info.Mode().IsRegular() {
+		h.Method = zip.Deflate
+	}
+	w, err := z.CreateHeader(h)
+	if err != nil {
+		return nil, err
+	}
+	if link != "" {
+		_, err = io.WriteString(w, link)
+	}
+	return w, err
+}
+
+type tarGzWriter struct {
+	gz *gzip.Writer
+	tw *tar.Writer
+}
+
+func newTarGzWriter(w io.Writer) *tarGzWriter {
+	gz := gzip.NewWriter(w)
+	return &tarGzWriter{gz: gz, tw: tar.NewWriter(gz)}
+}
+
+func (t *tarGzWriter) add(name string, info fs.FileInfo, link string) (io.Writer, error) {
+	h, err := tar.FileInfoHeader(info, link)
+	if err != nil {
+		return nil, err
+	}
+	h.Name = name
+	if info.IsDir() {
+		h.Name += "/"
+	}
+	return t.tw, t.tw.WriteHeader(h)
+}
+
+func (t *tarGzWriter) Close() error {
+	if err := t.tw.Close(); err != nil {
+		return err
+	}
+	return t.gz.Close()
+}
+
+// Unpacks src archive into dst directory, creating it, if needed. Existing files aren't replaced,
+// entries, that would land outside of dst, fail the extraction. Extracted part stays on failure.
+// Progress is counted in archive bytes: tar.gz entries are only known, once they're read.
+func Extract(ctx context.Context, src, dst string, report ReportFunc) error {
+	format, err := ArchiveFormat(src)
+	if err != nil {
+		return err
+	}
+	src, dst = paths.Long(src), paths.Long(dst)
+	f, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(dst, 0o755); err != nil {
+		return err
+	}
+	o := &op{ctx: ctx, report: report, started: time.Now()}
+	if format == FormatZip {
+		err = o.extractZip(f, info.Size(), dst)
+	} else {
+		o.p.TotalBytes = info.Size()
+		err = o.extractTarGz(&countingReader{r: f, o: o}, dst)
+	}
+	if err != nil {
+		return err
+	}
+	o.tick(true)
+	return nil
+}
+
+func (o *op) extractZip(r io.ReaderAt, size int64, dst string) error {
+	zr, err := zip.NewReader(r, size)
+	if err != nil {
+		return err
+	}
+	for _, f := range zr.File {
+		o.p.TotalBytes += int64(f.CompressedSize64)
+		if !f.FileInfo().IsDir() {
+			o.p.TotalFiles++
+		}
+	}
+	o.tick(true)
+	for _, f := range zr.File {
+		if err := o.extractZipFile(f, dst); err != nil {
+			return err
+		}
+		o.p.Bytes += int64(f.CompressedSize64)
+		o.tick(false)
+	}
+	return nil
+}
+
+func (o *op) extractZipFile(f *zip.File, dst string) error {
+	rc, err := f.Open()
+	if err != nil {
+		return err
+	}
+	defer rc.Close()
+	mode := f.Mode()
+	link := ""
+	if mode&fs.ModeSymlink != 0 {
+		target, err := io.ReadAll(io.LimitReader(rc, 4096))
+		if err != nil {
+			return err
+		}
+		link = string(target)
+	}
+	return o.extractEntry(dst, f.Name, mode, link, rc)
+}
+
+func (o *op) extractTarGz(r io.Reader, dst string) error {
+	gz, err := gzip.NewReader(r)
+	if err != nil {
+		return err
+	}
+	defer gz.Close()
+	tr := tar.NewReader(gz)
+	for {
+		h, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		switch h.Typeflag {
+		case tar.TypeReg, tar.TypeSymlink:
+			// total is unknown until the end of the stream
+			o.p.TotalFiles++
+		case tar.TypeDir:
+		default:
+			continue // hard links, devices and the like aren't extracted
+		}
+		if err := o.extractEntry(dst, h.Name, h.FileInfo().Mode(), h.Linkname, tr); err != nil {
+			return err
+		}
+	}
+}
+
+// Creates entry of the archive under dst: directory, regular file or symlink. Other kinds are skipped.
+func (o *op) extractEntry(dst, name string, mode fs.FileMode, link string, content io.Reader) error {
+	if err := o.ctx.Err(); err != nil {
+		return err
+	}
+	name = filepath.FromSlash(strings.TrimSuffix(name, "/"))
+	if !filepath.IsLocal(name) {
+		return fmt.Errorf("%s: entry leads outside of %s", name, dst)
+	}
+	if err := checkParents(dst, name); err != nil {
+		return err
+	}
+	target := filepath.Join(dst, name)
+	if err := os.MkdirAll(filepath.Dir(target), 0o755); err != nil {
+		return err
+	}
+	switch {
+	case mode.IsDir():
+		return os.MkdirAll(target, mode.Perm()|0o700)
+	case mode&fs.ModeSymlink != 0:
+		if err := os.Symlink(link, target); err != nil {
+			return err
+		}
+	case mode.IsRegular():
+		out, err := os.OpenFile(target, os.O_WRONLY|os.O_CREATE|os.O_EXCL, mode.Perm())
+		if err != nil {
+			return err
+		}
+		if _, err := io.Copy(out, content); err != nil {
+			out.Close()
+			return err
+		}
+		if err := out.Close(); err != nil {
+			return err
+		}
+	default:
+		return nil
+	}
+	o.p.Files++
+	o.tick(false)
+	return nil
+}
+
+// Fails, when a parent of the entry under dst is a symlink, e.g. extracted earlier,
+// so entries can't be written through it outside of dst.
+func checkParents(dst, name string) error {
+	dir := dst
+	parts := strings.Split(name, string(filepath.Separator))
+	for _, part := range parts[:len(parts)-1] {
+		dir = filepath.Join(dir, part)
+		info, err := os.Lstat(dir)
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if info.Mode()&fs.ModeSymlink != 0 {
+			return fmt.Errorf("%s: entry leads through symlink %s", name, dir)
+		}
+	}
+	return nil
+}
+
+// Counts bytes, read from the archive, as progress.
+type countingReader struct {
+	r io.Reader
+	o *op
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.o.p.Bytes += int64(n)
+	c.o.tick(false)
+	return n, err
+}
@@ -0,0 +1,66 @@
+package fileop
+
+import (
+	"fmt"
+	"time"
+)
+
+// Kinds of commands, recorded in the journal.
+const (
+	OpRename = "rename"
+	OpMove   = "move"
+	OpCopy   = "copy"
+	OpCreate = "create"
+	OpDelete = "delete"
+)
+
+// Command is a finished file operation: each of Paths became Targets at the same index.
+// Created and deleted paths have no targets.
+type Command struct {
+	Kind     string
+	Paths    []string
+	Targets  []string
+	Dir      bool    // created path is a directory
+	Replaced bool    // existing targets were removed first
+	Empty    bool    // only empty files and directories are deleted, see Inverse
+	Stamps   []Stamp // of copied targets, when the copy finished; deleted paths must still match them
+	Time     time.Time
+}
+
+// Summary of a file tree. Anything written, added or removed in the tree since changes it.
+type Stamp struct {
+	Entries int
+	Size    int64
+	ModTime time.Time // the latest one in the tree
+}
+
+// Reports whether the command can be undone. Deleted files and replaced targets are gone for good,
+// deletes don't go through a trash. Copies without stamps can't be told unchanged, so they stay too.
+func (c Command) Reversible() bool {
+	switch {
+	case c.Kind == OpDelete || c.Replaced:
+		return false
+	case c.Kind == OpCopy:
+		return len(c.Stamps) == len(c.Targets)
+	}
+	return true
+}
+
+// Returns command, that undoes c. Renames and moves go back, copies are deleted, while they
+// match their stamps. Created paths are deleted only while they are empty, so nothing written
+// since is lost.
+func (c Command) Inverse() (Command, error) {
+	if !c.Reversible() {
+		return Command{}, fmt.Errorf("%s of %s can't be undone", c.Kind, c.Paths[0])
+	}
+	inv := Command{Kind: c.Kind, Dir: c.Dir, Time: time.Now()}
+	switch c.Kind {
+	case OpRename, OpMove:
+		inv.Paths, inv.Targets = c.Targets, c.Paths
+	case OpCopy:
+		inv.Kind, inv.Paths, inv.Stamps = OpDelete, c.Targets, c.Stamps
+	case OpCreate:
+		inv.Kind, inv.Paths, inv.Empty = OpDelete, c.Paths, true
+	}
+	return inv, nil
+}
@@ -0,0 +1,275 @@
+package fileop
+
+import (
+	"context"
+	"errors"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/LeperGnome/bt/pkg/paths"
+)
+
+// Progress of an operation. Totals are known after the source is scanned.
+type Progress struct {
+	Files      int64
+	Bytes      int64
+	TotalFiles int64
+	TotalBytes int64
+	Elapsed    time.Duration // since the scan is finished
+}
+
+// Receives progress updates. Updates are throttled, the final one is always sent.
+type ReportFunc func(Progress)
+
+const (
+	reportInterval = 100 * time.Millisecond
+	chunkSize      = 256 * 1024
+)
+
+type op struct {
+	ctx      context.Context
+	report   ReportFunc
+	p        Progress
+	reported time.Time
+	started  time.Time
+}
+
+func (o *op) tick(force bool) {
+	if force || time.Since(o.reported) >= reportInterval {
+		o.p.Elapsed = time.Since(o.started)
+		o.reported = time.Now()
+		o.report(o.p)
+	}
+}
+
+// Returns paths, that can be passed to the system past MAX_PATH on Windows, see paths.Long.
+func longPaths(ps []string) []string {
+	long := make([]string, len(ps))
+	for i, p := range ps {
+		long[i] = paths.Long(p)
+	}
+	return long
+}
+
+// Counts files and bytes under path. Symlinks are counted as files, but not followed.
+func (o *op) scan(path string) error {
+	return filepath.WalkDir(path, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if err := o.ctx.Err(); err != nil {
+			return err
+		}
+		if d.IsDir() {
+			return nil
+		}
+		o.p.TotalFiles++
+		if d.Type().IsRegular() {
+			if info, err := d.Info(); err == nil {
+				o.p.TotalBytes += info.Size()
+			}
+		}
+		return nil
+	})
+}
+
+// Copies src file or directory to dst, which must not exist.
+// Partial copy is removed, when the operation fails or is cancelled.
+func Copy(ctx context.Context, src, dst string, report ReportFunc) error {
+	return CopyAll(ctx, []string{src}, []string{dst}, report)
+}
+
+// Copies each of srcs to dsts at the same index, see Copy. Copies, finished before a failure, stay.
+func CopyAll(ctx context.Context, srcs, dsts []string, report ReportFunc) error {
+	srcs, dsts = longPaths(srcs), longPaths(dsts)
+	o := &op{ctx: ctx, report: report}
+	for _, src := range srcs {
+		if err := o.scan(src); err != nil {
+			return err
+		}
+	}
+	o.started = time.Now()
+	o.tick(true)
+	for i, src := range srcs {
+		if err := o.copyTree(src, dsts[i]); err != nil {
+			os.RemoveAll(dsts[i])
+			return err
+		}
+	}
+	o.tick(true)
+	return nil
+}
+
+// Moves src to dst, which must not exist. Within a filesystem it's a rename,
+// across filesystems src is copied and removed afterwards.
+func Move(ctx context.Context, src, dst string, report ReportFunc) error {
+	return MoveAll(ctx, []string{src}, []string{dst}, report)
+}
+
+// Moves each of srcs to dsts at the same index, see Move. Renames go first,
+// sources on other filesystems are copied together, so progress covers all of them.
+func MoveAll(ctx context.Context, srcs, dsts []string, report ReportFunc) error {
+	srcs, dsts = longPaths(srcs), longPaths(dsts)
+	var copySrcs, copyDsts []string
+	for i, src := range srcs {
+		err := os.Rename(src, dsts[i])
+		if crossDevice(err) {
+			copySrcs, copyDsts = append(copySrcs, src), append(copyDsts, dsts[i])
+		} else if err != nil {
+			return err
+		}
+	}
+	if len(copySrcs) == 0 {
+		return nil
+	}
+	if err := CopyAll(ctx, copySrcs, copyDsts, report); err != nil {
+		return err
+	}
+	for _, src := range copySrcs {
+		if err := os.RemoveAll(src); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Removes paths recursively, reporting removed files.
+// Cancelled removal leaves the rest of files in place.
+func Delete(ctx context.Context, paths []string, report ReportFunc) error {
+	paths = longPaths(paths)
+	o := &op{ctx: ctx, report: report}
+	for _, path := range paths {
+		if err := o.scan(path); err != nil {
+			return err
+		}
+	}
+	o.started = time.Now()
+	o.tick(true)
+	for _, path := range paths {
+		if err := o.remove(path); err != nil {
+			return err
+		}
+	}
+	o.tick(true)
+	return nil
+}
+
+// Returns number of files and bytes under paths in TotalFiles and TotalBytes.
+func Count(ctx context.Context, paths []string) (Progress, error) {
+	paths = longPaths(paths)
+	o := &op{ctx: ctx}
+	for _, path := range paths {
+		if err := o.scan(path); err != nil {
+			return o.p, err
+		}
+	}
+	return o.p, nil
+}
+
+func (o *op) remove(path string) error {
+	if err := o.ctx.Err(); err != nil {
+		return err
+	}
+	info, err := os.Lstat(path)
+	if err != nil {
+		return err
+	}
+	if info.IsDir() {
+		entries, err := os.ReadDir(path)
+		if err != nil {
+			return err
+		}
+		for _, e := range entries {
+			if err := o.remove(filepath.Join(path, e.Name())); err != nil {
+				return err
+			}
+		}
+		return os.Remove(path)
+	}
+	if err := os.Remove(path); err != nil {
+		return err
+	}
+	o.p.Files++
+	if info.Mode().IsRegular() {
+		o.p.Bytes += info.Size()
+	}
+	o.tick(false)
+	return nil
+}
+
+func (o *op) copyTree(src, dst string) error {
+	if err := o.ctx.Err(); err != nil {
+		return err
+	}
+	info, err := os.Lstat(src)
+	if err != nil {
+		return err
+	}
+	switch {
+	case info.IsDir():
+		if err := os.Mkdir(dst, info.Mode().Perm()|0o700); err != nil {
+			return err
+		}
+		entries, err := os.ReadDir(src)
+		if err != nil {
+			return err
+		}
+		for _, e := range entries {
+			if err := o.copyTree(filepath.Join(src, e.Name()), filepath.Join(dst, e.Name())); err != nil {
+				return err
+			}
+		}
+		// write permission was kept for copying children
+		return os.Chmod(dst, info.Mode().Perm())
+	case info.Mode()&fs.ModeSymlink != 0:
+		target, err := os.Readlink(src)
+		if err != nil {
+			return err
+		}
+		if err := os.Symlink(target, dst); err != nil {
+			return err
+		}
+	case info.Mode().IsRegular():
+		if err := o.copyFile(src, dst, info.Mode().Perm()); err != nil {
+			return err
+		}
+	default:
+		return &fs.PathError{Op: "copy", Path: src, Err: errors.ErrUnsupported}
+	}
+	o.p.Files++
+	o.tick(false)
+	return nil
+}
+
+func (o *op) copyFile(src, dst string, perm fs.FileMode) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	out, err := os.OpenFile(dst, os.O_WRONLY|os.O_CREATE|os.O_EXCL, perm)
+	if err != nil {
+		return err
+	}
+	buf := make([]byte, chunkSize)
+	for {
+		if err := o.ctx.Err(); err != nil {
+			out.Close()
+			return err
+		}
+		n, err := io.CopyBuffer(out, io.LimitReader(in, chunkSize), buf)
+		o.p.Bytes += n
+		o.tick(false)
+		if err != nil {
+			out.Close()
+			return err
+		}
+		if n < chunkSize {
+			break
+		}
+	}
+	return out.Close()
+}
@@ -0,0 +1,13 @@
+//go:build !windows
+
+package fileop
+
+import (
+	"errors"
+	"syscall"
+)
+
+// Reports whether rename failed, because paths are on different filesystems.
+func crossDevice(err error) bool {
+	return errors.Is(err, syscall.EXDEV)
+}
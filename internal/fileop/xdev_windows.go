@@ -0,0 +1,16 @@
+//go:build windows
+
+package fileop
+
+import (
+	"errors"
+	"syscall"
+)
+
+// ERROR_NOT_SAME_DEVICE, returned by renames between drives.
+const errNotSameDevice = syscall.Errno(17)
+
+// Reports whether rename failed, because paths are on different drives.
+func crossDevice(err error) bool {
+	return errors.Is(err, errNotSameDevice)
+}
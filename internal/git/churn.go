@@ -0,0 +1,57 @@
+package git
+
+import (
+	"bufio"
+	"bytes"
+	"os/exec"
+	"slices"
+	"strings"
+)
+
+type FileChurn struct {
+	Path    string // relative to the inspected directory
+	Commits int
+}
+
+// Counts commits, touching each file under dir since given date (anything `git log --since` accepts).
+// Result is sorted by commit count, most changed first.
+func Churn(dir, since string) ([]FileChurn, error) {
+	cmd := exec.Command("git", "-C", dir, "log", "--relative", "--name-only", "--format=", "--since="+since, "--", ".")
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return nil, &Error{Msg: msg}
+		}
+		return nil, err
+	}
+
+	counts := map[string]int{}
+	sc := bufio.NewScanner(bytes.NewReader(out))
+	for sc.Scan() {
+		if line := strings.TrimSpace(sc.Text()); line != "" {
+			counts[line]++
+		}
+	}
+	files := make([]FileChurn, 0, len(counts))
+	for p, c := range counts {
+		files = append(files, FileChurn{Path: p, Commits: c})
+	}
+	slices.SortFunc(files, func(a, b FileChurn) int {
+		if a.Commits != b.Commits {
+			return b.Commits - a.Commits
+		}
+		return strings.Compare(a.Path, b.Path)
+	})
+	return files, nil
+}
+
+// Error, reported by git itself.
+type Error struct {
+	Msg string
+}
+
+func (e *Error) Error() string {
+	return "git: " + e.Msg
+}
@@ -0,0 +1,23 @@
+package git
+
+import (
+	"bytes"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// Returns diff of the file against HEAD. Empty diff means the file is unchanged or untracked.
+func DiffHead(path string) (string, error) {
+	cmd := exec.Command("git", "-C", filepath.Dir(path), "diff", "--no-color", "--no-ext-diff", "HEAD", "--", filepath.Base(path))
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return "", &Error{Msg: msg}
+		}
+		return "", err
+	}
+	return string(out), nil
+}
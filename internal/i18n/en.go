@@ -0,0 +1,313 @@
+package i18n
+
+var en = Catalog{
+	"ui.too-small":             "too small =(",
+	"ui.binary-content":        "<binary content>",
+	"ui.preview-pending":       "running previewer...",
+	"ui.dir-preview":           "%d dirs, %d files, %s",
+	"ui.dir-empty":             "empty directory",
+	"ui.dir-more":              "... and %d more",
+	"ui.help-hint":             "Press ? to toggle help",
+	"ui.status-items":          "%d files, %d dirs",
+	"ui.status-hidden":         " (%d hidden)",
+	"ui.status-selected":       "%d selected, %s",
+	"ui.status-free":           "%s free",
+	"ui.status-natural":        "natural order",
+	"ui.status-case":           "case-sensitive",
+	"ui.status-read-only":      "read-only",
+	"ui.read-only":             "read-only mode, files can't be changed",
+	"ui.pane-files":            "Files",
+	"ui.pane-preview":          "Preview",
+	"ui.pane-preview-of":       "Preview: %s",
+	"ui.pane-diff-of":          "Diff vs HEAD: %s",
+	"ui.pane-pinned":           "Pinned: %s",
+	"ui.pane-help":             "Help",
+	"ui.pane-churn":            "Hot files: %s",
+	"ui.churn-loading":         "reading git history...",
+	"ui.churn-empty":           "no changes in recent history",
+	"ui.total-size":            "(%s total)",
+	"ui.sizing":                "(computing total...)",
+	"ui.bookmarks":             "Bookmarks",
+	"ui.pane-recent":           "Recent directories",
+	"ui.same-as":               "= same as %s",
+	"ui.job-copy":              "copying %s",
+	"ui.job-move":              "moving %s",
+	"ui.job-delete":            "deleting %s",
+	"ui.job-archive":           "archiving %s",
+	"ui.job-extract":           "extracting %s",
+	"ui.job-progress":          "%d/%d files, %s/%s",
+	"ui.job-cancel":            "%s - cancel",
+	"ui.job-eta":               "%s left",
+	"ui.job-slow":              "transferring %s to %s takes about %s, judging by previous transfers",
+	"ui.job-busy":              "%s is still running",
+	"ui.job-local-only":        "%s works on the local file system only",
+	"ui.group-dirs":            "Directories",
+	"ui.group-code":            "Code",
+	"ui.group-images":          "Images",
+	"ui.group-documents":       "Documents",
+	"ui.group-other":           "Other",
+	"ui.no-bookmarks":          "no bookmarks yet, press m and a letter to add one",
+	"ui.no-artifacts":          "no build artifacts of known project types in %s",
+	"ui.local-only":            "not available in remote trees",
+	"ui.expand-limited":        "expanding stopped after %d directories, expand deeper ones separately",
+	"ui.pane-bulk-rename":      "Bulk rename",
+	"ui.bulk-rename-hint":      "type regexp/replacement, e.g. (.*)\\.jpeg/$1.jpg, or press ctrl+e to edit names in $EDITOR",
+	"ui.filter":                "[filter: %s]",
+	"ui.dirs-only":             "[dirs only]",
+	"ui.files-only":            "[files only]",
+	"ui.pane-grep":             "Search: %s",
+	"ui.pane-shell":            "$ %s",
+	"ui.pane-compare":          "%s %s %s",
+	"ui.files-equal":           "files are equal",
+	"ui.pane-tail":             "Following: %s",
+	"ui.checksums":             "computing checksums...",
+	"ui.pane-messages":         "Messages",
+	"ui.pane-drives":           "Drives",
+	"ui.pane-journal":          "Journal",
+	"ui.no-journal":            "no file operations yet",
+	"ui.pane-registers":        "Registers",
+	"ui.no-registers":          "registers are empty, a-z to yank into one",
+	"ui.undone":                "(undone)",
+	"ui.irreversible":          "(can't undo)",
+	"ui.nothing-to-undo":       "nothing to undo",
+	"ui.nothing-to-redo":       "nothing to redo",
+	"ui.undo-failed":           "can't undo %s: %v",
+	"ui.redo-failed":           "can't redo %s: %v",
+	"ui.changed-since-copy":    "%s was changed since it was copied, it stays",
+	"ui.op-rename":             "rename",
+	"ui.op-move":               "move",
+	"ui.op-copy":               "copy",
+	"ui.op-create":             "create",
+	"ui.op-delete":             "delete",
+	"ui.register-other-fs":     "can't append paths from another file system",
+	"ui.register-paths":        "%d path(s) in register %c",
+	"ui.register-empty":        "register %c is empty",
+	"ui.paste-other-fs":        "can't paste between local and remote trees",
+	"ui.paste-into-self":       "can't paste %s into itself",
+	"ui.copy-into-self":        "can't copy %s into itself",
+	"ui.move-into-self":        "can't move %s into itself",
+	"ui.overwrite-own-content": "can't overwrite %s with its own content",
+	"ui.archive-into-self":     "can't put archive of %s into itself",
+	"ui.select-archive":        "select an archive to extract",
+	"ui.select-checksum":       "select a file to compute checksums",
+	"ui.no-checksums":          "no checksums of the selected file, compute them first",
+	"ui.compare-hint":          "mark a file with 'y' or 'd', then select another one to compare",
+	"ui.no-drives":             "no drives to switch to",
+	"ui.path-exists":           "%s already exists",
+	"ui.not-directory":         "%s is not a directory",
+	"ui.not-regular-file":      "%s is not a regular file",
+	"ui.not-empty-anymore":     "%s is not empty anymore",
+	"ui.unknown-operation":     "unknown operation %s",
+	"ui.tree-exported":         "tree exported to %s",
+	"ui.nothing-staged":        "nothing is staged, select paths with space",
+	"ui.select-tail":           "select a file to follow",
+	"ui.name-mismatch":         "name doesn't match, nothing is done",
+	"ui.no-matches":            "no matches for '%s'",
+	"ui.shell-no-selected":     "nothing is selected for %s",
+	"ui.shell-no-marked":       "nothing is marked for %m",
+	"ui.shell-output-cut":      "\n... output is cut at %d bytes",
+	"ui.no-messages":           "no messages yet",
+	"ui.tail-following":        "following, w or esc - stop",
+	"ui.tail-back":             "%d lines back, J / ctrl+d - forward",
+	"ui.shell-running":         "running...",
+	"ui.send-no-program":       "no program to send paths to",
+	"ui.unfinished-quote":      "unfinished quote in the command",
+	"ui.hook-failed":           "%s hook: %s",
+	"ui.tail-unseekable":       "%s can't be followed on this file system",
+	"ui.clipboard-too-large":   "'%s' is too large to copy",
+	"ui.shell-exit":            "exit status %d",
+	"ui.grep-count":            "%d matches",
+	"ui.grep-searching":        "(searching...)",
+	"ui.pane-snapshots":        "Snapshots",
+	"ui.pane-snapshot":         "Snapshot: %s (%s)",
+	"ui.snapshots-loading":     "looking for snapshots...",
+	"ui.no-snapshots":          "no zfs, btrfs (snapper) or Time Machine snapshots of %s found",
+	"ui.pane-cleanup":          "Cleanup: %s",
+	"ui.pane-basket":           "To be deleted (%d)",
+	"ui.basket":                "[to be deleted: %d]",
+	"ui.basket-hint":           "u - take back, D - delete all, esc - close",
+	"ui.pane-staging":          "Staged (%d)",
+	"ui.staging-hint":          "u - unstage, x - unstage all, enter - reveal, c / m - copy / move all to, D - delete, & / ! - send to program / shell",
+	"ui.pane-delete":           "Delete %d paths",
+	"ui.pane-delete-dir":       "Delete %s/",
+	"ui.delete-total":          "%d files, %s",
+	"ui.delete-counting":       "counting files...",
+	"ui.delete-failed":         "counting failed: %v",
+	"ui.delete-uncounted":      "files aren't counted on this file system",
+	"ui.delete-hint":           "y - delete, n - keep",
+	"ui.delete-empty-dir":      "(empty directory)",
+	"ui.delete-more":           "... and %d more",
+	"ui.cleanup-scanning":      "looking for candidates...",
+	"ui.cleanup-empty":         "nothing to clean up",
+	"ui.cleanup-selected":      "selected: %s (space - toggle, a - toggle group, D - delete, esc - close)",
+	"ui.cleanup-cache":         "Caches and temporary files",
+	"ui.cleanup-duplicate":     "Duplicates",
+	"ui.cleanup-large":         "Large files",
+	"ui.cleanup-old":           "Not modified for a year",
+
+	"op.moving":                  "moving",
+	"op.copying":                 "copying",
+	"op.confirm-delete":          "confirm removing (y/n) of",
+	"op.insert":                  "create new (f)ile/(d)irectory",
+	"op.insert-file":             "enter new file name (nested paths like src/a.go create directories, trailing / - directory):",
+	"op.insert-dir":              "enter new directory name (nested paths allowed):",
+	"op.renaming":                "renaming (alt+a - select all)",
+	"op.bulk-rename":             "bulk rename (regexp/replacement, ctrl+e - $EDITOR):",
+	"op.confirm-bulk-rename":     "confirm renaming (y/n)",
+	"op.filter":                  "filter tree by glob (enter - keep, esc - cancel):",
+	"op.search":                  "find name in the tree (enter - keep for n / N, esc - cancel):",
+	"op.grep":                    "search file contents under current directory (regexp):",
+	"op.grep-results":            "search results (j/k, enter - open, esc - close)",
+	"op.shell":                   "shell command (%s - selected, %m - marked, %d - current dir, ctrl+r - register, leading ! - interactive):",
+	"op.shell-output":            "command output (j/k, g/G, esc - close)",
+	"op.snapshot-pick":           "open snapshot (j/k, enter):",
+	"op.yank":                    "copy to clipboard (p)ath / (r)elative path / (c)ontent / (m)d5 / s(h)a1 / (s)ha256",
+	"op.chmod":                   "change mode (octal or symbolic, e.g. 644, u+x,go-w) of",
+	"op.chown":                   "change owner (user[:group]) of",
+	"op.bookmark-set":            "bookmark current directory as:",
+	"op.bookmark-jump":           "jump to bookmark:",
+	"op.anchor-set":              "anchor preview line of selected file as:",
+	"op.anchor-note":             "enter anchor note (optional):",
+	"op.cleanup":                 "cleanup",
+	"op.confirm-clean-artifacts": "confirm removing (y/n) of build artifacts",
+	"op.confirm-cleanup":         "confirm removing (y/n) of selected candidates",
+	"op.session-restore":         "restore previous session here: expanded directories, selection, filter (y/n)?",
+	"op.paste-conflict":          "paste target exists, (o)verwrite / (s)kip / (r)ename, O / S / R - same for all following:",
+	"op.guard-confirm":           "protected path, type its name to confirm:",
+	"op.recent":                  "jump to recent directory (j/k, enter):",
+	"op.basket":                  "review files to be deleted",
+	"op.confirm-basket":          "confirm removing (y/n) of everything in the list",
+	"op.confirm-delete-listed":   "confirm removing (y/n) of everything listed",
+	"op.messages":                "messages (j / k to scroll)",
+	"op.copy-to":                 "copy to (tab completes directories)",
+	"op.move-to":                 "move to (tab completes directories)",
+	"op.archive":                 "archive as (.zip, .tar.gz or .tgz)",
+	"op.extract":                 "extract to directory (tab completes)",
+	"op.drives":                  "switch to drive (j/k, enter):",
+	"op.journal":                 "journal (j / k to scroll, u - undo, r - redo)",
+	"op.register-pick":           "register (a-z, A-Z - append):",
+	"op.register-use":            "(y)ank into register / (p)aste copies here / (m)ove here / (c)lear",
+	"op.open-with":               "open with command ({} - file, appended otherwise; leading ! - in the terminal):",
+	"op.send":                    "send selected paths to program as arguments ({} - where, tab - configured ones, leading ! - in the terminal):",
+	"op.export":                  "export visible tree to (.md - Markdown, text otherwise; tab completes directories)",
+	"op.staging":                 "staging area (j/k)",
+
+	"action.select-next":       "Select next child",
+	"action.select-prev":       "Select previous child",
+	"action.parent-dir":        "Move up a dir",
+	"action.enter-dir":         "Enter selected directory",
+	"action.insert":            "Create file (f) / directory (d) in current directory",
+	"action.move":              "Move selected child (then 'p' to paste)",
+	"action.copy":              "Copy selected child (then 'p' to paste)",
+	"action.copy-to":           "Copy selected paths to a typed destination",
+	"action.move-to":           "Move selected paths to a typed destination",
+	"action.archive":           "Pack selected paths into a .zip or .tar.gz archive",
+	"action.extract":           "Extract selected archive into a directory",
+	"action.checksums":         "Compute MD5, SHA1 and SHA256 of selected file",
+	"action.delete":            "Delete selected child (or all selected), asks y/n",
+	"action.rename":            "Rename selected child",
+	"action.bulk-rename":       "Bulk rename entries of current directory (regexp or $EDITOR)",
+	"action.yank":              "Copy absolute path (p), relative path (r), content (c) or checksum (m / h / s) of selected child to clipboard",
+	"action.chmod":             "Change permissions of selected child (octal or symbolic)",
+	"action.chown":             "Change owner / group of selected child (user:group)",
+	"action.edit":              "Edit selected file in $EDITOR",
+	"action.open":              "Open selected file with its opener from config or system default application",
+	"action.open-with":         "Open selected file with a typed command once",
+	"action.shell":             "Run shell command on selected paths (%s), output shown in a pane",
+	"action.send":              "Send selected paths to a program as arguments, its output is shown like shell output",
+	"action.export":            "Export the visible tree with sizes to a text or Markdown file",
+	"action.staging":           "Review selected paths from all directories and act on all of them",
+	"action.subshell":          "Open $SHELL in current directory, bt comes back, when it exits",
+	"action.compare":           "Compare marked file with selected one: unified diff in a pane (= again to close)",
+	"action.tail":              "Follow selected file like tail -f in a pane (w again to stop)",
+	"action.select-first":      "Go to top most child in current directory",
+	"action.select-last":       "Go to last child in current directory",
+	"action.toggle-expand":     "Open selected node: expand directory or preview file (configurable)",
+	"action.expand-all":        "Expand selected directory recursively (with a count - that many levels, 3E)",
+	"action.collapse-under":    "Collapse everything under selected directory",
+	"action.collapse-others":   "Collapse directories next to selected entry",
+	"action.back":              "Go back to previously visited directory",
+	"action.forward":           "Go forward in visited directories",
+	"action.recent":            "Pick one of recently visited directories",
+	"action.messages":          "Show recent errors and notices",
+	"action.drives":            "Switch to another drive (Windows)",
+	"action.undo":              "Undo the last file operation: rename, move, copy or creation",
+	"action.redo":              "Redo the last undone file operation",
+	"action.journal":           "Show the journal of file operations",
+	"action.register":          "Use a named register of paths: yank, paste, move",
+	"action.bookmark-set":      "Bookmark current directory (then a letter)",
+	"action.bookmark-jump":     "Jump to bookmarked directory or anchor (then a letter)",
+	"action.anchor-set":        "Anchor selected file at top preview line (then a letter and a note)",
+	"action.cancel":            "Clear error message / stop current operation",
+	"action.toggle-help":       "Toggle help with current key bindings (j / k to scroll)",
+	"action.toggle-preview":    "Show / hide preview pane (hidden preview reads no files)",
+	"action.toggle-details":    "Toggle detail columns (permissions, owner, size, modification time)",
+	"action.toggle-diff":       "Toggle diff against git HEAD in preview for modified files",
+	"action.toggle-markdown":   "Toggle rendered / raw markdown preview",
+	"action.preview-down":      "Scroll preview down",
+	"action.preview-up":        "Scroll preview up",
+	"action.preview-page-down": "Scroll preview half a page down",
+	"action.preview-page-up":   "Scroll preview half a page up",
+	"action.toggle-dual-pane":  "Toggle dual pane mode",
+	"action.cycle-focus":       "Cycle focus between panes (focused preview scrolls with j / k)",
+	"action.dir-size":          "Compute total size of selected directory",
+	"action.clean-artifacts":   "Remove build artifacts (node_modules, target, .venv, ...) of project in current directory",
+	"action.filter":            "Filter tree by glob, e.g. *.go",
+	"action.clear-filter":      "Clear tree filter and entry type",
+	"action.dirs-only":         "Show only directories, press again to show all",
+	"action.files-only":        "Show only files and directories leading to them, press again to show all",
+	"action.search":            "Find name in the shown tree, jumping to matches as you type",
+	"action.search-next":       "Go to next name match",
+	"action.search-prev":       "Go to previous name match",
+	"action.pin":               "Pin preview to selected file / unpin",
+	"action.pin-transient":     "Toggle preview of selected file below the pinned one",
+	"action.toss":              "Toss selected child (or all selected) to the list of files to be deleted",
+	"action.basket":            "Review and delete files from the to be deleted list",
+	"action.grow-tree":         "Make tree pane wider",
+	"action.shrink-tree":       "Make tree pane narrower",
+	"action.toggle-groups":     "Toggle grouping of entries by kind: directories, code, images, documents",
+	"action.natural-sort":      "Toggle natural order of names: file2 before file10",
+	"action.toggle-numbers":    "Toggle line numbers in preview",
+	"action.toggle-wrap":       "Toggle wrapping of long lines in preview (cut lines end with …)",
+	"action.columns":           "List the current directory in columns, when preview is hidden and the terminal is wide",
+	"action.case-sort":         "Toggle case-sensitive order of names: B before a",
+	"action.fold-group":        "Fold group of the selected child (grouped view)",
+	"action.unfold-groups":     "Unfold all groups in the current directory",
+	"action.cancel-job":        "Cancel running copy / move / delete",
+	"action.scroll-left":       "Scroll tree left",
+	"action.scroll-right":      "Scroll tree right, to see long names",
+	"action.toggle-select":     "Select / unselect selected child (written to -pipe-fd, if set)",
+	"action.grep":              "Search file contents under current directory (regexp, smart case)",
+	"action.cleanup":           "Suggest cleanup candidates in selected directory",
+	"action.time-travel":       "Browse snapshots of current directory in the second pane",
+	"action.restore":           "Restore selected entry from the snapshot pane into the live directory",
+	"action.toggle-churn":      "Toggle hot files view (git commit counts) for selected directory",
+	"action.new-tab":           "Open new tab in current directory",
+	"action.close-tab":         "Close current tab",
+	"action.next-tab":          "Switch to next tab",
+	"action.prev-tab":          "Switch to previous tab",
+	"action.quit":              "Exit",
+	"action.quit-cd":           "Exit and cd shell into current directory (see shell-init)",
+
+	"preview.format":          "Format",
+	"preview.size":            "Size",
+	"preview.dimensions":      "Dimensions",
+	"preview.channels":        "Channels",
+	"preview.sample-rate":     "Sample rate",
+	"preview.bits":            "Bits per sample",
+	"preview.brand":           "Brand",
+	"preview.version":         "Version",
+	"preview.first-entry":     "First entry",
+	"preview.modified":        "Modified",
+	"preview.original-name":   "Original name",
+	"preview.type":            "Type",
+	"preview.architecture":    "Architecture",
+	"preview.page-size":       "Page size",
+	"preview.kind-image":      "image",
+	"preview.kind-audio":      "audio",
+	"preview.kind-video":      "video",
+	"preview.kind-document":   "document",
+	"preview.kind-archive":    "archive",
+	"preview.kind-executable": "executable",
+	"preview.kind-database":   "database",
+}
@@ -0,0 +1,58 @@
+package i18n
+
+import (
+	"os"
+	"strings"
+)
+
+const DefaultLocale = "en"
+
+type Catalog map[string]string
+
+var catalogs = map[string]Catalog{
+	"en": en,
+	"ru": ru,
+}
+
+var current = catalogs[DefaultLocale]
+
+// Picks locale from configured value or from environment (LC_ALL, LC_MESSAGES, LANG).
+// Returns DefaultLocale if nothing matches a known catalog.
+func Detect(configured string) string {
+	candidates := []string{configured, os.Getenv("LC_ALL"), os.Getenv("LC_MESSAGES"), os.Getenv("LANG")}
+	for _, c := range candidates {
+		if l := normalize(c); l != "" {
+			if _, ok := catalogs[l]; ok {
+				return l
+			}
+		}
+	}
+	return DefaultLocale
+}
+
+func SetLocale(locale string) {
+	if c, ok := catalogs[normalize(locale)]; ok {
+		current = c
+	}
+}
+
+// Returns message for key in current locale,
+// falling back to default locale and then to the key itself.
+func T(key string) string {
+	if msg, ok := current[key]; ok {
+		return msg
+	}
+	if msg, ok := catalogs[DefaultLocale][key]; ok {
+		return msg
+	}
+	return key
+}
+
+// "ru_RU.UTF-8" -> "ru"
+func normalize(locale string) string {
+	locale = strings.ToLower(locale)
+	if i := strings.IndexAny(locale, "_.@-"); i >= 0 {
+		locale = locale[:i]
+	}
+	return locale
+}
@@ -0,0 +1,313 @@
+package i18n
+
+var ru = Catalog{
+	"ui.too-small":             "слишком мало места =(",
+	"ui.binary-content":        "<двоичные данные>",
+	"ui.preview-pending":       "запуск просмотрщика...",
+	"ui.dir-preview":           "директорий: %d, файлов: %d, %s",
+	"ui.dir-empty":             "пустая директория",
+	"ui.dir-more":              "... и ещё %d",
+	"ui.help-hint":             "Нажмите ? для справки",
+	"ui.status-items":          "файлов: %d, директорий: %d",
+	"ui.status-hidden":         " (скрыто: %d)",
+	"ui.status-selected":       "выбрано: %d, %s",
+	"ui.status-free":           "свободно: %s",
+	"ui.status-natural":        "естественный порядок",
+	"ui.status-case":           "с учётом регистра",
+	"ui.status-read-only":      "только чтение",
+	"ui.read-only":             "режим только для чтения, файлы не изменить",
+	"ui.pane-files":            "Файлы",
+	"ui.pane-preview":          "Просмотр",
+	"ui.pane-preview-of":       "Просмотр: %s",
+	"ui.pane-diff-of":          "Отличия от HEAD: %s",
+	"ui.pane-pinned":           "Закреплён: %s",
+	"ui.pane-help":             "Справка",
+	"ui.pane-churn":            "Часто изменяемые: %s",
+	"ui.churn-loading":         "чтение истории git...",
+	"ui.churn-empty":           "нет изменений за последнее время",
+	"ui.total-size":            "(всего %s)",
+	"ui.sizing":                "(подсчет размера...)",
+	"ui.pane-recent":           "Недавние директории",
+	"ui.bookmarks":             "Закладки",
+	"ui.same-as":               "= то же, что %s",
+	"ui.job-copy":              "копирование %s",
+	"ui.job-move":              "перемещение %s",
+	"ui.job-delete":            "удаление %s",
+	"ui.job-archive":           "архивация %s",
+	"ui.job-extract":           "распаковка %s",
+	"ui.job-progress":          "%d/%d файлов, %s/%s",
+	"ui.job-cancel":            "%s - отмена",
+	"ui.job-eta":               "осталось %s",
+	"ui.job-slow":              "перенос %s на %s займёт около %s, судя по прошлым операциям",
+	"ui.job-busy":              "%s ещё не завершено",
+	"ui.job-local-only":        "%s возможно только в локальной файловой системе",
+	"ui.group-dirs":            "Каталоги",
+	"ui.group-code":            "Код",
+	"ui.group-images":          "Изображения",
+	"ui.group-documents":       "Документы",
+	"ui.group-other":           "Прочее",
+	"ui.no-bookmarks":          "закладок пока нет, нажмите m и букву, чтобы добавить",
+	"ui.no-artifacts":          "в %s нет артефактов сборки известных типов проектов",
+	"ui.local-only":            "недоступно в удалённых деревьях",
+	"ui.expand-limited":        "раскрытие остановлено после %d каталогов, раскройте более глубокие отдельно",
+	"ui.pane-bulk-rename":      "Массовое переименование",
+	"ui.bulk-rename-hint":      "введите регулярку/замену, напр. (.*)\\.jpeg/$1.jpg, или нажмите ctrl+e, чтобы править имена в $EDITOR",
+	"ui.filter":                "[фильтр: %s]",
+	"ui.dirs-only":             "[только директории]",
+	"ui.files-only":            "[только файлы]",
+	"ui.pane-grep":             "Поиск: %s",
+	"ui.pane-shell":            "$ %s",
+	"ui.pane-compare":          "%s %s %s",
+	"ui.files-equal":           "файлы совпадают",
+	"ui.pane-tail":             "Слежение: %s",
+	"ui.checksums":             "контрольные суммы считаются...",
+	"ui.pane-messages":         "Сообщения",
+	"ui.pane-drives":           "Диски",
+	"ui.pane-journal":          "Журнал",
+	"ui.no-journal":            "операций с файлами пока не было",
+	"ui.pane-registers":        "Регистры",
+	"ui.no-registers":          "регистры пусты, a-z - скопировать пути в регистр",
+	"ui.undone":                "(отменено)",
+	"ui.irreversible":          "(не отменить)",
+	"ui.nothing-to-undo":       "нечего отменять",
+	"ui.nothing-to-redo":       "нечего повторять",
+	"ui.undo-failed":           "не удалось отменить %s: %v",
+	"ui.redo-failed":           "не удалось повторить %s: %v",
+	"ui.changed-since-copy":    "%s изменился после копирования и остаётся на месте",
+	"ui.op-rename":             "переименование",
+	"ui.op-move":               "перемещение",
+	"ui.op-copy":               "копирование",
+	"ui.op-create":             "создание",
+	"ui.op-delete":             "удаление",
+	"ui.register-other-fs":     "нельзя добавить пути из другой файловой системы",
+	"ui.register-paths":        "путей в регистре %[2]c: %[1]d",
+	"ui.register-empty":        "регистр %c пуст",
+	"ui.paste-other-fs":        "нельзя вставлять между локальным и удалённым деревом",
+	"ui.paste-into-self":       "нельзя вставить %s внутрь него самого",
+	"ui.copy-into-self":        "нельзя скопировать %s внутрь него самого",
+	"ui.move-into-self":        "нельзя переместить %s внутрь него самого",
+	"ui.overwrite-own-content": "нельзя заменить %s его собственным содержимым",
+	"ui.archive-into-self":     "нельзя поместить архив %s внутрь него самого",
+	"ui.select-archive":        "выберите архив для распаковки",
+	"ui.select-checksum":       "выберите файл для подсчёта контрольных сумм",
+	"ui.no-checksums":          "для выбранного файла нет контрольных сумм, сначала посчитайте их",
+	"ui.compare-hint":          "отметьте файл через 'y' или 'd', затем выберите другой для сравнения",
+	"ui.no-drives":             "нет дисков для переключения",
+	"ui.path-exists":           "%s уже существует",
+	"ui.not-directory":         "%s не является каталогом",
+	"ui.not-regular-file":      "%s не является обычным файлом",
+	"ui.not-empty-anymore":     "%s уже не пуст",
+	"ui.unknown-operation":     "неизвестная операция %s",
+	"ui.tree-exported":         "дерево выгружено в %s",
+	"ui.nothing-staged":        "ничего не подготовлено, выберите пути пробелом",
+	"ui.select-tail":           "выберите файл для слежения",
+	"ui.name-mismatch":         "имя не совпадает, ничего не сделано",
+	"ui.no-matches":            "нет совпадений для '%s'",
+	"ui.shell-no-selected":     "для %s ничего не выбрано",
+	"ui.shell-no-marked":       "для %m ничего не отмечено",
+	"ui.shell-output-cut":      "\n... вывод обрезан на %d байтах",
+	"ui.no-messages":           "сообщений пока нет",
+	"ui.tail-following":        "слежение, w или esc - остановить",
+	"ui.tail-back":             "%d строк назад, J / ctrl+d - вперёд",
+	"ui.shell-running":         "выполняется...",
+	"ui.send-no-program":       "нет программы, которой отправить пути",
+	"ui.unfinished-quote":      "незакрытая кавычка в команде",
+	"ui.hook-failed":           "хук %s: %s",
+	"ui.tail-unseekable":       "%s нельзя отслеживать в этой файловой системе",
+	"ui.clipboard-too-large":   "'%s' слишком велик для копирования",
+	"ui.shell-exit":            "код завершения %d",
+	"ui.grep-count":            "совпадений: %d",
+	"ui.grep-searching":        "(идёт поиск...)",
+	"ui.pane-snapshots":        "Снимки",
+	"ui.pane-snapshot":         "Снимок: %s (%s)",
+	"ui.snapshots-loading":     "ищем снимки...",
+	"ui.no-snapshots":          "снимков zfs, btrfs (snapper) или Time Machine для %s не найдено",
+	"ui.pane-cleanup":          "Очистка: %s",
+	"ui.pane-basket":           "К удалению (%d)",
+	"ui.basket":                "[к удалению: %d]",
+	"ui.basket-hint":           "u - вернуть, D - удалить всё, esc - закрыть",
+	"ui.pane-staging":          "Отобранные (%d)",
+	"ui.staging-hint":          "u - убрать, x - убрать все, enter - показать, c / m - копировать / переместить все в, D - удалить, & / ! - программе / команде",
+	"ui.pane-delete":           "Удаление путей: %d",
+	"ui.pane-delete-dir":       "Удаление %s/",
+	"ui.delete-total":          "файлов: %d, %s",
+	"ui.delete-counting":       "подсчёт файлов...",
+	"ui.delete-failed":         "ошибка подсчёта: %v",
+	"ui.delete-uncounted":      "в этой файловой системе файлы не подсчитываются",
+	"ui.delete-hint":           "y - удалить, n - оставить",
+	"ui.delete-empty-dir":      "(пустой каталог)",
+	"ui.delete-more":           "... и ещё %d",
+	"ui.cleanup-scanning":      "ищем кандидатов...",
+	"ui.cleanup-empty":         "удалять нечего",
+	"ui.cleanup-selected":      "выбрано: %s (space - выбрать, a - выбрать группу, D - удалить, esc - закрыть)",
+	"ui.cleanup-cache":         "Кэши и временные файлы",
+	"ui.cleanup-duplicate":     "Дубликаты",
+	"ui.cleanup-large":         "Большие файлы",
+	"ui.cleanup-old":           "Не изменялись больше года",
+
+	"op.moving":                  "перемещение",
+	"op.copying":                 "копирование",
+	"op.confirm-delete":          "подтвердите удаление (y/n)",
+	"op.insert":                  "создать (f)айл/(d)иректорию",
+	"op.insert-file":             "имя нового файла (вложенные пути вроде src/a.go создают директории, / в конце - директория):",
+	"op.insert-dir":              "имя новой директории (можно вложенный путь):",
+	"op.renaming":                "переименование (alt+a - выделить всё)",
+	"op.bulk-rename":             "массовое переименование (регулярка/замена, ctrl+e - $EDITOR):",
+	"op.confirm-bulk-rename":     "подтвердите переименование (y/n)",
+	"op.filter":                  "фильтр дерева по glob (enter - оставить, esc - отменить):",
+	"op.search":                  "поиск имени в дереве (enter - оставить для n / N, esc - отменить):",
+	"op.grep":                    "поиск по содержимому файлов в текущей директории (регулярка):",
+	"op.grep-results":            "результаты поиска (j/k, enter - открыть, esc - закрыть)",
+	"op.shell":                   "команда (%s - выбранные, %m - отмеченный, %d - текущая директория, ctrl+r - регистр, ! в начале - интерактивно):",
+	"op.shell-output":            "вывод команды (j/k, g/G, esc - закрыть)",
+	"op.snapshot-pick":           "открыть снимок (j/k, enter):",
+	"op.yank":                    "скопировать в буфер обмена (p)уть / (r) относительный путь / (c) содержимое / (m)d5 / s(h)a1 / (s)ha256",
+	"op.chmod":                   "изменение прав (восьмерично или символьно, напр. 644, u+x,go-w) для",
+	"op.chown":                   "изменение владельца (user[:group]) для",
+	"op.bookmark-set":            "добавить закладку на текущую директорию:",
+	"op.bookmark-jump":           "перейти к закладке:",
+	"op.anchor-set":              "добавить якорь на строку превью выбранного файла:",
+	"op.anchor-note":             "введите заметку к якорю (необязательно):",
+	"op.cleanup":                 "очистка",
+	"op.confirm-clean-artifacts": "подтвердите удаление (y/n) артефактов сборки",
+	"op.confirm-cleanup":         "подтвердите удаление (y/n) выбранных кандидатов",
+	"op.session-restore":         "восстановить прошлую сессию: раскрытые директории, выбор, фильтр (y/n)?",
+	"op.paste-conflict":          "цель вставки существует, (o) заменить / (s) пропустить / (r) переименовать, O / S / R - так же для всех следующих:",
+	"op.guard-confirm":           "защищённый путь, введите его имя для подтверждения:",
+	"op.recent":                  "перейти в недавнюю директорию (j/k, enter):",
+	"op.basket":                  "просмотр файлов к удалению",
+	"op.confirm-basket":          "подтвердите удаление (y/n) всего списка",
+	"op.confirm-delete-listed":   "подтвердите удаление (y/n) всего перечисленного",
+	"op.messages":                "сообщения (j / k для прокрутки)",
+	"op.copy-to":                 "копировать в (tab дополняет директории)",
+	"op.move-to":                 "переместить в (tab дополняет директории)",
+	"op.archive":                 "архивировать в (.zip, .tar.gz или .tgz)",
+	"op.extract":                 "распаковать в директорию (tab дополняет)",
+	"op.drives":                  "перейти на диск (j/k, enter):",
+	"op.journal":                 "журнал (j / k для прокрутки, u - отменить, r - повторить)",
+	"op.register-pick":           "регистр (a-z, A-Z - дописать):",
+	"op.register-use":            "(y) - запомнить в регистре / (p) - вставить копии сюда / (m) - переместить сюда / (c) - очистить",
+	"op.open-with":               "открыть командой ({} - файл, иначе он добавляется в конец; ! в начале - в терминале):",
+	"op.send":                    "передать выбранные пути программе аргументами ({} - куда, tab - из конфига, ! в начале - в терминале):",
+	"op.export":                  "экспортировать видимое дерево в (.md - Markdown, иначе текст; tab дополняет директории)",
+	"op.staging":                 "отобранные пути (j/k)",
+
+	"action.select-next":       "Выбрать следующий элемент",
+	"action.select-prev":       "Выбрать предыдущий элемент",
+	"action.parent-dir":        "Перейти на уровень выше",
+	"action.enter-dir":         "Войти в выбранную директорию",
+	"action.insert":            "Создать файл (f) / директорию (d) в текущей директории",
+	"action.move":              "Переместить выбранный элемент (затем 'p' для вставки)",
+	"action.copy":              "Скопировать выбранный элемент (затем 'p' для вставки)",
+	"action.copy-to":           "Скопировать выбранное по введённому пути",
+	"action.move-to":           "Переместить выбранное по введённому пути",
+	"action.archive":           "Упаковать выбранное в архив .zip или .tar.gz",
+	"action.extract":           "Распаковать выбранный архив в директорию",
+	"action.checksums":         "Посчитать MD5, SHA1 и SHA256 выбранного файла",
+	"action.delete":            "Удалить выбранный элемент (или все отмеченные), спрашивает y/n",
+	"action.rename":            "Переименовать выбранный элемент",
+	"action.bulk-rename":       "Массово переименовать элементы текущей директории (регулярка или $EDITOR)",
+	"action.yank":              "Скопировать в буфер обмена абсолютный путь (p), относительный путь (r), содержимое (c) или контрольную сумму (m / h / s) выбранного элемента",
+	"action.chmod":             "Изменить права выбранного элемента (восьмерично или символьно)",
+	"action.chown":             "Изменить владельца / группу выбранного элемента (user:group)",
+	"action.edit":              "Редактировать выбранный файл в $EDITOR",
+	"action.open":              "Открыть выбранный файл командой из конфига или приложением по умолчанию",
+	"action.open-with":         "Открыть выбранный файл введённой командой",
+	"action.shell":             "Выполнить команду оболочки над выбранными путями (%s), вывод - в панели",
+	"action.send":              "Передать выбранные пути программе аргументами, её вывод показывается как вывод команды",
+	"action.export":            "Экспортировать видимое дерево с размерами в текстовый или Markdown-файл",
+	"action.staging":           "Просмотреть выбранные во всех директориях пути и выполнить действие над всеми",
+	"action.subshell":          "Открыть $SHELL в текущей директории, bt вернётся после выхода из неё",
+	"action.compare":           "Сравнить отмеченный файл с выбранным: diff в панели (= ещё раз - закрыть)",
+	"action.tail":              "Следить за выбранным файлом как tail -f в панели (w ещё раз - остановить)",
+	"action.select-first":      "Перейти к первому элементу директории",
+	"action.select-last":       "Перейти к последнему элементу директории",
+	"action.toggle-expand":     "Открыть выбранный узел: развернуть директорию или показать файл (настраивается)",
+	"action.expand-all":        "Раскрыть выбранную директорию рекурсивно (со счётчиком - на столько уровней, 3E)",
+	"action.collapse-under":    "Свернуть всё внутри выбранной директории",
+	"action.collapse-others":   "Свернуть директории рядом с выбранным элементом",
+	"action.back":              "Вернуться в предыдущую посещённую директорию",
+	"action.forward":           "Перейти вперёд по посещённым директориям",
+	"action.recent":            "Выбрать одну из недавних директорий",
+	"action.messages":          "Показать последние ошибки и уведомления",
+	"action.drives":            "Перейти на другой диск (Windows)",
+	"action.undo":              "Отменить последнюю операцию: переименование, перемещение, копирование или создание",
+	"action.redo":              "Повторить последнюю отменённую операцию",
+	"action.journal":           "Показать журнал операций с файлами",
+	"action.register":          "Именованный регистр путей: запомнить, вставить, переместить",
+	"action.bookmark-set":      "Добавить закладку на текущую директорию (затем буква)",
+	"action.bookmark-jump":     "Перейти к закладке или якорю (затем буква)",
+	"action.anchor-set":        "Добавить якорь на верхнюю строку превью выбранного файла (затем буква и заметка)",
+	"action.cancel":            "Сбросить ошибку / прервать текущую операцию",
+	"action.toggle-help":       "Показать / скрыть справку по текущим клавишам (j / k для прокрутки)",
+	"action.toggle-preview":    "Показать / скрыть панель превью (скрытая панель не читает файлы)",
+	"action.toggle-details":    "Показать / скрыть колонки с деталями (права, владелец, размер, время изменения)",
+	"action.toggle-diff":       "Показывать в превью изменённых файлов diff относительно git HEAD",
+	"action.toggle-markdown":   "Переключить отрисовку markdown / исходный текст",
+	"action.preview-down":      "Прокрутить просмотр вниз",
+	"action.preview-up":        "Прокрутить просмотр вверх",
+	"action.preview-page-down": "Прокрутить просмотр на полстраницы вниз",
+	"action.preview-page-up":   "Прокрутить просмотр на полстраницы вверх",
+	"action.toggle-dual-pane":  "Включить / выключить режим двух панелей",
+	"action.cycle-focus":       "Переключить фокус между панелями (просмотр прокручивается j / k)",
+	"action.dir-size":          "Посчитать полный размер выбранной директории",
+	"action.clean-artifacts":   "Удалить артефакты сборки (node_modules, target, .venv, ...) проекта в текущей директории",
+	"action.filter":            "Отфильтровать дерево по glob, напр. *.go",
+	"action.clear-filter":      "Сбросить фильтр и тип записей дерева",
+	"action.dirs-only":         "Показать только директории, повторно - показать все",
+	"action.files-only":        "Показать только файлы и ведущие к ним директории, повторно - показать все",
+	"action.search":            "Найти имя в показанном дереве, переходя к совпадениям по мере ввода",
+	"action.search-next":       "Перейти к следующему совпадению имени",
+	"action.search-prev":       "Перейти к предыдущему совпадению имени",
+	"action.pin":               "Закрепить превью на выбранном файле / открепить",
+	"action.pin-transient":     "Показывать выбранный файл под закреплённым",
+	"action.toss":              "Отложить выбранный элемент (или всё выделенное) в список к удалению",
+	"action.basket":            "Просмотреть и удалить файлы из списка к удалению",
+	"action.grow-tree":         "Расширить панель дерева",
+	"action.shrink-tree":       "Сузить панель дерева",
+	"action.toggle-groups":     "Группировать по типу: каталоги, код, изображения, документы",
+	"action.natural-sort":      "Естественный порядок имён: file2 перед file10",
+	"action.toggle-numbers":    "Номера строк в превью",
+	"action.toggle-wrap":       "Перенос длинных строк в превью (обрезанные заканчиваются …)",
+	"action.columns":           "Список текущей директории в колонках, когда превью скрыто и терминал широкий",
+	"action.case-sort":         "Порядок имён с учётом регистра: B перед a",
+	"action.fold-group":        "Свернуть группу выбранного элемента (при группировке)",
+	"action.unfold-groups":     "Развернуть все группы в текущем каталоге",
+	"action.cancel-job":        "Отменить копирование / перемещение / удаление",
+	"action.scroll-left":       "Прокрутить дерево влево",
+	"action.scroll-right":      "Прокрутить дерево вправо, чтобы увидеть длинные имена",
+	"action.toggle-select":     "Выделить / снять выделение с выбранного элемента (пишется в -pipe-fd, если задан)",
+	"action.grep":              "Искать по содержимому файлов в текущей директории (регулярка, умный регистр)",
+	"action.cleanup":           "Предложить кандидатов на удаление в выбранной директории",
+	"action.time-travel":       "Просмотреть снимки текущей директории во второй панели",
+	"action.restore":           "Восстановить выбранный элемент из панели снимка в живую директорию",
+	"action.toggle-churn":      "Показать / скрыть часто изменяемые файлы (коммиты git) в выбранной директории",
+	"action.new-tab":           "Открыть новую вкладку в текущей директории",
+	"action.close-tab":         "Закрыть текущую вкладку",
+	"action.next-tab":          "Перейти на следующую вкладку",
+	"action.prev-tab":          "Перейти на предыдущую вкладку",
+	"action.quit":              "Выход",
+	"action.quit-cd":           "Выйти и перейти в текущую директорию в shell (см. shell-init)",
+
+	"preview.format":          "Формат",
+	"preview.size":            "Размер",
+	"preview.dimensions":      "Размеры",
+	"preview.channels":        "Каналы",
+	"preview.sample-rate":     "Частота",
+	"preview.bits":            "Бит на отсчёт",
+	"preview.brand":           "Бренд",
+	"preview.version":         "Версия",
+	"preview.first-entry":     "Первый элемент",
+	"preview.modified":        "Изменён",
+	"preview.original-name":   "Исходное имя",
+	"preview.type":            "Тип",
+	"preview.architecture":    "Архитектура",
+	"preview.page-size":       "Размер страницы",
+	"preview.kind-image":      "изображение",
+	"preview.kind-audio":      "аудио",
+	"preview.kind-video":      "видео",
+	"preview.kind-document":   "документ",
+	"preview.kind-archive":    "архив",
+	"preview.kind-executable": "исполняемый файл",
+	"preview.kind-database":   "база данных",
+}
@@ -0,0 +1,191 @@
+// Package persist stores bt state files with schema version and checksum,
+// so that format changes are migrated and damaged files are never silently lost.
+package persist
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// Migration upgrades data by one schema version.
+type Migration func(data json.RawMessage) (json.RawMessage, error)
+
+// File is a persisted JSON document.
+type File struct {
+	Path   string
+	Schema int // current schema version, starting from 1
+	// Migrations by the version they upgrade from.
+	// Version 0 is a legacy file, written without envelope.
+	Migrations map[int]Migration
+}
+
+type envelope struct {
+	Schema   int             `json:"schema"`
+	Checksum string          `json:"checksum"` // sha256 of compact data
+	Data     json.RawMessage `json:"data"`
+}
+
+// Returned, when file can't be trusted. It is moved aside to Quarantine.
+type CorruptError struct {
+	Path       string
+	Quarantine string
+	Reason     string
+}
+
+func (e *CorruptError) Error() string {
+	return fmt.Sprintf("%s is damaged (%s), moved to %s", e.Path, e.Reason, e.Quarantine)
+}
+
+// Returned, when file was written by a newer bt. The file must not be overwritten.
+type NewerSchemaError struct {
+	Path   string
+	Schema int
+}
+
+func (e *NewerSchemaError) Error() string {
+	return fmt.Sprintf("%s has schema %d, that is newer than supported, not saving changes", e.Path, e.Schema)
+}
+
+// Loads file into v. Returns false, if file doesn't exist.
+// Older schemas are migrated and saved back, keeping a backup of the original.
+func (f File) Load(v any) (bool, error) {
+	raw, err := os.ReadFile(f.Path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	env, err := decode(raw)
+	if err != nil {
+		return false, f.quarantine(err.Error())
+	}
+	if env.Schema > f.Schema {
+		return false, &NewerSchemaError{Path: f.Path, Schema: env.Schema}
+	}
+	if err := verify(env); err != nil {
+		return false, f.quarantine(err.Error())
+	}
+	data := env.Data
+	for version := env.Schema; version < f.Schema; version++ {
+		migrate, ok := f.Migrations[version]
+		if !ok {
+			return false, fmt.Errorf("%s: no migration from schema %d", f.Path, version)
+		}
+		if data, err = migrate(data); err != nil {
+			return false, f.quarantine(fmt.Sprintf("migration from schema %d: %v", version, err))
+		}
+	}
+	if err := json.Unmarshal(data, v); err != nil {
+		return false, f.quarantine(err.Error())
+	}
+	if env.Schema < f.Schema {
+		backup := fmt.Sprintf("%s.v%d.bak", f.Path, env.Schema)
+		if err := os.WriteFile(backup, raw, 0o644); err != nil {
+			return true, err
+		}
+		return true, f.Save(v)
+	}
+	return true, nil
+}
+
+// Decodes envelope. Files without envelope are schema 0.
+func decode(raw []byte) (envelope, error) {
+	var probe map[string]json.RawMessage
+	if err := json.Unmarshal(raw, &probe); err != nil {
+		return envelope{}, err
+	}
+	if _, ok := probe["schema"]; !ok {
+		return envelope{Schema: 0, Data: raw}, nil
+	}
+	var env envelope
+	err := json.Unmarshal(raw, &env)
+	return env, err
+}
+
+func verify(env envelope) error {
+	if env.Schema == 0 {
+		return nil // legacy files have no checksum
+	}
+	sum, err := checksum(env.Data)
+	if err != nil {
+		return err
+	}
+	if sum != env.Checksum {
+		return errors.New("checksum mismatch")
+	}
+	return nil
+}
+
+func checksum(data json.RawMessage) (string, error) {
+	var buf bytes.Buffer
+	if err := json.Compact(&buf, data); err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(buf.Bytes())
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// Saves v atomically: readers see either old or new file, never a partial one.
+func (f File) Save(v any) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	sum, err := checksum(data)
+	if err != nil {
+		return err
+	}
+	out, err := json.MarshalIndent(envelope{Schema: f.Schema, Checksum: sum, Data: data}, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(f.Path), 0o755); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(f.Path), filepath.Base(f.Path)+".tmp*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(out); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(tmp.Name(), 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), f.Path)
+}
+
+// Moves damaged file aside, so it can be inspected or restored by hand.
+func (f File) quarantine(reason string) error {
+	dest := fmt.Sprintf("%s.corrupt-%s", f.Path, time.Now().Format("20060102-150405"))
+	for i := 1; exists(dest); i++ {
+		dest = fmt.Sprintf("%s.corrupt-%s-%d", f.Path, time.Now().Format("20060102-150405"), i)
+	}
+	if err := os.Rename(f.Path, dest); err != nil {
+		return fmt.Errorf("%s is damaged (%s): %w", f.Path, reason, err)
+	}
+	return &CorruptError{Path: f.Path, Quarantine: dest, Reason: reason}
+}
+
+func exists(path string) bool {
+	_, err := os.Lstat(path)
+	return err == nil
+}
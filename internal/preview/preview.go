@@ -0,0 +1,338 @@
+package preview
+
+import (
+	"context"
+	"encoding/hex"
+	"mime"
+	"net/http"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/alecthomas/chroma/v2/lexers"
+
+	"github.com/LeperGnome/bt/internal/config"
+)
+
+const (
+	commandTimeout     = 2 * time.Second // default, see config.Previewer.Timeout
+	commandOutputLimit = 64 * 1024
+)
+
+// Preview is a file, prepared for display by one of the preview kinds.
+type Preview struct {
+	Kind  string // one of config.Preview* kinds, empty - none of the chain could show the file
+	Text  string
+	Lexer string // syntax of highlight kind
+	size  int64
+	read  int64 // offset in the file, Text ends at
+	enc   encoding
+	cmd   *pendingCommand
+	first int    // line of the file, Text starts with
+	start int64  // offset in the file, Text starts at
+	pos   int    // length of the text, decoded before Text
+	marks []mark // starts of the chunks, decoded so far
+}
+
+// Start of a chunk of the file, text can be decoded from, as it was before.
+type mark struct {
+	line   int
+	offset int64
+	pos    int // length of the text, decoded before the chunk
+}
+
+// Command kind of the chain, waiting to be run, with what's needed to go on with the chain.
+type pendingCommand struct {
+	previewer config.Previewer
+	rest      []string // kinds of the chain after the command
+	path      string
+	size      int64
+	head      []byte
+}
+
+// Reports whether the file goes on after the text of the preview. Only text and highlight
+// kinds are continued, see Continue.
+func (p Preview) Partial() bool {
+	return p.enc != encNone && p.read < p.size
+}
+
+// Returns offset in the file, the next chunk of text starts at.
+func (p Preview) Offset() int64 {
+	return p.read
+}
+
+// Appends the next chunk of the file, read from Offset, to the text, decoding it like
+// the beginning of the file. Character, cut by the end of chunk, is left for the next one.
+func (p *Preview) Continue(chunk []byte) {
+	if !p.Partial() {
+		return
+	}
+	end := p.read+int64(len(chunk)) >= p.size
+	text, n := decodeChunk(p.enc, chunk, end)
+	if n == 0 && !end {
+		p.size = p.read // chunk is too small to continue, nothing more is read
+		return
+	}
+	if last := p.marks[len(p.marks)-1]; p.read > last.offset {
+		p.marks = append(p.marks, mark{line: p.Lines() - 1, offset: p.read, pos: p.pos + len(p.Text)})
+	}
+	p.Text += text
+	p.read += int64(n)
+	if end {
+		p.read = p.size
+	}
+}
+
+// Returns line of the file, Text starts with. It's not the first one, when the beginning
+// of the text was dropped by Trim.
+func (p Preview) FirstLine() int {
+	return p.first
+}
+
+// Returns number of lines of the file up to the end of Text.
+func (p Preview) Lines() int {
+	return p.first + strings.Count(p.Text, "\n") + 1
+}
+
+// Returns part of the file, that is to be read back and passed to Prepend, so that Text
+// starts with the line whole: its offset and length up to the start of Text. Reports false,
+// when Text starts with the line or before it already.
+func (p Preview) Back(line int) (int64, int64, bool) {
+	if p.enc == encNone || p.first < line || p.start == p.marks[0].offset {
+		return 0, 0, false
+	}
+	m := p.marks[0]
+	for _, next := range p.marks[1:] {
+		if next.line >= line || next.offset >= p.start {
+			break
+		}
+		m = next
+	}
+	return m.offset, p.start - m.offset, true
+}
+
+// Puts chunk, read back from offset, returned by Back, before Text. It's decoded chunk by
+// chunk, the way it was read before, so the text is the same.
+func (p *Preview) Prepend(offset int64, chunk []byte) {
+	i := slices.IndexFunc(p.marks, func(m mark) bool { return m.offset == offset })
+	if i < 0 || offset+int64(len(chunk)) != p.start {
+		return
+	}
+	var text strings.Builder
+	for j := i; j < len(p.marks) && p.marks[j].offset < p.start; j++ {
+		end := p.start
+		if j+1 < len(p.marks) {
+			end = min(end, p.marks[j+1].offset)
+		}
+		decoded, _ := decodeChunk(p.enc, chunk[p.marks[j].offset-offset:end-offset], false)
+		text.WriteString(decoded)
+	}
+	m := p.marks[i]
+	p.Text = text.String() + p.Text
+	p.first, p.start, p.pos = m.line, m.offset, m.pos
+}
+
+// Drops Text and goes on from offset, returned by Back, so that lines far back are read
+// on from there with Continue, instead of reading the whole way back to Text.
+func (p *Preview) Rewind(offset int64) {
+	i := slices.IndexFunc(p.marks, func(m mark) bool { return m.offset == offset })
+	if i < 0 {
+		return
+	}
+	m := p.marks[i]
+	p.Text, p.marks = "", p.marks[:i+1]
+	p.first, p.start, p.read, p.pos = m.line, m.offset, m.offset, m.pos
+}
+
+// Drops chunks of Text, that are before line from or after line to, so that only a window
+// of a huge file is kept. Dropped chunks are read again with Back and Continue.
+func (p *Preview) Trim(from, to int) {
+	if p.enc == encNone {
+		return
+	}
+	for i, m := range p.marks {
+		if m.offset <= p.start || m.offset >= p.read {
+			continue
+		}
+		if m.line > to {
+			p.Text = p.Text[:m.pos-p.pos]
+			p.read = m.offset
+			p.marks = p.marks[:i+1]
+			break
+		}
+		if m.line < from {
+			p.Text = p.Text[m.pos-p.pos:]
+			p.first, p.start, p.pos = m.line, m.offset, m.pos
+		}
+	}
+}
+
+// Makes preview of text kinds, that is continued from the end of head.
+func textPreview(kind, lexer string, size int64, head []byte, end bool) (Preview, bool) {
+	text, enc, bom, n, ok := decodeText(head, end)
+	if !ok {
+		return Preview{}, false
+	}
+	start := int64(bom)
+	return Preview{
+		Kind: kind, Text: text, Lexer: lexer, size: size, read: start + int64(n), enc: enc,
+		start: start, marks: []mark{{offset: start}},
+	}, true
+}
+
+// Makes preview of the file at path, trying kinds of the chain, that matches the file, in order.
+// head is the beginning of the file content, size is the full file size.
+// Text of text and highlight kinds can be continued with the rest of the file, see Continue.
+// Command kind is not run here, as it may take up to its timeout: preview is returned pending then.
+func Make(ps []config.Previewer, path string, size int64, head []byte) Preview {
+	previewer := previewerFor(ps, path, size, head)
+	return makeChain(previewer, previewer.Chain, path, size, head)
+}
+
+func makeChain(previewer config.Previewer, chain []string, path string, size int64, head []byte) Preview {
+	for i, kind := range chain {
+		if kind == config.PreviewCommand {
+			cmd := &pendingCommand{previewer: previewer, rest: chain[i+1:], path: path, size: size, head: head}
+			return Preview{Kind: kind, cmd: cmd}
+		}
+		if p, ok := makeKind(kind, previewer, path, size, head); ok {
+			return p
+		}
+	}
+	return Preview{}
+}
+
+// Reports whether preview waits for output of its command, see Run and Finish.
+func (p Preview) Pending() bool {
+	return p.cmd != nil
+}
+
+// Runs command of the pending preview. It's killed after the previewer timeout, so it's better
+// run in background.
+func (p Preview) Run() (string, error) {
+	timeout := p.cmd.previewer.Timeout
+	if timeout == 0 {
+		timeout = commandTimeout
+	}
+	return runCommand(p.cmd.previewer.Command, p.cmd.path, timeout)
+}
+
+// Returns preview, made of output of the pending preview command. Failed command or empty output
+// goes on with the rest of the chain.
+func (p Preview) Finish(out string, err error) Preview {
+	if err == nil && out != "" {
+		return Preview{Kind: config.PreviewCommand, Text: out}
+	}
+	c := p.cmd
+	return makeChain(c.previewer, c.rest, c.path, c.size, c.head)
+}
+
+func makeKind(kind string, previewer config.Previewer, path string, size int64, head []byte) (Preview, bool) {
+	end := size <= int64(len(head))
+	if !end && kind != config.PreviewText && kind != config.PreviewHighlight {
+		head = trimPartialRune(head)
+	}
+	switch kind {
+	case config.PreviewText:
+		return textPreview(kind, "", size, head, end)
+	case config.PreviewHighlight:
+		lexer := lexers.Match(filepath.Base(path))
+		if lexer == nil {
+			return Preview{}, false
+		}
+		return textPreview(kind, lexer.Config().Name, size, head, end)
+	case config.PreviewSummary:
+		text, ok := summarize(head, size)
+		if !ok {
+			return Preview{}, false
+		}
+		return Preview{Kind: kind, Text: text}, true
+	case config.PreviewHex:
+		return Preview{Kind: kind, Text: strings.TrimSuffix(hex.Dump(head), "\n")}, true
+	}
+	return Preview{}, false
+}
+
+// Returns the first previewer, matching file, or the default one.
+func previewerFor(ps []config.Previewer, file string, size int64, head []byte) config.Previewer {
+	for _, p := range ps {
+		if p.MaxSize > 0 && size > p.MaxSize {
+			continue
+		}
+		if Matches(p.Match, file, func() []byte { return head }) {
+			return p
+		}
+	}
+	return config.Previewer{Chain: config.DefaultPreviewChain}
+}
+
+// Reports whether file matches one of patterns: name globs, or MIME types, when they contain a slash.
+// Head of the file is read only for MIME types, that its extension doesn't tell.
+func Matches(patterns []string, file string, head func() []byte) bool {
+	name := filepath.Base(file)
+	mimeType := ""
+	for _, m := range patterns {
+		target := name
+		if strings.Contains(m, "/") {
+			if mimeType == "" {
+				mimeType = detectMIME(name, head)
+			}
+			target = mimeType
+		}
+		if ok, _ := path.Match(m, target); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// Guesses MIME type by extension, falling back to content sniffing. Parameters are dropped.
+func detectMIME(name string, head func() []byte) string {
+	t := mime.TypeByExtension(filepath.Ext(name))
+	if t == "" {
+		t = http.DetectContentType(head())
+	}
+	t, _, _ = strings.Cut(t, ";")
+	return strings.TrimSpace(t)
+}
+
+// Runs command, {} in its arguments is replaced with path, or path is appended, if there is no {}.
+// Output is cut at the limit, command is killed after timeout.
+func runCommand(command, path string, timeout time.Duration) (string, error) {
+	args := strings.Fields(command)
+	substituted := false
+	for i, a := range args {
+		if strings.Contains(a, "{}") {
+			args[i] = strings.ReplaceAll(a, "{}", path)
+			substituted = true
+		}
+	}
+	if !substituted {
+		args = append(args, path)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	out, err := exec.CommandContext(ctx, args[0], args[1:]...).Output()
+	if err != nil {
+		return "", err
+	}
+	out = out[:min(len(out), commandOutputLimit)]
+	return strings.ToValidUTF8(strings.TrimRight(string(out), "\n"), "?"), nil
+}
+
+// Drops incomplete rune at the end of the cut content, so cut text stays valid.
+func trimPartialRune(b []byte) []byte {
+	for i := 1; i < utf8.UTFMax && i <= len(b); i++ {
+		if utf8.RuneStart(b[len(b)-i]) {
+			if !utf8.FullRune(b[len(b)-i:]) {
+				return b[:len(b)-i]
+			}
+			break
+		}
+	}
+	return b
+}
@@ -0,0 +1,313 @@
+package preview
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"image"
+	_ "image/gif"
+	_ "image/jpeg"
+	_ "image/png"
+	"strings"
+	"time"
+
+	"github.com/LeperGnome/bt/internal/i18n"
+)
+
+// Binary format, recognized by magic bytes.
+type format struct {
+	name    string
+	kind    string // i18n key of the category
+	magic   func(b []byte) bool
+	details func(b []byte) []field // read from the file head, may be nil
+}
+
+type field struct {
+	label string // i18n key
+	value string
+}
+
+func prefix(magic string) func(b []byte) bool {
+	return func(b []byte) bool { return bytes.HasPrefix(b, []byte(magic)) }
+}
+
+func at(offset int, magic string) func(b []byte) bool {
+	return func(b []byte) bool { return len(b) >= offset && bytes.HasPrefix(b[offset:], []byte(magic)) }
+}
+
+// For short magic, that text may start with too: binary content has zero bytes.
+func binaryPrefix(magic string) func(b []byte) bool {
+	return func(b []byte) bool { return bytes.HasPrefix(b, []byte(magic)) && bytes.IndexByte(b, 0) >= 0 }
+}
+
+func riff(form string) func(b []byte) bool {
+	return func(b []byte) bool { return len(b) >= 12 && string(b[:4]) == "RIFF" && string(b[8:12]) == form }
+}
+
+var formats = []format{
+	{"PNG", "preview.kind-image", prefix("\x89PNG\r\n\x1a\n"), imageDetails},
+	{"GIF", "preview.kind-image", func(b []byte) bool { return prefix("GIF87a")(b) || prefix("GIF89a")(b) }, imageDetails},
+	{"JPEG", "preview.kind-image", prefix("\xff\xd8\xff"), imageDetails},
+	{"WebP", "preview.kind-image", riff("WEBP"), nil},
+	{"BMP", "preview.kind-image", binaryPrefix("BM"), bmpDetails},
+	{"TIFF", "preview.kind-image", func(b []byte) bool {
+		return bytes.HasPrefix(b, []byte("II*\x00")) || bytes.HasPrefix(b, []byte("MM\x00*"))
+	}, nil},
+	{"WAV", "preview.kind-audio", riff("WAVE"), wavDetails},
+	{"AVI", "preview.kind-video", riff("AVI "), nil},
+	{"MP3", "preview.kind-audio", binaryPrefix("ID3"), nil},
+	{"Ogg", "preview.kind-audio", prefix("OggS"), nil},
+	{"FLAC", "preview.kind-audio", prefix("fLaC"), flacDetails},
+	{"MP4 / QuickTime", "preview.kind-video", at(4, "ftyp"), mp4Details},
+	{"Matroska / WebM", "preview.kind-video", prefix("\x1a\x45\xdf\xa3"), nil},
+	{"PDF", "preview.kind-document", prefix("%PDF-"), pdfDetails},
+	{"ZIP", "preview.kind-archive", prefix("PK\x03\x04"), zipDetails},
+	{"gzip", "preview.kind-archive", prefix("\x1f\x8b"), gzipDetails},
+	{"bzip2", "preview.kind-archive", func(b []byte) bool { return prefix("BZh")(b) && at(4, "1AY&SY")(b) }, nil},
+	{"xz", "preview.kind-archive", prefix("\xfd7zXZ\x00"), nil},
+	{"Zstandard", "preview.kind-archive", prefix("\x28\xb5\x2f\xfd"), nil},
+	{"7-Zip", "preview.kind-archive", prefix("7z\xbc\xaf\x27\x1c"), nil},
+	{"RAR", "preview.kind-archive", prefix("Rar!\x1a\x07"), nil},
+	{"tar", "preview.kind-archive", at(257, "ustar"), tarDetails},
+	{"ELF", "preview.kind-executable", prefix("\x7fELF"), elfDetails},
+	{"Mach-O", "preview.kind-executable", func(b []byte) bool {
+		for _, m := range []string{"\xfe\xed\xfa\xce", "\xfe\xed\xfa\xcf", "\xce\xfa\xed\xfe", "\xcf\xfa\xed\xfe"} {
+			if bytes.HasPrefix(b, []byte(m)) {
+				return true
+			}
+		}
+		// universal binary shares magic with Java class, but has only a few architectures
+		return bytes.HasPrefix(b, []byte("\xca\xfe\xba\xbe")) && len(b) >= 8 && binary.BigEndian.Uint32(b[4:]) < 40
+	}, nil},
+	{"Java class", "preview.kind-executable", prefix("\xca\xfe\xba\xbe"), classDetails},
+	{"PE", "preview.kind-executable", binaryPrefix("MZ"), peDetails},
+	{"WebAssembly", "preview.kind-executable", prefix("\x00asm"), nil},
+	{"SQLite", "preview.kind-database", prefix("SQLite format 3\x00"), sqliteDetails},
+}
+
+// Returns format of the known binary file, nil if head is not recognized.
+func identify(head []byte) *format {
+	for i := range formats {
+		if formats[i].magic(head) {
+			return &formats[i]
+		}
+	}
+	return nil
+}
+
+// Describes known binary file: format, size and metadata, found in its head.
+func summarize(head []byte, size int64) (string, bool) {
+	f := identify(head)
+	if f == nil {
+		return "", false
+	}
+	fields := []field{
+		{"preview.format", fmt.Sprintf("%s (%s)", f.name, i18n.T(f.kind))},
+		{"preview.size", humanSize(size)},
+	}
+	if f.details != nil {
+		fields = append(fields, f.details(head)...)
+	}
+	width := 0
+	for _, fl := range fields {
+		width = max(width, len([]rune(i18n.T(fl.label))))
+	}
+	lines := make([]string, 0, len(fields))
+	for _, fl := range fields {
+		label := i18n.T(fl.label)
+		lines = append(lines, label+":"+strings.Repeat(" ", width-len([]rune(label))+1)+fl.value)
+	}
+	return strings.Join(lines, "\n"), true
+}
+
+func humanSize(n int64) string {
+	units := []string{"B", "KiB", "MiB", "GiB", "TiB"}
+	v := float64(n)
+	i := 0
+	for v >= 1024 && i < len(units)-1 {
+		v /= 1024
+		i++
+	}
+	if i == 0 {
+		return fmt.Sprintf("%d B", n)
+	}
+	return fmt.Sprintf("%.1f %s (%d B)", v, units[i], n)
+}
+
+func dimensions(w, h int) field {
+	return field{"preview.dimensions", fmt.Sprintf("%d×%d", w, h)}
+}
+
+func imageDetails(b []byte) []field {
+	cfg, _, err := image.DecodeConfig(bytes.NewReader(b))
+	if err != nil {
+		return nil
+	}
+	return []field{dimensions(cfg.Width, cfg.Height)}
+}
+
+func bmpDetails(b []byte) []field {
+	if len(b) < 26 {
+		return nil
+	}
+	w := int32(binary.LittleEndian.Uint32(b[18:]))
+	h := int32(binary.LittleEndian.Uint32(b[22:]))
+	return []field{dimensions(int(w), int(max(h, -h)))} // negative height - top-down bitmap
+}
+
+func wavDetails(b []byte) []field {
+	if len(b) < 36 || string(b[12:16]) != "fmt " {
+		return nil
+	}
+	return []field{
+		{"preview.channels", fmt.Sprint(binary.LittleEndian.Uint16(b[22:]))},
+		{"preview.sample-rate", fmt.Sprintf("%d Hz", binary.LittleEndian.Uint32(b[24:]))},
+		{"preview.bits", fmt.Sprint(binary.LittleEndian.Uint16(b[34:]))},
+	}
+}
+
+func flacDetails(b []byte) []field {
+	// STREAMINFO block follows the magic: 4 bytes of header, sample rate is in bits 80-99
+	if len(b) < 8+18 {
+		return nil
+	}
+	info := b[8:]
+	rate := uint32(info[10])<<12 | uint32(info[11])<<4 | uint32(info[12])>>4
+	channels := (info[12]>>1)&0x7 + 1
+	return []field{
+		{"preview.channels", fmt.Sprint(channels)},
+		{"preview.sample-rate", fmt.Sprintf("%d Hz", rate)},
+	}
+}
+
+func mp4Details(b []byte) []field {
+	if len(b) < 12 {
+		return nil
+	}
+	return []field{{"preview.brand", strings.TrimSpace(string(b[8:12]))}}
+}
+
+func pdfDetails(b []byte) []field {
+	version, _, _ := bytes.Cut(b[5:min(len(b), 12)], []byte("\n"))
+	return []field{{"preview.version", strings.TrimSpace(string(version))}}
+}
+
+func zipDetails(b []byte) []field {
+	// local file header: name length at 26, name at 30
+	if len(b) < 30 {
+		return nil
+	}
+	n := int(binary.LittleEndian.Uint16(b[26:]))
+	if len(b) < 30+n {
+		return nil
+	}
+	return []field{{"preview.first-entry", string(b[30 : 30+n])}}
+}
+
+func gzipDetails(b []byte) []field {
+	if len(b) < 10 {
+		return nil
+	}
+	fields := []field{}
+	if mtime := binary.LittleEndian.Uint32(b[4:]); mtime != 0 {
+		fields = append(fields, field{"preview.modified", time.Unix(int64(mtime), 0).Format(time.DateTime)})
+	}
+	const fextra, fname = 0x04, 0x08
+	rest := b[10:]
+	if b[3]&fextra != 0 {
+		if len(rest) < 2 {
+			return fields
+		}
+		rest = rest[min(len(rest), 2+int(binary.LittleEndian.Uint16(rest))):]
+	}
+	if b[3]&fname != 0 {
+		if name, _, ok := bytes.Cut(rest, []byte{0}); ok {
+			fields = append(fields, field{"preview.original-name", string(name)})
+		}
+	}
+	return fields
+}
+
+func tarDetails(b []byte) []field {
+	name, _, _ := bytes.Cut(b[:100], []byte{0})
+	return []field{{"preview.first-entry", string(name)}}
+}
+
+var elfMachines = map[uint16]string{
+	3: "x86", 8: "MIPS", 20: "PowerPC", 21: "PowerPC64", 40: "ARM", 62: "x86-64", 183: "AArch64", 243: "RISC-V",
+}
+
+var elfTypes = map[uint16]string{1: "relocatable", 2: "executable", 3: "shared object", 4: "core dump"}
+
+func elfDetails(b []byte) []field {
+	if len(b) < 20 {
+		return nil
+	}
+	var order binary.ByteOrder = binary.LittleEndian
+	endian := "little endian"
+	if b[5] == 2 {
+		order, endian = binary.BigEndian, "big endian"
+	}
+	class := "32-bit"
+	if b[4] == 2 {
+		class = "64-bit"
+	}
+	typ := order.Uint16(b[16:])
+	machine := order.Uint16(b[18:])
+	return []field{
+		{"preview.type", orNumber(elfTypes, typ)},
+		{"preview.architecture", fmt.Sprintf("%s, %s, %s", orNumber(elfMachines, machine), class, endian)},
+	}
+}
+
+var peMachines = map[uint16]string{0x14c: "x86", 0x8664: "x86-64", 0x1c0: "ARM", 0xaa64: "ARM64"}
+
+func peDetails(b []byte) []field {
+	if len(b) < 0x40 {
+		return nil
+	}
+	off := int(binary.LittleEndian.Uint32(b[0x3c:]))
+	if off < 0 || len(b) < off+24 || string(b[off:off+4]) != "PE\x00\x00" {
+		return []field{{"preview.type", "MS-DOS"}}
+	}
+	machine := binary.LittleEndian.Uint16(b[off+4:])
+	characteristics := binary.LittleEndian.Uint16(b[off+22:])
+	typ := "executable"
+	if characteristics&0x2000 != 0 {
+		typ = "DLL"
+	}
+	return []field{
+		{"preview.type", typ},
+		{"preview.architecture", orNumber(peMachines, machine)},
+	}
+}
+
+func classDetails(b []byte) []field {
+	if len(b) < 8 {
+		return nil
+	}
+	major := binary.BigEndian.Uint16(b[6:])
+	version := fmt.Sprint(major)
+	if major >= 49 {
+		version = fmt.Sprintf("%d (Java %d)", major, major-44)
+	}
+	return []field{{"preview.version", version}}
+}
+
+func sqliteDetails(b []byte) []field {
+	if len(b) < 18 {
+		return nil
+	}
+	page := int(binary.BigEndian.Uint16(b[16:]))
+	if page == 1 {
+		page = 65536
+	}
+	return []field{{"preview.page-size", fmt.Sprintf("%d B", page)}}
+}
+
+func orNumber(names map[uint16]string, v uint16) string {
+	if name, ok := names[v]; ok {
+		return name
+	}
+	return fmt.Sprintf("0x%x", v)
+}
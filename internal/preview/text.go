@@ -0,0 +1,146 @@
+package preview
+
+import (
+	"bytes"
+	"encoding/binary"
+	"strings"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+// Encoding of text previews, the rest of the file is decoded with, see Preview.Continue.
+type encoding int
+
+const (
+	encNone encoding = iota // not a text
+	encUTF8
+	encUTF16LE
+	encUTF16BE
+	encLegacy
+)
+
+// Returns head as UTF-8 text, when it looks like text in UTF-8 (with or without BOM), UTF-16
+// or a legacy 8-bit encoding (read as Windows-1252). Known binary formats are never text.
+// Also returns the encoding, length of BOM and number of bytes of head after it, the text
+// is made of: character, cut by the end of head, is left for the next chunk, unless head
+// ends the file.
+func decodeText(head []byte, end bool) (string, encoding, int, int, bool) {
+	if identify(head) != nil {
+		return "", encNone, 0, 0, false
+	}
+	whole := head
+	if !end {
+		whole = trimPartialRune(head)
+	}
+	enc, bom := encUTF8, 0
+	switch {
+	case bytes.HasPrefix(head, []byte{0xEF, 0xBB, 0xBF}):
+		bom = 3
+	case bytes.HasPrefix(head, []byte{0xFF, 0xFE}):
+		enc, bom = encUTF16LE, 2
+	case bytes.HasPrefix(head, []byte{0xFE, 0xFF}):
+		enc, bom = encUTF16BE, 2
+	case utf8.Valid(whole) && bytes.IndexByte(head, 0) < 0:
+	default:
+		order, ok := guessUTF16(head)
+		switch {
+		case ok && order == binary.LittleEndian:
+			enc = encUTF16LE
+		case ok:
+			enc = encUTF16BE
+		case looksLegacy(head):
+			enc = encLegacy
+		default:
+			return "", encNone, 0, 0, false
+		}
+	}
+	text, n := decodeChunk(enc, head[bom:], end)
+	return text, enc, bom, n, true
+}
+
+// Decodes chunk of text in enc. Returns the text and number of bytes of chunk, it's made of.
+func decodeChunk(enc encoding, chunk []byte, end bool) (string, int) {
+	switch enc {
+	case encUTF8:
+		if !end {
+			chunk = trimPartialRune(chunk)
+		}
+		return strings.ToValidUTF8(string(chunk), string(utf8.RuneError)), len(chunk)
+	case encUTF16LE:
+		return decodeUTF16(chunk, binary.LittleEndian, end)
+	case encUTF16BE:
+		return decodeUTF16(chunk, binary.BigEndian, end)
+	case encLegacy:
+		return decodeWindows1252(chunk), len(chunk)
+	}
+	return "", 0
+}
+
+func decodeUTF16(b []byte, order binary.ByteOrder, end bool) (string, int) {
+	units := make([]uint16, len(b)/2) // odd byte of cut content is dropped
+	for i := range units {
+		units[i] = order.Uint16(b[2*i:])
+	}
+	// first half of a surrogate pair, cut by the end of chunk, is left for the next one
+	if !end && len(units) > 0 && utf16.IsSurrogate(rune(units[len(units)-1])) && units[len(units)-1] < 0xDC00 {
+		units = units[:len(units)-1]
+	}
+	return string(utf16.Decode(units)), 2 * len(units)
+}
+
+// UTF-16 without BOM is recognized by ASCII text, interleaved with zero bytes.
+func guessUTF16(b []byte) (binary.ByteOrder, bool) {
+	pairs := len(b) / 2
+	if pairs < 2 {
+		return nil, false
+	}
+	even, odd := 0, 0 // zero bytes at even and odd positions
+	for i := 0; i < pairs*2; i += 2 {
+		if b[i] == 0 && b[i+1] != 0 {
+			even++
+		}
+		if b[i+1] == 0 && b[i] != 0 {
+			odd++
+		}
+	}
+	switch {
+	case odd*10 >= pairs*9:
+		return binary.LittleEndian, true
+	case even*10 >= pairs*9:
+		return binary.BigEndian, true
+	}
+	return nil, false
+}
+
+// Legacy 8-bit text has no zero bytes and almost no control characters.
+func looksLegacy(b []byte) bool {
+	controls := 0
+	for _, c := range b {
+		switch {
+		case c == 0:
+			return false
+		case c < 0x20 && c != '\n' && c != '\r' && c != '\t' && c != '\f' && c != 0x1b:
+			controls++
+		}
+	}
+	return controls*100 <= len(b)
+}
+
+// Characters of Windows-1252 at 0x80 - 0x9F, where Latin-1 has control characters.
+var windows1252 = [32]rune{
+	'€', '�', '‚', 'ƒ', '„', '…', '†', '‡', 'ˆ', '‰', 'Š', '‹', 'Œ', '�', 'Ž', '�',
+	'�', '‘', '’', '“', '”', '•', '–', '—', '˜', '™', 'š', '›', 'œ', '�', 'ž', 'Ÿ',
+}
+
+func decodeWindows1252(b []byte) string {
+	var sb strings.Builder
+	sb.Grow(len(b))
+	for _, c := range b {
+		if c >= 0x80 && c < 0xA0 {
+			sb.WriteRune(windows1252[c-0x80])
+		} else {
+			sb.WriteRune(rune(c))
+		}
+	}
+	return sb.String()
+}
@@ -0,0 +1,158 @@
+// Package remote browses other machines over SFTP.
+package remote
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net"
+	"net/url"
+	"os"
+	"os/user"
+	"path/filepath"
+	"strings"
+
+	"github.com/pkg/sftp"
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/agent"
+	"golang.org/x/crypto/ssh/knownhosts"
+
+	"github.com/LeperGnome/bt/internal/tree"
+)
+
+const scheme = "sftp"
+
+// Address of a remote tree: sftp://[user@]host[:port][/path].
+type URL struct {
+	User string
+	Host string
+	Port string
+	Path string // empty - home directory
+}
+
+// Reports whether s looks like a remote address, not a local path.
+func IsURL(s string) bool {
+	return strings.HasPrefix(s, scheme+"://")
+}
+
+func Parse(s string) (URL, error) {
+	u, err := url.Parse(s)
+	if err != nil {
+		return URL{}, err
+	}
+	if u.Scheme != scheme || u.Hostname() == "" {
+		return URL{}, fmt.Errorf("'%s' is not an sftp://[user@]host[:port]/path address", s)
+	}
+	name := u.User.Username()
+	if name == "" {
+		if cur, err := user.Current(); err == nil {
+			name = cur.Username
+		}
+	}
+	return URL{User: name, Host: u.Hostname(), Port: cmp.Or(u.Port(), "22"), Path: u.Path}, nil
+}
+
+// FS is tree.FS of a remote machine. Previews are read over the connection,
+// rename, delete and mkdir are done with SFTP calls. Changes are not watched.
+type FS struct {
+	client *sftp.Client
+	conn   *ssh.Client
+}
+
+// Connects to u with keys from ssh-agent and ~/.ssh. Host must be in ~/.ssh/known_hosts.
+// Password is asked with prompt, if keys don't fit.
+func Dial(u URL, prompt func(question string) (string, error)) (*FS, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return nil, err
+	}
+	hostKeys, err := knownhosts.New(filepath.Join(home, ".ssh", "known_hosts"))
+	if err != nil {
+		return nil, fmt.Errorf("can't check host key: %w", err)
+	}
+	password := ssh.PasswordCallback(func() (string, error) {
+		return prompt(fmt.Sprintf("%s@%s's password: ", u.User, u.Host))
+	})
+	cfg := &ssh.ClientConfig{
+		User:            u.User,
+		Auth:            append(keyAuth(home), password),
+		HostKeyCallback: hostKeys,
+	}
+	conn, err := ssh.Dial("tcp", net.JoinHostPort(u.Host, u.Port), cfg)
+	var keyErr *knownhosts.KeyError
+	if errors.As(err, &keyErr) && len(keyErr.Want) == 0 {
+		return nil, fmt.Errorf("%s is not a known host, connect with ssh once to check and add its key", u.Host)
+	}
+	if err != nil {
+		return nil, err
+	}
+	client, err := sftp.NewClient(conn)
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+	return &FS{client: client, conn: conn}, nil
+}
+
+// Returns ssh-agent and unencrypted default keys, that are present.
+func keyAuth(home string) []ssh.AuthMethod {
+	methods := []ssh.AuthMethod{}
+	if sock := os.Getenv("SSH_AUTH_SOCK"); sock != "" {
+		if c, err := net.Dial("unix", sock); err == nil {
+			methods = append(methods, ssh.PublicKeysCallback(agent.NewClient(c).Signers))
+		}
+	}
+	signers := []ssh.Signer{}
+	for _, name := range []string{"id_ed25519", "id_ecdsa", "id_rsa"} {
+		data, err := os.ReadFile(filepath.Join(home, ".ssh", name))
+		if err != nil {
+			continue
+		}
+		if signer, err := ssh.ParsePrivateKey(data); err == nil {
+			signers = append(signers, signer)
+		}
+	}
+	if len(signers) > 0 {
+		methods = append(methods, ssh.PublicKeys(signers...))
+	}
+	return methods
+}
+
+func (f *FS) Close() error {
+	return errors.Join(f.client.Close(), f.conn.Close())
+}
+
+func (f *FS) ReadDir(path string) ([]fs.DirEntry, error) {
+	infos, err := f.client.ReadDir(path)
+	if err != nil {
+		return nil, err
+	}
+	entries := make([]fs.DirEntry, 0, len(infos))
+	for _, info := range infos {
+		entries = append(entries, fs.FileInfoToDirEntry(info))
+	}
+	return entries, nil
+}
+
+func (f *FS) Stat(path string) (fs.FileInfo, error)  { return f.client.Stat(path) }
+func (f *FS) Lstat(path string) (fs.FileInfo, error) { return f.client.Lstat(path) }
+
+// Resolves path on the server, relative paths are relative to the home directory.
+func (f *FS) EvalSymlinks(path string) (string, error) { return f.client.RealPath(path) }
+
+func (f *FS) Open(path string) (fs.File, error) { return f.client.Open(path) }
+
+func (f *FS) Watch() (tree.Watcher, <-chan tree.NodeChange, error) { return tree.Unwatched() }
+
+func (f *FS) Rename(oldPath, newPath string) error { return f.client.Rename(oldPath, newPath) }
+func (f *FS) RemoveAll(path string) error          { return f.client.RemoveAll(path) }
+func (f *FS) MkdirAll(path string) error           { return f.client.MkdirAll(path) }
+
+func (f *FS) Create(path string) error {
+	file, err := f.client.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL)
+	if err != nil {
+		return err
+	}
+	return file.Close()
+}
@@ -0,0 +1,134 @@
+package search
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"strings"
+	"sync"
+	"unicode"
+
+	"github.com/LeperGnome/bt/internal/artifacts"
+	t "github.com/LeperGnome/bt/internal/tree"
+)
+
+const (
+	// Lines longer than that are cut in matches.
+	MaxSnippetLen = 200
+	// Files, that have NUL byte in the beginning, are skipped as binary.
+	binarySniffLen = 8000
+)
+
+type Match struct {
+	Path string
+	Line int // 1-based
+	Text string
+}
+
+// Compiles pattern as a regular expression. Patterns without upper case
+// letters match case-insensitively.
+func Compile(pattern string) (*regexp.Regexp, error) {
+	if !strings.ContainsFunc(pattern, unicode.IsUpper) {
+		pattern = "(?i)" + pattern
+	}
+	return regexp.Compile(pattern)
+}
+
+// Searches contents of files under root with parallel workers and sends matches
+// to out, closing it when done. Version control, dependency and build directories,
+// as well as binary files, are skipped.
+func Grep(ctx context.Context, root string, re *regexp.Regexp, out chan<- Match) error {
+	defer close(out)
+	paths := make(chan string)
+	var wg sync.WaitGroup
+	for range runtime.NumCPU() {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for p := range paths {
+				grepFile(ctx, p, re, out)
+			}
+		}()
+	}
+	// hardlinked files and bind mounted directories are searched once, like in tree.DirSize
+	seen := map[t.FileID]struct{}{}
+	err := filepath.WalkDir(root, func(p string, d fs.DirEntry, err error) error {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return ctxErr
+		}
+		if err != nil {
+			if d != nil && d.IsDir() && p != root {
+				return fs.SkipDir
+			}
+			return nil
+		}
+		if d.IsDir() && p != root && (isVCSDir(d.Name()) || artifacts.IsArtifactDir(p)) {
+			return fs.SkipDir
+		}
+		if !d.IsDir() && !d.Type().IsRegular() {
+			return nil
+		}
+		if info, err := d.Info(); err == nil {
+			if id, ok := t.FileIDOf(info); ok {
+				if _, dup := seen[id]; dup {
+					if d.IsDir() {
+						return fs.SkipDir
+					}
+					return nil
+				}
+				seen[id] = struct{}{}
+			}
+		}
+		if d.IsDir() {
+			return nil
+		}
+		select {
+		case paths <- p:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+		return nil
+	})
+	close(paths)
+	wg.Wait()
+	return err
+}
+
+func grepFile(ctx context.Context, path string, re *regexp.Regexp, out chan<- Match) {
+	f, err := os.Open(path)
+	if err != nil {
+		return
+	}
+	defer f.Close()
+	r := bufio.NewReader(f)
+	if head, _ := r.Peek(binarySniffLen); bytes.IndexByte(head, 0) >= 0 {
+		return
+	}
+	sc := bufio.NewScanner(r)
+	sc.Buffer(make([]byte, 64*1024), 1024*1024)
+	line := 0
+	for sc.Scan() {
+		line++
+		if !re.Match(sc.Bytes()) {
+			continue
+		}
+		text := strings.TrimSpace(sc.Text())
+		if runes := []rune(text); len(runes) > MaxSnippetLen {
+			text = string(runes[:MaxSnippetLen])
+		}
+		select {
+		case out <- Match{Path: path, Line: line, Text: text}:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+func isVCSDir(name string) bool {
+	return name == ".git" || name == ".hg" || name == ".svn"
+}
@@ -0,0 +1,147 @@
+package share
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/charmbracelet/x/ansi"
+)
+
+// Server publishes rendered frames to read-only viewers: a web page
+// at "/" and a raw terminal stream at "/tty" (curl -sN http://addr/tty).
+type Server struct {
+	mu          sync.Mutex
+	frame       string
+	subscribers map[chan string]struct{}
+}
+
+func New() *Server {
+	return &Server{subscribers: map[chan string]struct{}{}}
+}
+
+// Sends frame to all viewers. Slow viewers skip intermediate frames.
+func (s *Server) Publish(frame string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if frame == s.frame {
+		return
+	}
+	s.frame = frame
+	for ch := range s.subscribers {
+		select {
+		case <-ch:
+		default:
+		}
+		ch <- frame
+	}
+}
+
+func (s *Server) subscribe() chan string {
+	ch := make(chan string, 1)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.subscribers[ch] = struct{}{}
+	if s.frame != "" {
+		ch <- s.frame
+	}
+	return ch
+}
+
+func (s *Server) unsubscribe(ch chan string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.subscribers, ch)
+}
+
+// Starts serving on addr in background. Listening errors are returned right away.
+func (s *Server) Start(addr string) error {
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
+	go http.Serve(ln, s.Handler())
+	return nil
+}
+
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", s.servePage)
+	mux.HandleFunc("/events", s.serveEvents)
+	mux.HandleFunc("/tty", s.serveTTY)
+	return mux
+}
+
+func (s *Server) servePage(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path != "/" {
+		http.NotFound(w, r)
+		return
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	fmt.Fprint(w, page)
+}
+
+// Streams frames without styling as server-sent events.
+func (s *Server) serveEvents(w http.ResponseWriter, r *http.Request) {
+	s.stream(w, r, "text/event-stream", func(frame string) string {
+		var b strings.Builder
+		for _, line := range strings.Split(ansi.Strip(frame), "\n") {
+			b.WriteString("data: " + line + "\n")
+		}
+		b.WriteString("\n")
+		return b.String()
+	})
+}
+
+// Streams frames with styling. Screen is not cleared between frames, lines are
+// overwritten in place and their leftovers erased, so viewers see no flicker.
+func (s *Server) serveTTY(w http.ResponseWriter, r *http.Request) {
+	s.stream(w, r, "text/plain; charset=utf-8", func(frame string) string {
+		return "\x1b[H" + strings.ReplaceAll(frame, "\n", "\x1b[K\n") + "\x1b[K\x1b[J"
+	})
+}
+
+func (s *Server) stream(w http.ResponseWriter, r *http.Request, contentType string, format func(string) string) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming is not supported", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Cache-Control", "no-cache")
+	ch := s.subscribe()
+	defer s.unsubscribe(ch)
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case frame := <-ch:
+			if _, err := fmt.Fprint(w, format(frame)); err != nil {
+				return
+			}
+			flusher.Flush()
+		}
+	}
+}
+
+const page = `<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>bt</title>
+<style>
+body { background: #1e1e1e; color: #e6e6e6; margin: 1em; }
+pre { font-family: monospace; line-height: 1.2; }
+</style>
+</head>
+<body>
+<pre id="screen">waiting for bt...</pre>
+<script>
+const screen = document.getElementById("screen");
+new EventSource("/events").onmessage = (e) => { screen.textContent = e.data; };
+</script>
+</body>
+</html>
+`
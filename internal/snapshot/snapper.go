@@ -0,0 +1,10 @@
+package snapshot
+
+// Snapper keeps btrfs snapshots of a subvolume in ".snapshots/<number>/snapshot".
+type Snapper struct{}
+
+func (Snapper) Name() string { return "btrfs" }
+
+func (Snapper) Snapshots(dir string) ([]Snapshot, error) {
+	return findInAncestors("btrfs", dir, ".snapshots", "snapshot")
+}
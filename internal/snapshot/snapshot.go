@@ -0,0 +1,79 @@
+package snapshot
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"time"
+)
+
+// Snapshot is a read-only copy of a directory at some point in time.
+type Snapshot struct {
+	Provider string
+	Name     string
+	Time     time.Time
+	Path     string // directory inside of the snapshot, matching the live one
+}
+
+// Provider finds snapshots of a particular filesystem or backup tool.
+type Provider interface {
+	Name() string
+	// Returns snapshots, that contain dir. No snapshots is not an error.
+	Snapshots(dir string) ([]Snapshot, error)
+}
+
+var Providers = []Provider{ZFS{}, Snapper{}, TimeMachine{}}
+
+// Collects snapshots of dir from all providers, newest first.
+// Errors of individual providers are joined, found snapshots are returned anyway.
+func List(dir string) ([]Snapshot, error) {
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, err
+	}
+	all := []Snapshot{}
+	var errs []error
+	for _, p := range Providers {
+		snaps, err := p.Snapshots(abs)
+		if err != nil {
+			errs = append(errs, err)
+		}
+		all = append(all, snaps...)
+	}
+	slices.SortStableFunc(all, func(a, b Snapshot) int { return b.Time.Compare(a.Time) })
+	return all, errors.Join(errs...)
+}
+
+// Walks from dir up to the filesystem root, looking for a directory with snapshots,
+// e.g. ".zfs/snapshot". Each entry of it is a snapshot, inner is the path inside of
+// the entry, where the snapshotted tree begins.
+func findInAncestors(provider, dir, snapshotsDir, inner string) ([]Snapshot, error) {
+	for cur := dir; ; cur = filepath.Dir(cur) {
+		base := filepath.Join(cur, snapshotsDir)
+		entries, err := os.ReadDir(base)
+		if err == nil {
+			rel, err := filepath.Rel(cur, dir)
+			if err != nil {
+				return nil, err
+			}
+			snaps := []Snapshot{}
+			for _, e := range entries {
+				p := filepath.Join(base, e.Name(), inner, rel)
+				info, err := os.Stat(p)
+				if err != nil || !info.IsDir() {
+					continue
+				}
+				t := info.ModTime()
+				if entryInfo, err := e.Info(); err == nil {
+					t = entryInfo.ModTime()
+				}
+				snaps = append(snaps, Snapshot{Provider: provider, Name: e.Name(), Time: t, Path: p})
+			}
+			return snaps, nil
+		}
+		if cur == filepath.Dir(cur) {
+			return nil, nil
+		}
+	}
+}
@@ -0,0 +1,59 @@
+package snapshot
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
+)
+
+// TimeMachine lists macOS backups with tmutil. Backups hold volumes, that hold the tree.
+type TimeMachine struct{}
+
+func (TimeMachine) Name() string { return "timemachine" }
+
+func (TimeMachine) Snapshots(dir string) ([]Snapshot, error) {
+	if runtime.GOOS != "darwin" {
+		return nil, nil
+	}
+	out, err := exec.Command("tmutil", "listbackups").Output()
+	if err != nil {
+		// no backup disk configured is not an error for us
+		return nil, nil
+	}
+	snaps := []Snapshot{}
+	for _, backup := range strings.Split(strings.TrimSpace(string(out)), "\n") {
+		if backup == "" {
+			continue
+		}
+		volumes, err := os.ReadDir(backup)
+		if err != nil {
+			continue
+		}
+		for _, v := range volumes {
+			p := filepath.Join(backup, v.Name(), dir)
+			if info, err := os.Stat(p); err == nil && info.IsDir() {
+				snaps = append(snaps, Snapshot{
+					Provider: "timemachine",
+					Name:     filepath.Base(backup),
+					Time:     backupTime(backup),
+					Path:     p,
+				})
+				break
+			}
+		}
+	}
+	return snaps, nil
+}
+
+// Backups are named like "2024-01-31-101500" or "2024-01-31-101500.backup".
+func backupTime(backup string) time.Time {
+	name := strings.TrimSuffix(filepath.Base(backup), ".backup")
+	t, err := time.ParseInLocation("2006-01-02-150405", name, time.Local)
+	if err != nil {
+		return time.Time{}
+	}
+	return t
+}
@@ -0,0 +1,10 @@
+package snapshot
+
+// ZFS exposes snapshots of a dataset in the hidden ".zfs/snapshot" directory of its mountpoint.
+type ZFS struct{}
+
+func (ZFS) Name() string { return "zfs" }
+
+func (ZFS) Snapshots(dir string) ([]Snapshot, error) {
+	return findInAncestors("zfs", dir, ".zfs/snapshot", "")
+}
@@ -0,0 +1,346 @@
+package state
+
+import (
+	"slices"
+	"strings"
+
+	"github.com/LeperGnome/bt/internal/i18n"
+)
+
+type ActionID string
+
+const (
+	ActionNone            ActionID = ""
+	ActionCancel          ActionID = "cancel"
+	ActionQuit            ActionID = "quit"
+	ActionQuitCd          ActionID = "quit-cd"
+	ActionBookmarkSet     ActionID = "bookmark-set"
+	ActionBookmarkJump    ActionID = "bookmark-jump"
+	ActionAnchorSet       ActionID = "anchor-set"
+	ActionSelectNext      ActionID = "select-next"
+	ActionSelectPrev      ActionID = "select-prev"
+	ActionEnterDir        ActionID = "enter-dir"
+	ActionParentDir       ActionID = "parent-dir"
+	ActionCopy            ActionID = "copy"
+	ActionYank            ActionID = "yank"
+	ActionCopyTo          ActionID = "copy-to"
+	ActionMoveTo          ActionID = "move-to"
+	ActionArchive         ActionID = "archive"
+	ActionExtract         ActionID = "extract"
+	ActionChecksums       ActionID = "checksums"
+	ActionMove            ActionID = "move"
+	ActionDelete          ActionID = "delete"
+	ActionSelectFirst     ActionID = "select-first"
+	ActionSelectLast      ActionID = "select-last"
+	ActionInsert          ActionID = "insert"
+	ActionRename          ActionID = "rename"
+	ActionBulkRename      ActionID = "bulk-rename"
+	ActionChmod           ActionID = "chmod"
+	ActionChown           ActionID = "chown"
+	ActionEdit            ActionID = "edit"
+	ActionOpen            ActionID = "open"
+	ActionOpenWith        ActionID = "open-with"
+	ActionShell           ActionID = "shell"
+	ActionSend            ActionID = "send"
+	ActionExport          ActionID = "export"
+	ActionStaging         ActionID = "staging"
+	ActionSubshell        ActionID = "subshell"
+	ActionCompare         ActionID = "compare"
+	ActionTail            ActionID = "tail"
+	ActionToggleHelp      ActionID = "toggle-help"
+	ActionTogglePreview   ActionID = "toggle-preview"
+	ActionToggleExpand    ActionID = "toggle-expand"
+	ActionExpandAll       ActionID = "expand-all"
+	ActionCollapseUnder   ActionID = "collapse-under"
+	ActionCollapseOthers  ActionID = "collapse-others"
+	ActionToggleMarkdown  ActionID = "toggle-markdown"
+	ActionToggleDetails   ActionID = "toggle-details"
+	ActionToggleDiff      ActionID = "toggle-diff"
+	ActionToggleDualPane  ActionID = "toggle-dual-pane"
+	ActionCycleFocus      ActionID = "cycle-focus"
+	ActionTimeTravel      ActionID = "time-travel"
+	ActionRestore         ActionID = "restore"
+	ActionToggleChurn     ActionID = "toggle-churn"
+	ActionDirSize         ActionID = "dir-size"
+	ActionGrep            ActionID = "grep"
+	ActionFilter          ActionID = "filter"
+	ActionClearFilter     ActionID = "clear-filter"
+	ActionDirsOnly        ActionID = "dirs-only"
+	ActionFilesOnly       ActionID = "files-only"
+	ActionSearch          ActionID = "search"
+	ActionSearchNext      ActionID = "search-next"
+	ActionSearchPrev      ActionID = "search-prev"
+	ActionToggleSelect    ActionID = "toggle-select"
+	ActionGrowTree        ActionID = "grow-tree"
+	ActionToss            ActionID = "toss"
+	ActionPin             ActionID = "pin"
+	ActionBack            ActionID = "back"
+	ActionForward         ActionID = "forward"
+	ActionRecent          ActionID = "recent"
+	ActionMessages        ActionID = "messages"
+	ActionDrives          ActionID = "drives"
+	ActionUndo            ActionID = "undo"
+	ActionRedo            ActionID = "redo"
+	ActionJournal         ActionID = "journal"
+	ActionRegister        ActionID = "register"
+	ActionPinTransient    ActionID = "pin-transient"
+	ActionBasket          ActionID = "basket"
+	ActionShrinkTree      ActionID = "shrink-tree"
+	ActionCleanup         ActionID = "cleanup"
+	ActionCleanArtifacts  ActionID = "clean-artifacts"
+	ActionToggleGroups    ActionID = "toggle-groups"
+	ActionToggleNatural   ActionID = "natural-sort"
+	ActionToggleNumbers   ActionID = "toggle-numbers"
+	ActionToggleWrap      ActionID = "toggle-wrap"
+	ActionToggleColumns   ActionID = "columns"
+	ActionToggleCase      ActionID = "case-sort"
+	ActionFoldGroup       ActionID = "fold-group"
+	ActionUnfoldGroups    ActionID = "unfold-groups"
+	ActionCancelJob       ActionID = "cancel-job"
+	ActionScrollLeft      ActionID = "scroll-left"
+	ActionScrollRight     ActionID = "scroll-right"
+	ActionPreviewDown     ActionID = "preview-down"
+	ActionPreviewUp       ActionID = "preview-up"
+	ActionPreviewPageDown ActionID = "preview-page-down"
+	ActionPreviewPageUp   ActionID = "preview-page-up"
+	ActionNewTab          ActionID = "new-tab"
+	ActionCloseTab        ActionID = "close-tab"
+	ActionNextTab         ActionID = "next-tab"
+	ActionPrevTab         ActionID = "prev-tab"
+)
+
+// Actions is the registry of everything, that can be bound to a key in default mode.
+// Order here is the order actions are listed in help and exports.
+var Actions = []ActionID{
+	ActionSelectNext,
+	ActionSelectPrev,
+	ActionParentDir,
+	ActionEnterDir,
+	ActionInsert,
+	ActionMove,
+	ActionCopy,
+	ActionYank,
+	ActionCopyTo,
+	ActionMoveTo,
+	ActionArchive,
+	ActionExtract,
+	ActionChecksums,
+	ActionDelete,
+	ActionToss,
+	ActionBasket,
+	ActionRename,
+	ActionBulkRename,
+	ActionChmod,
+	ActionChown,
+	ActionEdit,
+	ActionOpen,
+	ActionOpenWith,
+	ActionShell,
+	ActionSend,
+	ActionExport,
+	ActionStaging,
+	ActionSubshell,
+	ActionCompare,
+	ActionTail,
+	ActionSelectFirst,
+	ActionSelectLast,
+	ActionToggleExpand,
+	ActionExpandAll,
+	ActionCollapseUnder,
+	ActionCollapseOthers,
+	ActionDirSize,
+	ActionGrep,
+	ActionFilter,
+	ActionClearFilter,
+	ActionDirsOnly,
+	ActionFilesOnly,
+	ActionSearch,
+	ActionSearchNext,
+	ActionSearchPrev,
+	ActionToggleSelect,
+	ActionCleanup,
+	ActionCleanArtifacts,
+	ActionBack,
+	ActionForward,
+	ActionRecent,
+	ActionMessages,
+	ActionDrives,
+	ActionUndo,
+	ActionRedo,
+	ActionJournal,
+	ActionRegister,
+	ActionBookmarkSet,
+	ActionBookmarkJump,
+	ActionAnchorSet,
+	ActionCancel,
+	ActionCancelJob,
+	ActionToggleHelp,
+	ActionTogglePreview,
+	ActionToggleMarkdown,
+	ActionToggleDiff,
+	ActionPin,
+	ActionPinTransient,
+	ActionToggleDetails,
+	ActionToggleGroups,
+	ActionToggleNatural,
+	ActionToggleNumbers,
+	ActionToggleWrap,
+	ActionToggleColumns,
+	ActionToggleCase,
+	ActionFoldGroup,
+	ActionUnfoldGroups,
+	ActionPreviewDown,
+	ActionPreviewUp,
+	ActionPreviewPageDown,
+	ActionPreviewPageUp,
+	ActionToggleDualPane,
+	ActionGrowTree,
+	ActionShrinkTree,
+	ActionScrollLeft,
+	ActionScrollRight,
+	ActionCycleFocus,
+	ActionTimeTravel,
+	ActionRestore,
+	ActionToggleChurn,
+	ActionNewTab,
+	ActionCloseTab,
+	ActionNextTab,
+	ActionPrevTab,
+	ActionQuit,
+	ActionQuitCd,
+}
+
+// Returns localized description of an action.
+func (id ActionID) Description() string {
+	return i18n.T("action." + string(id))
+}
+
+// Keymap binds actions to key names, as reported by tea.KeyMsg.String().
+// Chords are key names, separated by space: "g g".
+type Keymap map[ActionID][]string
+
+var DefaultKeymap = Keymap{
+	ActionCancel:          {"esc"},
+	ActionQuit:            {"q", "ctrl+c"},
+	ActionQuitCd:          {"Q"},
+	ActionBookmarkSet:     {"m"},
+	ActionBack:            {"(", "alt+left"},
+	ActionForward:         {")", "alt+right"},
+	ActionRecent:          {"ctrl+r"},
+	ActionMessages:        {"g m"},
+	ActionDrives:          {"g d"},
+	ActionUndo:            {"ctrl+z"},
+	ActionRedo:            {"ctrl+y"},
+	ActionJournal:         {"g j"},
+	ActionRegister:        {"@"},
+	ActionBookmarkJump:    {"'"},
+	ActionAnchorSet:       {"A"},
+	ActionSelectNext:      {"j", "down"},
+	ActionSelectPrev:      {"k", "up"},
+	ActionEnterDir:        {"l", "right"},
+	ActionParentDir:       {"h", "left"},
+	ActionCopy:            {"y y"},
+	ActionYank:            {"Y"},
+	ActionCopyTo:          {"y t"},
+	ActionMoveTo:          {"d t"},
+	ActionArchive:         {"g z"},
+	ActionExtract:         {"g x"},
+	ActionChecksums:       {"g s"},
+	ActionMove:            {"d d"},
+	ActionDelete:          {"D"},
+	ActionToss:            {"b"},
+	ActionBasket:          {"B"},
+	ActionSelectFirst:     {"g g"},
+	ActionSelectLast:      {"G"},
+	ActionInsert:          {"i"},
+	ActionRename:          {"r"},
+	ActionBulkRename:      {"R"},
+	ActionChmod:           {"c"},
+	ActionChown:           {"O"},
+	ActionEdit:            {"e"},
+	ActionOpen:            {"o"},
+	ActionOpenWith:        {"g o"},
+	ActionShell:           {"!"},
+	ActionSend:            {"&"},
+	ActionExport:          {"g e"},
+	ActionStaging:         {"g S"},
+	ActionSubshell:        {"s"},
+	ActionCompare:         {"="},
+	ActionTail:            {"w"},
+	ActionToggleHelp:      {"?"},
+	ActionTogglePreview:   {"\""},
+	ActionToggleExpand:    {"enter"},
+	ActionExpandAll:       {"E"},
+	ActionCollapseUnder:   {"-"},
+	ActionCollapseOthers:  {"_"},
+	ActionToggleMarkdown:  {"M"},
+	ActionToggleDetails:   {"L"},
+	ActionToggleDiff:      {"V"},
+	ActionPin:             {"P"},
+	ActionPinTransient:    {"ctrl+p"},
+	ActionToggleDualPane:  {"%"},
+	ActionGrowTree:        {">"},
+	ActionShrinkTree:      {"<"},
+	ActionCycleFocus:      {"tab"},
+	ActionTimeTravel:      {"T"},
+	ActionRestore:         {"U"},
+	ActionToggleChurn:     {"H"},
+	ActionDirSize:         {"S"},
+	ActionGrep:            {"F"},
+	ActionFilter:          {"f"},
+	ActionClearFilter:     {"x"},
+	ActionDirsOnly:        {"g D"},
+	ActionFilesOnly:       {"g F"},
+	ActionSearch:          {"/"},
+	ActionSearchNext:      {"n"},
+	ActionSearchPrev:      {"N"},
+	ActionToggleSelect:    {" "},
+	ActionCleanup:         {"C"},
+	ActionCleanArtifacts:  {"X"},
+	ActionToggleGroups:    {"W"},
+	ActionToggleNatural:   {"a"},
+	ActionToggleNumbers:   {"#"},
+	ActionToggleWrap:      {"u"},
+	ActionToggleColumns:   {"v"},
+	ActionToggleCase:      {"I"},
+	ActionFoldGroup:       {"z"},
+	ActionUnfoldGroups:    {"Z"},
+	ActionCancelJob:       {"ctrl+g"},
+	ActionScrollLeft:      {"{", "shift+left"},
+	ActionScrollRight:     {"}", "shift+right"},
+	ActionPreviewDown:     {"J"},
+	ActionPreviewUp:       {"K"},
+	ActionPreviewPageDown: {"ctrl+d", "pgdown"},
+	ActionPreviewPageUp:   {"ctrl+u", "pgup"},
+	ActionNewTab:          {"t"},
+	ActionCloseTab:        {"ctrl+w"},
+	ActionNextTab:         {"]"},
+	ActionPrevTab:         {"["},
+}
+
+// Returns action bound to key or ActionNone.
+func (k Keymap) Lookup(key string) ActionID {
+	for id, keys := range k {
+		if slices.Contains(keys, key) {
+			return id
+		}
+	}
+	return ActionNone
+}
+
+// Returns action bound to the key sequence or ActionNone. Reports true, if the sequence
+// starts a longer chord, so the next key is awaited instead.
+func (k Keymap) Match(keys []string) (ActionID, bool) {
+	seq := strings.Join(keys, " ")
+	match := ActionNone
+	for id, bindings := range k {
+		for _, b := range bindings {
+			if b == seq {
+				match = id
+			} else if strings.HasPrefix(b, seq+" ") {
+				return ActionNone, true
+			}
+		}
+	}
+	return match, false
+}
@@ -0,0 +1,80 @@
+package state
+
+import (
+	"fmt"
+	"path/filepath"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/LeperGnome/bt/internal/fileop"
+	"github.com/LeperGnome/bt/internal/i18n"
+	"github.com/LeperGnome/bt/pkg/paths"
+)
+
+// Asks for the name of the archive of selected paths, or the child under cursor.
+// The name is offered in the current directory: after the only path or after the directory itself.
+func (s *State) promptArchive() {
+	srcs := s.shellTargets()
+	if len(srcs) == 0 {
+		return
+	}
+	name := filepath.Base(s.Tree.CurrentDir.Path)
+	if len(srcs) == 1 {
+		name = filepath.Base(srcs[0])
+	}
+	s.OpBuf = ArchiveName
+	s.setInput(filepath.Join(s.Tree.CurrentDir.Path, name) + ".zip")
+}
+
+func (s *State) archive(typed string) tea.Cmd {
+	srcs := s.shellTargets()
+	if len(srcs) == 0 || s.jobBusy() {
+		return nil
+	}
+	if !s.localOnly() {
+		return nil
+	}
+	dst := s.typedPath(typed)
+	if err := paths.CheckName(filepath.Base(dst)); err != nil {
+		s.ErrBuf = err.Error()
+		return nil
+	}
+	if _, err := fileop.ArchiveFormat(dst); err != nil {
+		s.ErrBuf = err.Error()
+		return nil
+	}
+	for _, src := range srcs {
+		if paths.Within(src, dst) {
+			s.ErrBuf = fmt.Sprintf(i18n.T("ui.archive-into-self"), src)
+			return nil
+		}
+	}
+	return s.runJob(s.Tree.FS(), &FileJob{Kind: JobArchive, Src: srcs[0], Dst: dst, Paths: srcs}, false)
+}
+
+// Asks for the directory to extract the selected archive to, offering the current one.
+func (s *State) promptExtract() {
+	selected := s.Tree.GetSelectedChild()
+	if selected == nil || !selected.Info.Mode().IsRegular() {
+		s.ErrBuf = i18n.T("ui.select-archive")
+		return
+	}
+	if _, err := fileop.ArchiveFormat(selected.Path); err != nil {
+		s.ErrBuf = err.Error()
+		return
+	}
+	s.OpBuf = ExtractTo
+	s.setInput(s.Tree.CurrentDir.Path + string(filepath.Separator))
+}
+
+func (s *State) extract(typed string) tea.Cmd {
+	selected := s.Tree.GetSelectedChild()
+	if selected == nil || s.jobBusy() {
+		return nil
+	}
+	if !s.localOnly() {
+		return nil
+	}
+	dst := s.typedPath(typed)
+	return s.runJob(s.Tree.FS(), &FileJob{Kind: JobExtract, Src: selected.Path, Dst: dst, Paths: []string{selected.Path}}, false)
+}
@@ -0,0 +1,47 @@
+package state
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/LeperGnome/bt/internal/artifacts"
+	"github.com/LeperGnome/bt/internal/i18n"
+)
+
+// Looks for build artifacts of projects in current directory and asks for confirmation.
+func (s *State) findArtifacts() {
+	if !s.localOnly() {
+		return
+	}
+	found := artifacts.Find(s.Tree.CurrentDir.Path)
+	if len(found) == 0 {
+		s.ErrBuf = fmt.Sprintf(i18n.T("ui.no-artifacts"), s.Tree.CurrentDir.Path)
+		return
+	}
+	s.Artifacts = found
+	s.OpBuf = CleanArtifacts
+}
+
+func (s *State) processKeyCleanArtifacts(msg tea.KeyMsg) tea.Cmd {
+	pending := s.Artifacts
+	s.Artifacts = nil
+	s.OpBuf = Noop
+	if msg.String() != "y" {
+		return s.processKeyDefault(msg)
+	}
+	var errs []error
+	for _, p := range pending {
+		if err := os.RemoveAll(p); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		s.runHook(HookDelete, p)
+	}
+	if err := errors.Join(errs...); err != nil {
+		s.ErrBuf = err.Error()
+	}
+	return nil
+}
@@ -0,0 +1,119 @@
+package state
+
+import (
+	"errors"
+	"os"
+	"slices"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// Basket collects paths, tossed for deletion during triage.
+// Nothing is removed, until the whole basket is reviewed and confirmed.
+type Basket struct {
+	Paths  []string
+	Cursor int
+}
+
+func (b *Basket) Contains(path string) bool {
+	return slices.Contains(b.Paths, path)
+}
+
+func (b *Basket) toggle(path string) {
+	if i := slices.Index(b.Paths, path); i >= 0 {
+		b.remove(i)
+		return
+	}
+	b.Paths = append(b.Paths, path)
+}
+
+func (b *Basket) remove(i int) {
+	b.Paths = slices.Delete(b.Paths, i, i+1)
+	b.Cursor = max(min(b.Cursor, len(b.Paths)-1), 0)
+}
+
+// Tosses selected paths into the basket, or the selected child, if nothing is selected.
+// Paths, that are already in the basket, are taken back.
+// Basket holds local paths only, as it's emptied on the local file system.
+func (s *State) tossToBasket() {
+	if !s.localOnly() {
+		return
+	}
+	if len(s.Selection) > 0 {
+		for p := range s.Selection {
+			if !s.Basket.Contains(p) {
+				s.Basket.Paths = append(s.Basket.Paths, p)
+			}
+			s.unselect(p)
+		}
+		slices.Sort(s.Basket.Paths)
+		return
+	}
+	if child := s.Tree.GetSelectedChild(); child != nil {
+		s.Basket.toggle(child.Path)
+	}
+}
+
+func (s *State) openBasket() {
+	if len(s.Basket.Paths) > 0 {
+		s.OpBuf = BasketView
+	}
+}
+
+func (s *State) processKeyBasket(msg tea.KeyMsg) tea.Cmd {
+	b := &s.Basket
+	switch msg.String() {
+	case "esc", "q":
+		s.OpBuf = Noop
+	case "j", "down":
+		b.Cursor = min(b.Cursor+1, max(len(b.Paths)-1, 0))
+	case "k", "up":
+		b.Cursor = max(b.Cursor-1, 0)
+	case "u", " ":
+		if b.Cursor < len(b.Paths) {
+			b.remove(b.Cursor)
+		}
+		if len(b.Paths) == 0 {
+			s.OpBuf = Noop
+		}
+	case "D":
+		s.OpBuf = BasketConfirm
+	}
+	return nil
+}
+
+// Removes everything in the basket. Paths, that failed, stay there.
+func (s *State) processKeyBasketConfirm(msg tea.KeyMsg) tea.Cmd {
+	s.OpBuf = BasketView
+	if msg.String() != "y" {
+		return nil
+	}
+	for _, p := range s.Basket.Paths {
+		if s.isProtected(p) {
+			return s.guard(p, s.emptyBasket)
+		}
+	}
+	return s.emptyBasket()
+}
+
+// Deletes everything in the basket. Paths, that failed, are kept.
+func (s *State) emptyBasket() tea.Cmd {
+	var errs []error
+	left := []string{}
+	for _, p := range s.Basket.Paths {
+		if err := os.RemoveAll(p); err != nil {
+			errs = append(errs, err)
+			left = append(left, p)
+			continue
+		}
+		s.runHook(HookDelete, p)
+	}
+	s.Basket = Basket{Paths: left}
+	if len(left) == 0 {
+		s.OpBuf = Noop
+	}
+	if err := errors.Join(errs...); err != nil {
+		s.ErrBuf = err.Error()
+	}
+	return nil
+}
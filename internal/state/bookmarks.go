@@ -0,0 +1,119 @@
+package state
+
+import (
+	"path/filepath"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/LeperGnome/bt/internal/bookmarks"
+	"github.com/LeperGnome/bt/internal/config"
+)
+
+func loadBookmarks() (*bookmarks.Store, error) {
+	dir, err := config.DataDir()
+	if err != nil {
+		return bookmarks.Load("")
+	}
+	return bookmarks.Load(filepath.Join(dir, "bookmarks.json"))
+}
+
+func (s *State) processKeyBookmarkSet(msg tea.KeyMsg) tea.Cmd {
+	s.OpBuf = Noop
+	key, ok := bookmarkKey(msg)
+	if !ok {
+		return nil
+	}
+	if err := s.Bookmarks.SetDir(key, s.Tree.CurrentDir.Path); err != nil {
+		s.ErrBuf = err.Error()
+	}
+	return nil
+}
+
+func (s *State) processKeyBookmarkJump(msg tea.KeyMsg) tea.Cmd {
+	s.OpBuf = Noop
+	key, ok := bookmarkKey(msg)
+	if !ok {
+		return nil
+	}
+	if a, ok := s.Bookmarks.Anchor(key); ok {
+		if err := s.jumpToAnchor(a); err != nil {
+			s.ErrBuf = err.Error()
+		}
+		return nil
+	}
+	dir, ok := s.Bookmarks.Dir(key)
+	if !ok {
+		return nil
+	}
+	if err := s.jumpTo(dir); err != nil {
+		s.ErrBuf = err.Error()
+	}
+	return nil
+}
+
+func (s *State) processKeyAnchorSet(msg tea.KeyMsg) tea.Cmd {
+	s.OpBuf = Noop
+	key, ok := bookmarkKey(msg)
+	if !ok {
+		return nil
+	}
+	s.anchorKey = key
+	s.setInput("")
+	s.OpBuf = AnchorNote
+	return nil
+}
+
+func (s *State) processKeyAnchorNote(msg tea.KeyMsg) tea.Cmd {
+	switch msg.String() {
+	case "enter":
+		s.OpBuf = Noop
+		selected := s.Tree.GetSelectedChild()
+		if selected == nil {
+			return nil
+		}
+		a := bookmarks.Anchor{Path: selected.Path, Line: s.PreviewOffset + 1, Note: string(s.InputBuf)}
+		if err := s.Bookmarks.SetAnchor(s.anchorKey, a); err != nil {
+			s.ErrBuf = err.Error()
+		}
+		s.setInput("")
+	default:
+		return s.processKeyAnyInput(msg)
+	}
+	return nil
+}
+
+// Selects anchored file and scrolls preview to its line.
+func (s *State) jumpToAnchor(a bookmarks.Anchor) error {
+	return s.revealLine(a.Path, a.Line)
+}
+
+// Selects file and shows preview, scrolled to the 1-based line.
+func (s *State) revealLine(path string, line int) error {
+	if err := s.jumpTo(filepath.Dir(path)); err != nil {
+		return err
+	}
+	if err := s.Tree.Reveal(path); err != nil {
+		return err
+	}
+	s.PreviewToggle = true
+	s.previewPath = path
+	s.PreviewOffset = 0
+	s.scrollPreview(line - 1)
+	return nil
+}
+
+// Reveals path in the tree, making it a new root if path is outside of the current one.
+func (s *State) jumpTo(path string) error {
+	if s.Tree.Contains(path) {
+		return s.Tree.Reveal(path)
+	}
+	return s.Tree.SetRoot(path)
+}
+
+// Bookmarks are keyed by a single printable character.
+func bookmarkKey(msg tea.KeyMsg) (string, bool) {
+	if msg.Type != tea.KeyRunes || len(msg.Runes) != 1 {
+		return "", false
+	}
+	return string(msg.Runes), true
+}
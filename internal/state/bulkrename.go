@@ -0,0 +1,152 @@
+package state
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/LeperGnome/bt/internal/fileop"
+	t "github.com/LeperGnome/bt/internal/tree"
+)
+
+// Renaming of current directory entries at once.
+type BulkRenameSession struct {
+	Paths   []string
+	Renames []t.Rename // planned, shown as preview
+	Err     error      // invalid pattern or conflicts, renames are not applied
+}
+
+// Sent, when editor with the list of names exits.
+type BulkRenameEdited struct {
+	File string
+	Err  error
+}
+
+// Renames are applied on the local file system only, see tree.ApplyRenames.
+func (s *State) startBulkRename() {
+	if !s.localOnly() {
+		return
+	}
+	paths := []string{}
+	for _, ch := range s.Tree.CurrentDir.Children {
+		paths = append(paths, ch.Path)
+	}
+	if len(paths) == 0 {
+		return
+	}
+	s.BulkRename = &BulkRenameSession{Paths: paths}
+	s.setInput("")
+	s.OpBuf = BulkRename
+}
+
+func (s *State) closeBulkRename() {
+	s.BulkRename = nil
+	s.setInput("")
+	s.OpBuf = Noop
+}
+
+func (s *State) processKeyBulkRename(msg tea.KeyMsg) tea.Cmd {
+	switch msg.String() {
+	case "esc", "ctrl+c":
+		s.closeBulkRename()
+	case "enter":
+		s.applyBulkRename()
+	case "ctrl+e":
+		return s.editBulkRename()
+	default:
+		s.processKeyAnyInput(msg)
+		s.planBulkRename()
+	}
+	return nil
+}
+
+func (s *State) processKeyBulkRenameConfirm(msg tea.KeyMsg) tea.Cmd {
+	if msg.String() == "y" {
+		s.applyBulkRename()
+		return nil
+	}
+	s.closeBulkRename()
+	return nil
+}
+
+// Plans renames from input in "find/replace" form, find being a regular expression.
+func (s *State) planBulkRename() {
+	b := s.BulkRename
+	b.Renames, b.Err = nil, nil
+	find, replace, _ := strings.Cut(string(s.InputBuf), "/")
+	if find == "" {
+		return
+	}
+	re, err := regexp.Compile(find)
+	if err != nil {
+		b.Err = err
+		return
+	}
+	b.Renames = t.PlanRegexRename(b.Paths, re, replace)
+	b.Err = t.CheckRenames(b.Renames)
+}
+
+func (s *State) applyBulkRename() {
+	b := s.BulkRename
+	if b.Err != nil || len(b.Renames) == 0 {
+		return
+	}
+	if err := t.ApplyRenames(b.Renames); err != nil {
+		b.Err = err
+		return
+	}
+	c := fileop.Command{Kind: fileop.OpRename}
+	for _, r := range b.Renames {
+		c.Paths, c.Targets = append(c.Paths, r.Old), append(c.Targets, r.New)
+	}
+	s.record(t.OS, c)
+	s.closeBulkRename()
+}
+
+// Opens names in $EDITOR, one per line. Renames are planned after editor exits.
+func (s *State) editBulkRename() tea.Cmd {
+	f, err := os.CreateTemp("", "bt-rename-*.txt")
+	if err != nil {
+		s.BulkRename.Err = err
+		return nil
+	}
+	for _, p := range s.BulkRename.Paths {
+		fmt.Fprintln(f, filepath.Base(p))
+	}
+	if err := f.Close(); err != nil {
+		s.BulkRename.Err = err
+		return nil
+	}
+	return tea.ExecProcess(editorCommand(f.Name()), func(err error) tea.Msg {
+		return BulkRenameEdited{File: f.Name(), Err: err}
+	})
+}
+
+func (s *State) processBulkRenameEdited(msg BulkRenameEdited) tea.Cmd {
+	defer os.Remove(msg.File)
+	b := s.BulkRename
+	if b == nil {
+		return nil
+	}
+	if msg.Err != nil {
+		b.Err = msg.Err
+		return nil
+	}
+	data, err := os.ReadFile(msg.File)
+	if err != nil {
+		b.Err = err
+		return nil
+	}
+	names := strings.Split(strings.TrimSuffix(string(data), "\n"), "\n")
+	b.Renames, b.Err = t.PlanListRename(b.Paths, names)
+	if b.Err == nil {
+		b.Err = t.CheckRenames(b.Renames)
+	}
+	s.setInput("")
+	s.OpBuf = BulkRenameConfirm
+	return nil
+}
@@ -0,0 +1,122 @@
+package state
+
+import (
+	"context"
+	"crypto/md5"
+	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/LeperGnome/bt/internal/i18n"
+	t "github.com/LeperGnome/bt/internal/tree"
+)
+
+// Checksums of a file, computed in background.
+type Checksums struct {
+	Path      string
+	MD5       string
+	SHA1      string
+	SHA256    string
+	Computing bool
+	Err       error
+	id        int
+	cancel    func()
+}
+
+// Sent, when checksums are computed or computing failed.
+type ChecksumsDone struct {
+	id     int
+	MD5    string
+	SHA1   string
+	SHA256 string
+	Err    error
+}
+
+// Starts computing checksums of the selected file. All of them are computed in one read.
+func (s *State) computeChecksums() tea.Cmd {
+	selected := s.Tree.GetSelectedChild()
+	if selected == nil || !selected.Info.Mode().IsRegular() {
+		s.ErrBuf = i18n.T("ui.select-checksum")
+		return nil
+	}
+	s.closeChecksums()
+	ctx, cancel := context.WithCancel(context.Background())
+	s.checksumID++
+	s.Checksums = &Checksums{Path: selected.Path, Computing: true, id: s.checksumID, cancel: cancel}
+	fsys, path, id := s.Tree.FS(), selected.Path, s.checksumID
+	return func() tea.Msg {
+		msg := ChecksumsDone{id: id}
+		msg.MD5, msg.SHA1, msg.SHA256, msg.Err = checksums(ctx, fsys, path)
+		return msg
+	}
+}
+
+func checksums(ctx context.Context, fsys t.FS, path string) (string, string, string, error) {
+	f, err := fsys.Open(path)
+	if err != nil {
+		return "", "", "", err
+	}
+	defer f.Close()
+	m, s1, s256 := md5.New(), sha1.New(), sha256.New()
+	if _, err := io.Copy(io.MultiWriter(m, s1, s256), &ctxReader{ctx: ctx, r: f}); err != nil {
+		return "", "", "", err
+	}
+	return hex.EncodeToString(m.Sum(nil)), hex.EncodeToString(s1.Sum(nil)), hex.EncodeToString(s256.Sum(nil)), nil
+}
+
+// Stops reading, once the context is cancelled.
+type ctxReader struct {
+	ctx context.Context
+	r   io.Reader
+}
+
+func (c *ctxReader) Read(p []byte) (int, error) {
+	if err := c.ctx.Err(); err != nil {
+		return 0, err
+	}
+	return c.r.Read(p)
+}
+
+func (s *State) processChecksumsDone(msg ChecksumsDone) tea.Cmd {
+	c := s.Checksums
+	if c == nil || c.id != msg.id {
+		return nil
+	}
+	c.Computing = false
+	c.MD5, c.SHA1, c.SHA256, c.Err = msg.MD5, msg.SHA1, msg.SHA256, msg.Err
+	if msg.Err != nil && !errors.Is(msg.Err, context.Canceled) {
+		s.ErrBuf = msg.Err.Error()
+	}
+	return nil
+}
+
+// Returns checksums of the selected file, if they were computed for it.
+func (s *State) SelectedChecksums() *Checksums {
+	selected := s.Tree.GetSelectedChild()
+	if s.Checksums == nil || selected == nil || selected.Path != s.Checksums.Path {
+		return nil
+	}
+	return s.Checksums
+}
+
+// Copies one of checksums of the selected file to clipboard.
+func (s *State) copyChecksum(sum func(c *Checksums) string) tea.Cmd {
+	c := s.SelectedChecksums()
+	if c == nil || c.Computing || c.Err != nil {
+		s.ErrBuf = i18n.T("ui.no-checksums")
+		return nil
+	}
+	return s.copyText(sum(c))
+}
+
+// Forgets checksums, stopping computation, if it's still running.
+func (s *State) closeChecksums() {
+	if s.Checksums != nil {
+		s.Checksums.cancel()
+	}
+	s.Checksums = nil
+}
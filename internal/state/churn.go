@@ -0,0 +1,53 @@
+package state
+
+import (
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/LeperGnome/bt/internal/git"
+)
+
+// How far back git history is inspected for hot files.
+const churnSince = "6 months ago"
+
+type ChurnReport struct {
+	Dir   string
+	Files []git.FileChurn
+	Err   error
+}
+
+// Toggles hot files view for the selected directory (or the current one, if file is selected).
+func (s *State) toggleChurn() tea.Cmd {
+	if s.ChurnToggle {
+		s.ChurnToggle = false
+		s.Churn = nil
+		return nil
+	}
+	dir := s.Tree.CurrentDir.Path
+	if selected := s.Tree.GetSelectedChild(); selected != nil && selected.IsDir() {
+		dir = selected.Path
+	}
+	s.ChurnToggle = true
+	s.Churn = &ChurnReport{Dir: dir}
+	return func() tea.Msg {
+		files, err := git.Churn(dir, churnSince)
+		return ChurnReport{Dir: dir, Files: files, Err: err}
+	}
+}
+
+func (s *State) processChurnReport(msg ChurnReport) tea.Cmd {
+	// view was closed or reopened for another directory while computing
+	if !s.ChurnToggle || s.Churn == nil || s.Churn.Dir != msg.Dir {
+		return nil
+	}
+	if msg.Err != nil {
+		s.ErrBuf = msg.Err.Error()
+		s.ChurnToggle = false
+		s.Churn = nil
+		return nil
+	}
+	if msg.Files == nil {
+		msg.Files = []git.FileChurn{}
+	}
+	s.Churn = &msg
+	return nil
+}
@@ -0,0 +1,181 @@
+package state
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"os"
+	"slices"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/LeperGnome/bt/internal/cleanup"
+	t "github.com/LeperGnome/bt/internal/tree"
+)
+
+// Interactive review of deletion candidates.
+type CleanupSession struct {
+	Root     string
+	Report   *cleanup.Report // nil - still scanning
+	Cursor   int             // index in Candidates()
+	Selected map[string]bool
+	cancel   func()
+}
+
+type CleanupReport struct {
+	Root   string
+	Report *cleanup.Report
+	Err    error
+}
+
+// Returns candidates of all groups in display order.
+func (c *CleanupSession) Candidates() []cleanup.Candidate {
+	all := []cleanup.Candidate{}
+	if c.Report == nil {
+		return all
+	}
+	for _, g := range c.Report.Groups {
+		all = append(all, g.Candidates...)
+	}
+	return all
+}
+
+// Total size of selected candidates.
+func (c *CleanupSession) SelectedSize() int64 {
+	var total int64
+	for _, cand := range c.Candidates() {
+		if c.Selected[cand.Path] {
+			total += cand.Size
+		}
+	}
+	return total
+}
+
+// Starts scanning selected directory (or current one) for cleanup candidates.
+func (s *State) startCleanup() tea.Cmd {
+	if !s.localOnly() {
+		return nil
+	}
+	root := s.Tree.CurrentDir.Path
+	if selected := s.Tree.GetSelectedChild(); selected != nil && selected.IsDir() {
+		root = selected.Path
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	s.Cleanup = &CleanupSession{Root: root, Selected: map[string]bool{}, cancel: cancel}
+	s.OpBuf = Cleanup
+	return func() tea.Msg {
+		report, err := cleanup.Scan(ctx, root)
+		return CleanupReport{Root: root, Report: report, Err: err}
+	}
+}
+
+func (s *State) closeCleanup() {
+	if s.Cleanup != nil {
+		s.Cleanup.cancel()
+	}
+	s.Cleanup = nil
+	s.OpBuf = Noop
+}
+
+func (s *State) processCleanupReport(msg CleanupReport) tea.Cmd {
+	if s.Cleanup == nil || s.Cleanup.Root != msg.Root {
+		return nil
+	}
+	if msg.Err != nil {
+		if !errors.Is(msg.Err, context.Canceled) {
+			s.ErrBuf = msg.Err.Error()
+		}
+		s.closeCleanup()
+		return nil
+	}
+	s.Cleanup.Report = msg.Report
+	return nil
+}
+
+func (s *State) processKeyCleanup(msg tea.KeyMsg) tea.Cmd {
+	c := s.Cleanup
+	cands := c.Candidates()
+	switch msg.String() {
+	case "esc", "q":
+		s.closeCleanup()
+	case "j", "down":
+		c.Cursor = min(c.Cursor+1, max(len(cands)-1, 0))
+	case "k", "up":
+		c.Cursor = max(c.Cursor-1, 0)
+	case " ":
+		if c.Cursor < len(cands) {
+			p := cands[c.Cursor].Path
+			c.Selected[p] = !c.Selected[p]
+		}
+	case "a":
+		// toggling the whole group under cursor
+		if c.Report == nil {
+			return nil
+		}
+		start := 0
+		for _, g := range c.Report.Groups {
+			if c.Cursor < start+len(g.Candidates) {
+				allSelected := !slices.ContainsFunc(g.Candidates, func(cand cleanup.Candidate) bool { return !c.Selected[cand.Path] })
+				for _, cand := range g.Candidates {
+					c.Selected[cand.Path] = !allSelected
+				}
+				break
+			}
+			start += len(g.Candidates)
+		}
+	case "D":
+		if c.hasSelection() {
+			s.OpBuf = CleanupConfirm
+		}
+	}
+	return nil
+}
+
+func (s *State) processKeyCleanupConfirm(msg tea.KeyMsg) tea.Cmd {
+	s.OpBuf = Cleanup
+	if msg.String() != "y" {
+		return nil
+	}
+	paths := []string{}
+	for p, selected := range s.Cleanup.Selected {
+		if selected {
+			paths = append(paths, p)
+		}
+	}
+	if len(paths) == 0 || s.jobBusy() {
+		return nil
+	}
+	slices.Sort(paths)
+	return s.startDelete(t.OS, paths)
+}
+
+// Drops candidates, that don't exist anymore, after a deletion job.
+func (c *CleanupSession) dropDeleted() {
+	if c.Report == nil {
+		return
+	}
+	gone := func(p string) bool {
+		_, err := os.Lstat(p)
+		return errors.Is(err, fs.ErrNotExist)
+	}
+	for p := range c.Selected {
+		if gone(p) {
+			delete(c.Selected, p)
+		}
+	}
+	for i := range c.Report.Groups {
+		g := &c.Report.Groups[i]
+		g.Candidates = slices.DeleteFunc(g.Candidates, func(cand cleanup.Candidate) bool { return gone(cand.Path) })
+	}
+	c.Report.Groups = slices.DeleteFunc(c.Report.Groups, func(g cleanup.Group) bool { return len(g.Candidates) == 0 })
+	c.Cursor = min(c.Cursor, max(len(c.Candidates())-1, 0))
+}
+
+func (c *CleanupSession) hasSelection() bool {
+	for _, selected := range c.Selected {
+		if selected {
+			return true
+		}
+	}
+	return false
+}
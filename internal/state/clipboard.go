@@ -0,0 +1,77 @@
+package state
+
+import (
+	"fmt"
+	"io"
+	"path/filepath"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/LeperGnome/bt/internal/i18n"
+)
+
+// Files larger than that are not copied to clipboard.
+const ClipboardContentLimit = 1 << 20
+
+func (s *State) processKeyYank(msg tea.KeyMsg) tea.Cmd {
+	s.OpBuf = Noop
+	selected := s.Tree.GetSelectedChild()
+	if selected == nil {
+		return nil
+	}
+	switch msg.String() {
+	case "p":
+		abs, err := filepath.Abs(selected.Path)
+		if err != nil {
+			s.ErrBuf = err.Error()
+			return nil
+		}
+		return s.copyText(abs)
+	case "r":
+		rel, err := filepath.Rel(s.Tree.Root.Path, selected.Path)
+		if err != nil {
+			s.ErrBuf = err.Error()
+			return nil
+		}
+		return s.copyText(rel)
+	case "c":
+		if !selected.Info.Mode().IsRegular() {
+			return nil
+		}
+		return s.copyContent(selected.Path)
+	case "m":
+		return s.copyChecksum(func(c *Checksums) string { return c.MD5 })
+	case "h":
+		return s.copyChecksum(func(c *Checksums) string { return c.SHA1 })
+	case "s":
+		return s.copyChecksum(func(c *Checksums) string { return c.SHA256 })
+	default:
+		return s.processKeyDefault(msg)
+	}
+}
+
+func (s *State) copyText(text string) tea.Cmd {
+	cb := s.Clipboard
+	return func() tea.Msg {
+		return ExternalCommandFinished{Err: cb.Copy(text)}
+	}
+}
+
+func (s *State) copyContent(path string) tea.Cmd {
+	cb, fsys := s.Clipboard, s.Tree.FS()
+	return func() tea.Msg {
+		f, err := fsys.Open(path)
+		if err != nil {
+			return ExternalCommandFinished{Err: err}
+		}
+		defer f.Close()
+		data, err := io.ReadAll(io.LimitReader(f, ClipboardContentLimit+1))
+		if err != nil {
+			return ExternalCommandFinished{Err: err}
+		}
+		if len(data) > ClipboardContentLimit {
+			return ExternalCommandFinished{Err: fmt.Errorf(i18n.T("ui.clipboard-too-large"), filepath.Base(path))}
+		}
+		return ExternalCommandFinished{Err: cb.Copy(string(data))}
+	}
+}
@@ -0,0 +1,93 @@
+package state
+
+import (
+	"slices"
+
+	"github.com/mattn/go-runewidth"
+
+	t "github.com/LeperGnome/bt/internal/tree"
+)
+
+const (
+	ColumnsMinWidth = 100 // narrower terminals keep the tree, even if columns are on
+	ColumnGap       = 3   // cells before each name, the cursor is drawn there
+)
+
+// Reports whether the current directory is listed in columns, like ls -C, instead of the tree.
+// Columns take the whole width, so they're shown only when no pane is open on the right.
+func (s *State) ColumnView() bool {
+	return s.Columns && s.windowWidth >= ColumnsMinWidth && !s.sidePane()
+}
+
+// Reports whether a pane is shown next to the tree.
+func (s *State) sidePane() bool {
+	switch s.OpBuf {
+	case BookmarkJump, RecentPick, SnapshotPick, BasketView, BasketConfirm, MessageLog, DrivePick, JournalView, RegisterPick, RegisterUse, StagingView:
+		return true
+	}
+	return s.Deletion != nil || s.Shell != nil || s.Compare != nil || s.Tail != nil ||
+		s.Grep != nil || s.BulkRename != nil || s.Cleanup != nil ||
+		s.ChurnToggle || s.DualPane || s.PreviewToggle
+}
+
+// Returns visible entries of the current directory, in the order of the tree.
+func (s *State) Entries() []*t.Node {
+	entries := []*t.Node{}
+	for _, ch := range s.Tree.CurrentDir.Children {
+		if s.Tree.Visible(ch) {
+			entries = append(entries, ch)
+		}
+	}
+	return entries
+}
+
+// Returns layout of entries in columns, filled top to bottom: number of rows and width of a column,
+// gap included. As many columns are used as fit in the window, rows aren't limited by its height.
+func (s *State) ColumnGrid(entries []*t.Node) (rows, cellWidth int) {
+	width := max(s.windowWidth-2, 1) // pane borders
+	nameWidth := 0
+	for _, e := range entries {
+		nameWidth = max(nameWidth, runewidth.StringWidth(e.Info.Name()))
+	}
+	cellWidth = min(nameWidth+ColumnGap, width)
+	cols := max(width/cellWidth, 1)
+	rows = (len(entries) + cols - 1) / cols
+	return rows, cellWidth
+}
+
+// Handles actions, that move across columns, when entries are listed in columns.
+// Returns false if action should be handled as usual.
+func (s *State) processColumnAction(action ActionID) bool {
+	switch action {
+	case ActionParentDir, ActionEnterDir:
+	case ActionToggleExpand:
+		// expanding has nothing to show in columns
+		if selected := s.Tree.GetSelectedChild(); selected != nil && selected.IsDir() {
+			if err := s.Tree.SetSelectedChildAsCurrent(); err != nil {
+				s.ErrBuf = err.Error()
+			}
+			return true
+		}
+		return false
+	default:
+		return false
+	}
+	entries := s.Entries()
+	idx := slices.Index(entries, s.Tree.GetSelectedChild())
+	if idx < 0 {
+		return false
+	}
+	rows, _ := s.ColumnGrid(entries)
+	switch {
+	case action == ActionEnterDir:
+		if idx/rows < (len(entries)-1)/rows {
+			// the last column may be shorter
+			s.Tree.Select(entries[min(idx+rows, len(entries)-1)])
+		}
+	case idx >= rows:
+		s.Tree.Select(entries[idx-rows])
+	default:
+		s.Tree.SetParentAsCurrent()
+	}
+	return true
+}
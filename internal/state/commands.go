@@ -0,0 +1,86 @@
+package state
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// Sent, when external command, started by bt, finishes.
+type ExternalCommandFinished struct {
+	Err error
+}
+
+func (s *State) processExternalCommandFinished(msg ExternalCommandFinished) tea.Cmd {
+	if msg.Err != nil {
+		s.ErrBuf = msg.Err.Error()
+	}
+	return nil
+}
+
+// Runs interactive command, suspending the TUI until it exits.
+func execInteractive(c *exec.Cmd) tea.Cmd {
+	return tea.ExecProcess(c, func(err error) tea.Msg {
+		return ExternalCommandFinished{Err: err}
+	})
+}
+
+// Suspends the TUI and runs $SHELL in dir, until user exits it. Exit status of the shell
+// is the one of its last command, so it's not reported.
+func subshell(dir string) tea.Cmd {
+	shell := os.Getenv("SHELL")
+	if shell == "" {
+		shell = "sh"
+	}
+	c := exec.Command(shell)
+	c.Dir = dir
+	return tea.ExecProcess(c, func(err error) tea.Msg {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			err = nil
+		}
+		return ExternalCommandFinished{Err: err}
+	})
+}
+
+// Starts command in background, without waiting for it to exit.
+func startDetached(c *exec.Cmd) tea.Cmd {
+	return func() tea.Msg {
+		if err := c.Start(); err != nil {
+			return ExternalCommandFinished{Err: err}
+		}
+		go c.Wait()
+		return nil
+	}
+}
+
+func openEditor(path string) tea.Cmd {
+	return execInteractive(editorCommand(path))
+}
+
+// Returns $EDITOR command for path, vim by default.
+func editorCommand(path string) *exec.Cmd {
+	editor := strings.Fields(os.Getenv("EDITOR"))
+	if len(editor) == 0 {
+		editor = []string{"vim"}
+	}
+	return exec.Command(editor[0], append(editor[1:], path)...)
+}
+
+// Opens path with the default application of the OS.
+func openSystem(path string) tea.Cmd {
+	var c *exec.Cmd
+	switch runtime.GOOS {
+	case "darwin":
+		c = exec.Command("open", path)
+	case "windows":
+		c = exec.Command("cmd", "/c", "start", "", path)
+	default:
+		c = exec.Command("xdg-open", path)
+	}
+	return startDetached(c)
+}
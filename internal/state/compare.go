@@ -0,0 +1,69 @@
+package state
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/LeperGnome/bt/internal/i18n"
+	t "github.com/LeperGnome/bt/internal/tree"
+	"github.com/LeperGnome/bt/pkg/diff"
+)
+
+// Larger files are not compared.
+const CompareBytesLimit = 4 << 20
+
+// Diff of the marked file against the selected one.
+type Comparison struct {
+	A, B string // marked and selected paths
+	Diff string // unified, empty - files are equal
+}
+
+// Shows diff of the marked file and the selected one, or closes it, if shown.
+func (s *State) toggleCompare() {
+	if s.Compare != nil {
+		s.Compare = nil
+		return
+	}
+	src, marked := s.marked()
+	selected := s.Tree.GetSelectedChild()
+	if marked == nil || selected == nil {
+		s.ErrBuf = i18n.T("ui.compare-hint")
+		return
+	}
+	a, err := readCompared(src.FS(), marked)
+	if err != nil {
+		s.ErrBuf = err.Error()
+		return
+	}
+	b, err := readCompared(s.Tree.FS(), selected)
+	if err != nil {
+		s.ErrBuf = err.Error()
+		return
+	}
+	s.Compare = &Comparison{A: marked.Path, B: selected.Path}
+	s.PreviewOffset = 0
+	if bytes.IndexByte(a, 0) >= 0 || bytes.IndexByte(b, 0) >= 0 {
+		if !bytes.Equal(a, b) {
+			s.Compare.Diff = fmt.Sprintf("binary files %s and %s differ\n", marked.Path, selected.Path)
+		}
+		return
+	}
+	s.Compare.Diff = diff.Unified(marked.Path, selected.Path, diff.Split(string(a)), diff.Split(string(b)), 3)
+}
+
+func readCompared(fsys t.FS, n *t.Node) ([]byte, error) {
+	if !n.Info.Mode().IsRegular() {
+		return nil, fmt.Errorf(i18n.T("ui.not-regular-file"), n.Path)
+	}
+	if n.Info.Size() > CompareBytesLimit {
+		return nil, errors.New(n.Path + " is too large to compare")
+	}
+	f, err := fsys.Open(n.Path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return io.ReadAll(io.LimitReader(f, CompareBytesLimit))
+}
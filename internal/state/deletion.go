@@ -0,0 +1,123 @@
+package state
+
+import (
+	"context"
+	"path/filepath"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/LeperGnome/bt/internal/fileop"
+	t "github.com/LeperGnome/bt/internal/tree"
+)
+
+// Directory or several paths, waiting for the confirmation of removal.
+type DeletePlan struct {
+	Paths    []string // removed paths, sorted
+	Entries  []string // of the removed directory, when it's the only path; directories end with /
+	Files    int64    // files to be removed, counted in background
+	Bytes    int64
+	Counting bool
+	Err      error // counting failed, totals are partial
+	id       int
+	cancel   func()
+}
+
+// Totals of a DeletePlan, counted in background.
+type DeleteCounted struct {
+	id       int
+	Progress fileop.Progress
+	Err      error
+}
+
+// Removes selected paths, or the child under cursor, if nothing is selected. Directories and
+// selections are listed with their totals first, single files are only named in the heading.
+func (s *State) deleteSelected() tea.Cmd {
+	if len(s.Selection) == 0 {
+		child := s.Tree.GetSelectedChild()
+		if child == nil || !s.Tree.MarkSelectedChild() {
+			return nil
+		}
+		if !child.IsDir() {
+			s.OpBuf = Delete
+			return nil
+		}
+	}
+	paths := s.shellTargets()
+	plan := &DeletePlan{Paths: paths}
+	if len(paths) == 1 {
+		entries, err := s.Tree.FS().ReadDir(paths[0])
+		if err == nil {
+			for _, e := range entries {
+				name := e.Name()
+				if e.IsDir() {
+					name += string(filepath.Separator)
+				}
+				plan.Entries = append(plan.Entries, name)
+			}
+		}
+	}
+	s.Deletion = plan
+	s.OpBuf = DeleteConfirm
+	if s.Tree.FS() != t.OS {
+		return nil // counted by walking the local file system only
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	s.deleteID++
+	plan.id, plan.cancel, plan.Counting = s.deleteID, cancel, true
+	id := s.deleteID
+	return func() tea.Msg {
+		p, err := fileop.Count(ctx, paths)
+		return DeleteCounted{id: id, Progress: p, Err: err}
+	}
+}
+
+func (s *State) processDeleteCounted(msg DeleteCounted) tea.Cmd {
+	plan := s.Deletion
+	if plan == nil || plan.id != msg.id {
+		return nil
+	}
+	plan.Counting = false
+	plan.Files, plan.Bytes = msg.Progress.TotalFiles, msg.Progress.TotalBytes
+	plan.Err = msg.Err
+	return nil
+}
+
+// Only y and n (or esc) are accepted, so a stray key can't remove a directory.
+func (s *State) processKeyDeleteConfirm(msg tea.KeyMsg) tea.Cmd {
+	switch msg.String() {
+	case "y":
+		paths := s.Deletion.Paths
+		s.closeDeletion()
+		s.OpBuf = Noop
+		if s.jobBusy() {
+			s.dropMarks()
+			return nil
+		}
+		remove := func() tea.Cmd {
+			s.dropMarks()
+			for _, p := range paths {
+				s.unselect(p)
+			}
+			return s.startDelete(s.Tree.FS(), paths)
+		}
+		for _, p := range paths {
+			if s.isProtected(p) {
+				return s.guard(p, remove)
+			}
+		}
+		return remove()
+	case "n", "esc", "ctrl+c":
+		s.closeDeletion()
+		s.OpBuf = Noop
+		s.dropMarks()
+	}
+	return nil
+}
+
+// Forgets the plan, stopping the count, if it's still running.
+func (s *State) closeDeletion() {
+	if s.Deletion != nil && s.Deletion.cancel != nil {
+		s.Deletion.cancel()
+	}
+	s.Deletion = nil
+}
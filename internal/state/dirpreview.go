@@ -0,0 +1,82 @@
+package state
+
+import (
+	"io/fs"
+	"slices"
+	"time"
+)
+
+// Max number of entries, listed in a directory preview. The rest is only counted.
+const DirPreviewLimit = 500
+
+// Contents of a directory, shown in preview, when a directory is selected.
+type DirListing struct {
+	Entries []DirEntry // directories first, then files, by name
+	Dirs    int
+	Files   int   // including symlinks and other non-directories
+	Size    int64 // of listed files, not recursive
+	More    int   // entries past the limit, counted, but not listed
+}
+
+type DirEntry struct {
+	Name string
+	Mode fs.FileMode
+	Size int64
+}
+
+type dirListingCache struct {
+	path    string
+	modTime time.Time
+	listing DirListing
+	err     error
+}
+
+// Returns listing of the selected directory, so it can be looked into without expanding.
+// Listing is cached, until the directory changes. Failed reads are reported to the message log.
+func (s *State) DirPreview() (DirListing, error) {
+	selected := s.Tree.GetSelectedChild()
+	if selected == nil || !selected.Info.IsDir() {
+		return DirListing{}, ErrNoPreview
+	}
+	c := &s.dirMem
+	if c.path == selected.Path && c.modTime.Equal(selected.Info.ModTime()) {
+		return c.listing, c.err
+	}
+	*c = dirListingCache{path: selected.Path, modTime: selected.Info.ModTime()}
+	entries, err := s.Tree.FS().ReadDir(selected.Path)
+	if err != nil {
+		c.err = err
+		s.report(err.Error())
+		return DirListing{}, err
+	}
+	l := &c.listing
+	for _, e := range entries {
+		if e.IsDir() {
+			l.Dirs++
+		} else {
+			l.Files++
+		}
+		if len(l.Entries) >= DirPreviewLimit {
+			l.More++
+			continue
+		}
+		entry := DirEntry{Name: e.Name(), Mode: e.Type()}
+		if info, err := e.Info(); err == nil {
+			entry.Mode, entry.Size = info.Mode(), info.Size()
+			if info.Mode().IsRegular() {
+				l.Size += info.Size()
+			}
+		}
+		l.Entries = append(l.Entries, entry)
+	}
+	slices.SortStableFunc(l.Entries, func(a, b DirEntry) int {
+		switch {
+		case a.Mode.IsDir() == b.Mode.IsDir():
+			return 0
+		case a.Mode.IsDir():
+			return -1
+		}
+		return 1
+	})
+	return c.listing, nil
+}
@@ -0,0 +1,49 @@
+package state
+
+import (
+	"path/filepath"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/LeperGnome/bt/internal/i18n"
+	"github.com/LeperGnome/bt/pkg/paths"
+)
+
+// Lists drives to switch to, with cursor on the drive of the tree root. Drives exist on Windows only.
+func (s *State) openDrives() {
+	if !s.localOnly() {
+		return
+	}
+	s.Drives = paths.Drives()
+	if len(s.Drives) == 0 {
+		s.ErrBuf = i18n.T("ui.no-drives")
+		return
+	}
+	s.DriveCursor = 0
+	if root, err := filepath.Abs(s.Tree.Root.Path); err == nil {
+		for i, d := range s.Drives {
+			if strings.EqualFold(filepath.VolumeName(d), filepath.VolumeName(root)) {
+				s.DriveCursor = i
+			}
+		}
+	}
+	s.OpBuf = DrivePick
+}
+
+func (s *State) processKeyDrivePick(msg tea.KeyMsg) tea.Cmd {
+	switch msg.String() {
+	case "j", "down":
+		s.DriveCursor = min(s.DriveCursor+1, len(s.Drives)-1)
+	case "k", "up":
+		s.DriveCursor = max(s.DriveCursor-1, 0)
+	case "enter":
+		s.OpBuf = Noop
+		if err := s.jumpTo(s.Drives[s.DriveCursor]); err != nil {
+			s.ErrBuf = err.Error()
+		}
+	default:
+		s.OpBuf = Noop
+	}
+	return nil
+}
@@ -0,0 +1,141 @@
+package state
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/mattn/go-runewidth"
+
+	"github.com/LeperGnome/bt/internal/i18n"
+	t "github.com/LeperGnome/bt/internal/tree"
+	"github.com/LeperGnome/bt/pkg/paths"
+)
+
+// Asks for the file to export the visible tree to, offering one in the root.
+func (s *State) promptExport() {
+	name := filepath.Base(s.Tree.Root.Path)
+	if name == "." || name == string(filepath.Separator) {
+		name = "tree"
+	}
+	s.OpBuf = ExportName
+	s.setInput(filepath.Join(s.Tree.Root.Path, name+"-tree.md"))
+}
+
+// Writes the tree, as it's shown: expanded directories, filter and entry type are respected.
+// Markdown file gets the tree in a code block, so it keeps its layout in issues and docs.
+func (s *State) exportTree(typed string) {
+	if !s.localOnly() {
+		return
+	}
+	dst := s.typedPath(typed)
+	if err := paths.CheckName(filepath.Base(dst)); err != nil {
+		s.ErrBuf = err.Error()
+		return
+	}
+	if _, err := os.Lstat(dst); err == nil {
+		s.ErrBuf = fmt.Sprintf(i18n.T("ui.path-exists"), dst)
+		return
+	}
+	var b bytes.Buffer
+	ext := strings.ToLower(filepath.Ext(dst))
+	markdown := ext == ".md" || ext == ".markdown"
+	if markdown {
+		fmt.Fprintf(&b, "# %s\n\n```text\n", filepath.Base(s.Tree.Root.Path))
+	}
+	dirs, files := s.writeTree(&b)
+	if markdown {
+		b.WriteString("```\n")
+	}
+	fmt.Fprintf(&b, "\n%d directories, %d files\n", dirs, files)
+	if err := os.WriteFile(dst, b.Bytes(), 0o644); err != nil {
+		s.ErrBuf = err.Error()
+		return
+	}
+	s.ErrBuf = fmt.Sprintf(i18n.T("ui.tree-exported"), dst)
+}
+
+// Line of the exported tree, size is aligned after the longest name.
+type exportLine struct {
+	name string
+	size string
+}
+
+// Writes visible nodes with tree-like indentation, like -print does. Returns counts of directories and files.
+func (s *State) writeTree(w io.Writer) (int, int) {
+	root := s.Tree.Root.Path
+	if !strings.HasSuffix(root, string(filepath.Separator)) {
+		root += string(filepath.Separator)
+	}
+	lines := []exportLine{{name: root, size: s.exportSize(s.Tree.Root)}}
+	dirs, files := s.exportChildren(&lines, s.Tree.Root, "")
+	width := 0
+	for _, l := range lines {
+		width = max(width, runewidth.StringWidth(l.name))
+	}
+	for _, l := range lines {
+		if l.size == "" {
+			fmt.Fprintln(w, l.name)
+			continue
+		}
+		fmt.Fprintf(w, "%s%s  %10s\n", l.name, strings.Repeat(" ", width-runewidth.StringWidth(l.name)), l.size)
+	}
+	return dirs, files
+}
+
+func (s *State) exportChildren(lines *[]exportLine, n *t.Node, prefix string) (int, int) {
+	children := make([]*t.Node, 0, len(n.Children))
+	for _, ch := range n.Children {
+		if s.Tree.Visible(ch) {
+			children = append(children, ch)
+		}
+	}
+	dirs, files := 0, 0
+	for i, ch := range children {
+		branch, indent := "├── ", "│   "
+		if i == len(children)-1 {
+			branch, indent = "└── ", "    "
+		}
+		name := ch.Info.Name()
+		if ch.IsDir() {
+			name += string(filepath.Separator)
+		}
+		*lines = append(*lines, exportLine{name: prefix + branch + name, size: s.exportSize(ch)})
+		if !ch.IsDir() {
+			files++
+			continue
+		}
+		dirs++
+		d, f := s.exportChildren(lines, ch, prefix+indent)
+		dirs, files = dirs+d, files+f
+	}
+	return dirs, files
+}
+
+// Size of a file, or of a directory, when it's computed. Empty otherwise.
+func (s *State) exportSize(n *t.Node) string {
+	if !n.IsDir() {
+		return formatBytes(n.Info.Size())
+	}
+	if size, ok := s.DirSizes[n.Path]; ok {
+		return formatBytes(size)
+	}
+	return ""
+}
+
+// Formats size in binary units, like the file info line does.
+func formatBytes(size int64) string {
+	units := []string{"B", "KiB", "MiB", "GiB", "TiB", "PiB", "EiB"}
+	v, i := float64(size), 0
+	for v >= 1024 && i < len(units)-1 {
+		v /= 1024
+		i++
+	}
+	if i > 1 {
+		return fmt.Sprintf("%.2f %s", v, units[i])
+	}
+	return fmt.Sprintf("%.0f %s", v, units[i])
+}
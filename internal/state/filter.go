@@ -0,0 +1,43 @@
+package state
+
+import (
+	tea "github.com/charmbracelet/bubbletea"
+
+	t "github.com/LeperGnome/bt/internal/tree"
+)
+
+// Starts filter input, prefilled with the active filter.
+func (s *State) startFilter() {
+	s.filterBefore = s.Tree.Filter()
+	s.setInput(s.filterBefore)
+	s.OpBuf = FilterInput
+}
+
+// Filter is applied while typing. Esc brings back the filter, that was active before.
+func (s *State) processKeyFilter(msg tea.KeyMsg) tea.Cmd {
+	switch msg.String() {
+	case "enter":
+		s.OpBuf = Noop
+		s.setInput("")
+		return nil
+	case "esc", "ctrl+c":
+		s.Tree.SetFilter(s.filterBefore)
+		s.OpBuf = Noop
+		s.setInput("")
+		return nil
+	}
+	s.processKeyAnyInput(msg)
+	// incomplete patterns, like "[a", are skipped until fixed
+	if err := s.Tree.SetFilter(string(s.InputBuf)); err == nil {
+		s.ErrBuf = ""
+	}
+	return nil
+}
+
+// Shows only entries of the type, or all of them again, if the type is already shown.
+func (s *State) toggleEntryType(et t.EntryType) {
+	if s.Tree.EntryType() == et {
+		et = t.AllEntries
+	}
+	s.Tree.SetEntryType(et)
+}
@@ -0,0 +1,81 @@
+package state
+
+// Pane is a part of the screen, that can receive key events.
+type Pane int
+
+const (
+	PaneFirstTree Pane = iota
+	PaneSecondTree
+	PanePreview
+)
+
+// Returns panes currently on screen in focus cycling order.
+func (s *State) VisiblePanes() []Pane {
+	panes := []Pane{PaneFirstTree}
+	if s.DualPane {
+		panes = append(panes, PaneSecondTree)
+	} else if s.PreviewToggle {
+		panes = append(panes, PanePreview)
+	}
+	return panes
+}
+
+func (s *State) cycleFocus() {
+	panes := s.VisiblePanes()
+	for i, p := range panes {
+		if p == s.Focus {
+			s.setFocus(panes[(i+1)%len(panes)])
+			return
+		}
+	}
+	s.setFocus(PaneFirstTree)
+}
+
+func (s *State) setFocus(p Pane) {
+	s.Focus = p
+	switch p {
+	case PaneFirstTree:
+		s.ActivePane = 0
+	case PaneSecondTree:
+		s.ActivePane = 1
+	}
+	s.Tree = s.Panes[s.ActivePane]
+}
+
+// Moves focus back to a tree, if focused pane was hidden.
+func (s *State) fixFocus() {
+	for _, p := range s.VisiblePanes() {
+		if p == s.Focus {
+			return
+		}
+	}
+	s.setFocus(PaneFirstTree)
+}
+
+// Handles actions, that have special meaning, when preview is focused.
+// Returns false if action should be handled as usual.
+func (s *State) processPreviewAction(action ActionID) bool {
+	switch action {
+	case ActionSelectNext:
+		s.scrollPreview(1)
+	case ActionSelectPrev:
+		s.scrollPreview(-1)
+	default:
+		return false
+	}
+	return true
+}
+
+// Resets preview scroll, when selected file changes.
+func (s *State) syncPreview() {
+	path := ""
+	if selected := s.Tree.GetSelectedChild(); selected != nil {
+		path = selected.Path
+	}
+	if path != s.previewPath {
+		s.previewPath = path
+		if s.PinnedPath == "" {
+			s.PreviewOffset = 0
+		}
+	}
+}
@@ -0,0 +1,154 @@
+package state
+
+import (
+	"context"
+	"errors"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/LeperGnome/bt/internal/search"
+)
+
+// Matches beyond that are dropped and search is stopped.
+const GrepMaxMatches = 10_000
+
+// Content search under a directory with results, streamed as they are found.
+type GrepSession struct {
+	Root    string
+	Pattern string
+	Matches []search.Match
+	Cursor  int
+	Done    bool
+	id      int
+	cancel  func()
+	matches <-chan search.Match
+	errc    <-chan error
+}
+
+// Batch of matches, read from the running search.
+type GrepResults struct {
+	id      int
+	Matches []search.Match
+	Done    bool
+	Err     error
+}
+
+func (s *State) processKeyGrepInput(msg tea.KeyMsg) tea.Cmd {
+	switch msg.String() {
+	case "enter":
+		pattern := string(s.InputBuf)
+		s.setInput("")
+		if pattern == "" {
+			s.OpBuf = Noop
+			return nil
+		}
+		return s.startGrep(pattern)
+	default:
+		return s.processKeyAnyInput(msg)
+	}
+}
+
+func (s *State) startGrep(pattern string) tea.Cmd {
+	re, err := search.Compile(pattern)
+	if err != nil {
+		s.ErrBuf = err.Error()
+		s.OpBuf = Noop
+		return nil
+	}
+	s.closeGrep()
+	ctx, cancel := context.WithCancel(context.Background())
+	s.grepID++
+	g := &GrepSession{Root: s.Tree.CurrentDir.Path, Pattern: pattern, id: s.grepID, cancel: cancel}
+	s.Grep = g
+	s.OpBuf = GrepResultsView
+
+	matches := make(chan search.Match, 256)
+	errc := make(chan error, 1)
+	g.matches, g.errc = matches, errc
+	go func() {
+		errc <- search.Grep(ctx, g.Root, re, matches)
+	}()
+	return g.read()
+}
+
+// Waits for the next batch of matches. Everything, that is ready, is taken at once,
+// so the UI is not redrawn for every match.
+func (g *GrepSession) read() tea.Cmd {
+	id, matches, errc := g.id, g.matches, g.errc
+	return func() tea.Msg {
+		batch := []search.Match{}
+		for len(batch) < 512 {
+			var (
+				m  search.Match
+				ok bool
+			)
+			if len(batch) == 0 {
+				m, ok = <-matches
+			} else {
+				select {
+				case m, ok = <-matches:
+				default:
+					return GrepResults{id: id, Matches: batch}
+				}
+			}
+			if !ok {
+				return GrepResults{id: id, Matches: batch, Done: true, Err: <-errc}
+			}
+			batch = append(batch, m)
+		}
+		return GrepResults{id: id, Matches: batch}
+	}
+}
+
+func (s *State) processGrepResults(msg GrepResults) tea.Cmd {
+	g := s.Grep
+	if g == nil || g.id != msg.id {
+		return nil
+	}
+	g.Matches = append(g.Matches, msg.Matches...)
+	if len(g.Matches) >= GrepMaxMatches {
+		g.Matches = g.Matches[:GrepMaxMatches]
+		g.cancel()
+		g.Done = true
+		return nil
+	}
+	if msg.Done {
+		g.Done = true
+		if msg.Err != nil && !errors.Is(msg.Err, context.Canceled) {
+			s.ErrBuf = msg.Err.Error()
+		}
+		return nil
+	}
+	return g.read()
+}
+
+func (s *State) closeGrep() {
+	if s.Grep != nil {
+		s.Grep.cancel()
+	}
+	s.Grep = nil
+}
+
+func (s *State) processKeyGrepResults(msg tea.KeyMsg) tea.Cmd {
+	g := s.Grep
+	switch msg.String() {
+	case "j", "down":
+		g.Cursor = min(g.Cursor+1, max(len(g.Matches)-1, 0))
+	case "k", "up":
+		g.Cursor = max(g.Cursor-1, 0)
+	case "enter":
+		if g.Cursor >= len(g.Matches) {
+			return nil
+		}
+		m := g.Matches[g.Cursor]
+		s.OpBuf = Noop
+		s.closeGrep()
+		if err := s.revealLine(m.Path, m.Line); err != nil {
+			s.ErrBuf = err.Error()
+		}
+	case "esc", "q":
+		s.OpBuf = Noop
+		s.closeGrep()
+	}
+	return nil
+}
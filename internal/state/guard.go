@@ -0,0 +1,87 @@
+package state
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/LeperGnome/bt/internal/i18n"
+	"github.com/LeperGnome/bt/pkg/paths"
+)
+
+// Reports whether removing or moving path would take away the tree root, home directory
+// or one of the protected paths. Paths, containing them, are protected as well.
+func (s *State) isProtected(path string) bool {
+	abs, err := filepath.Abs(path)
+	if err != nil || abs == string(filepath.Separator) {
+		return true
+	}
+	protected := append([]string{s.Tree.Root.Path}, s.Protected...)
+	if home, err := os.UserHomeDir(); err == nil {
+		protected = append(protected, home)
+	}
+	for _, p := range protected {
+		p, err := filepath.Abs(expandHome(p))
+		if err != nil {
+			continue
+		}
+		if paths.Within(abs, p) {
+			return true
+		}
+	}
+	return false
+}
+
+func expandHome(path string) string {
+	if path != "~" && !(strings.HasPrefix(path, "~") && os.IsPathSeparator(path[1])) {
+		return path
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return path
+	}
+	return filepath.Join(home, path[1:])
+}
+
+// Runs action right away, or, if path is protected, after its name is typed for confirmation.
+func (s *State) guard(path string, action func() tea.Cmd) tea.Cmd {
+	if !s.isProtected(path) {
+		return action()
+	}
+	s.guardPath = path
+	s.guarded = action
+	s.setInput("")
+	s.OpBuf = GuardConfirm
+	return nil
+}
+
+// Returns protected path, waiting for a typed confirmation.
+func (s *State) GuardPath() string {
+	return s.guardPath
+}
+
+func (s *State) processKeyGuardConfirm(msg tea.KeyMsg) tea.Cmd {
+	switch msg.String() {
+	case "enter":
+		action, name := s.guarded, filepath.Base(s.guardPath)
+		typed := string(s.InputBuf)
+		s.OpBuf = Noop
+		s.setInput("")
+		s.guardPath, s.guarded = "", nil
+		if typed != name {
+			s.ErrBuf = i18n.T("ui.name-mismatch")
+			return nil
+		}
+		return action()
+	case "ctrl+c", "esc":
+		s.guardPath, s.guarded = "", nil
+		s.OpBuf = Noop
+		s.setInput("")
+		s.dropMarks()
+	default:
+		return s.processKeyAnyInput(msg)
+	}
+	return nil
+}
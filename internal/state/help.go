@@ -0,0 +1,30 @@
+package state
+
+import tea "github.com/charmbracelet/bubbletea"
+
+// Handles keys, while help overlay is open. Only scrolling, closing and quitting work.
+func (s *State) processHelpAction(action ActionID) tea.Cmd {
+	switch action {
+	case ActionSelectNext, ActionPreviewDown:
+		s.scrollHelp(1)
+	case ActionSelectPrev, ActionPreviewUp:
+		s.scrollHelp(-1)
+	case ActionPreviewPageDown:
+		s.scrollHelp(s.previewPage())
+	case ActionPreviewPageUp:
+		s.scrollHelp(-s.previewPage())
+	case ActionSelectLast:
+		s.scrollHelp(len(Actions))
+	case ActionCancel, ActionToggleHelp:
+		s.HelpToggle = false
+		s.HelpOffset = 0
+	case ActionQuit:
+		return tea.Quit
+	}
+	return nil
+}
+
+// Scrolls help by delta lines, keeping at least one line visible.
+func (s *State) scrollHelp(delta int) {
+	s.HelpOffset = max(min(s.HelpOffset+delta, len(Actions)-1), 0)
+}
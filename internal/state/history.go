@@ -0,0 +1,80 @@
+package state
+
+import (
+	"slices"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// Max number of directories, kept in the recent list.
+const RecentLimit = 50
+
+// History keeps visited directories of a tab for back / forward navigation.
+type History struct {
+	Entries []string
+	Pos     int // index of the current directory
+}
+
+// Records current directory, when it changes. Forward history is dropped, like in a browser.
+func (s *State) syncHistory() {
+	cur := s.Tree.CurrentDir.Path
+	if s.History == nil {
+		s.History = &History{Entries: []string{cur}}
+	}
+	h := s.History
+	if h.Entries[h.Pos] == cur {
+		return
+	}
+	h.Entries = append(h.Entries[:h.Pos+1], cur)
+	h.Pos++
+	s.addRecent(cur)
+}
+
+func (s *State) addRecent(dir string) {
+	s.Recent = slices.DeleteFunc(s.Recent, func(d string) bool { return d == dir })
+	s.Recent = slices.Insert(s.Recent, 0, dir)
+	if len(s.Recent) > RecentLimit {
+		s.Recent = s.Recent[:RecentLimit]
+	}
+}
+
+// Goes delta steps back (negative) or forward in history.
+func (s *State) historyStep(delta int) {
+	h := s.History
+	if h == nil {
+		return
+	}
+	pos := h.Pos + delta
+	if pos < 0 || pos >= len(h.Entries) {
+		return
+	}
+	if err := s.jumpTo(h.Entries[pos]); err != nil {
+		s.ErrBuf = err.Error()
+		return
+	}
+	h.Pos = pos
+}
+
+func (s *State) openRecent() {
+	if len(s.Recent) > 0 {
+		s.RecentCursor = 0
+		s.OpBuf = RecentPick
+	}
+}
+
+func (s *State) processKeyRecentPick(msg tea.KeyMsg) tea.Cmd {
+	switch msg.String() {
+	case "j", "down":
+		s.RecentCursor = min(s.RecentCursor+1, len(s.Recent)-1)
+	case "k", "up":
+		s.RecentCursor = max(s.RecentCursor-1, 0)
+	case "enter":
+		s.OpBuf = Noop
+		if err := s.jumpTo(s.Recent[s.RecentCursor]); err != nil {
+			s.ErrBuf = err.Error()
+		}
+	default:
+		s.OpBuf = Noop
+	}
+	return nil
+}
@@ -0,0 +1,78 @@
+package state
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/LeperGnome/bt/internal/i18n"
+)
+
+// Hook events, passed to hooks in $BT_EVENT.
+const (
+	HookSelect   = "select"
+	HookOpen     = "open"
+	HookDelete   = "delete"
+	HookEnterDir = "enter-dir"
+)
+
+func (s *State) hookCommand(event string) string {
+	switch event {
+	case HookSelect:
+		return s.Hooks.OnSelect
+	case HookOpen:
+		return s.Hooks.OnOpen
+	case HookDelete:
+		return s.Hooks.OnDelete
+	case HookEnterDir:
+		return s.Hooks.OnEnterDir
+	}
+	return ""
+}
+
+// Starts hook of event for path in background. Output of hooks is dropped,
+// only failure to start one is reported.
+func (s *State) runHook(event, path string) {
+	command := s.hookCommand(event)
+	if command == "" || !s.Tree.Local() {
+		return // hooks get local paths only
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	if event == HookSelect {
+		// moving through entries shouldn't pile up processes, the previous one is stopped
+		if s.hookCancel != nil {
+			s.hookCancel()
+		}
+		s.hookCancel = cancel
+	}
+	c := s.shellCommand(ctx, command)
+	c.Env = append(os.Environ(), "BT_EVENT="+event, "BT_PATH="+path, "BT_ROOT="+s.Tree.Root.Path)
+	if err := c.Start(); err != nil {
+		cancel()
+		s.ErrBuf = fmt.Sprintf(i18n.T("ui.hook-failed"), event, err)
+		return
+	}
+	go func() {
+		c.Wait()
+		cancel()
+	}()
+}
+
+// Runs hooks for changes of the current directory and selected child since the last call.
+func (s *State) syncHooks() {
+	selected := ""
+	if child := s.Tree.GetSelectedChild(); child != nil {
+		selected = child.Path
+	}
+	dir := s.Tree.CurrentDir.Path
+	if dir != s.hookedDir {
+		s.hookedDir = dir
+		s.runHook(HookEnterDir, dir)
+	}
+	if selected != s.hookedPath {
+		s.hookedPath = selected
+		if selected != "" {
+			s.runHook(HookSelect, selected)
+		}
+	}
+}
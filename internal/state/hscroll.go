@@ -0,0 +1,24 @@
+package state
+
+import "github.com/mattn/go-runewidth"
+
+const (
+	TreeScrollStep  = 4
+	treeIndentWidth = 3 // display cells of one nesting level in the rendered tree
+)
+
+// Scrolls tree horizontally by delta cells, so long names and deep indents can be inspected.
+// Tree can't be scrolled past the end of the selected row.
+func (s *State) scrollTree(delta int) {
+	selected := s.Tree.GetSelectedChild()
+	if selected == nil {
+		s.TreeScroll = 0
+		return
+	}
+	depth := 0
+	for n := selected; n != s.Tree.Root && n.Parent != nil; n = n.Parent {
+		depth++
+	}
+	rowWidth := depth*treeIndentWidth + runewidth.StringWidth(selected.Info.Name())
+	s.TreeScroll = max(min(s.TreeScroll+delta, rowWidth-1), 0)
+}
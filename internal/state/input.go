@@ -0,0 +1,148 @@
+package state
+
+import (
+	"path/filepath"
+	"strings"
+	"unicode"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	t "github.com/LeperGnome/bt/internal/tree"
+)
+
+// Replaces input with text, cursor is put at the end.
+func (s *State) setInput(text string) {
+	s.InputBuf = []rune(text)
+	s.InputPos = len(s.InputBuf)
+	s.InputSelected = false
+}
+
+// Offers name of the node for editing. Cursor stops before the extension of a file,
+// so the name itself is edited first.
+func (s *State) setNameInput(n *t.Node) {
+	s.setInput(n.Info.Name())
+	if !n.IsDir() {
+		s.InputPos = len([]rune(stem(n.Info.Name())))
+	}
+}
+
+// Returns name without its extension. Leading dot doesn't start an extension, .tar stays with the next one.
+func stem(name string) string {
+	trimmed := strings.TrimLeft(name, ".")
+	ext := filepath.Ext(trimmed)
+	if ext == "" {
+		return name
+	}
+	base := strings.TrimSuffix(name, ext)
+	if strings.HasSuffix(base, ".tar") && base != ".tar" {
+		base = strings.TrimSuffix(base, ".tar")
+	}
+	return base
+}
+
+// Edits input like a shell line: cursor movement, deletion by character, word or up to the ends,
+// insertion at the cursor. Pasted text is inserted as one line. Typing over the whole input,
+// selected with alt+a, replaces it, any other key drops the selection.
+func (s *State) editInput(msg tea.KeyMsg) {
+	buf := s.InputBuf
+	pos := min(max(s.InputPos, 0), len(buf))
+	if s.InputSelected {
+		s.InputSelected = false
+		switch msg.String() {
+		case "backspace", "ctrl+h", "delete", "ctrl+d":
+			s.InputBuf, s.InputPos = nil, 0
+			return
+		case "alt+a":
+			return
+		}
+		if msg.Type == tea.KeyRunes || msg.Type == tea.KeySpace {
+			buf, pos = nil, 0
+		}
+	}
+	switch msg.String() {
+	case "alt+a":
+		s.InputSelected = len(buf) > 0
+		pos = len(buf)
+	case "left", "ctrl+b":
+		pos = max(pos-1, 0)
+	case "right", "ctrl+f":
+		pos = min(pos+1, len(buf))
+	case "alt+left", "alt+b", "ctrl+left":
+		pos = wordStart(buf, pos)
+	case "alt+right", "alt+f", "ctrl+right":
+		pos = wordEnd(buf, pos)
+	case "home", "ctrl+a":
+		pos = 0
+	case "end", "ctrl+e":
+		pos = len(buf)
+	case "backspace", "ctrl+h":
+		if pos > 0 {
+			buf = append(buf[:pos-1], buf[pos:]...)
+			pos--
+		}
+	case "delete", "ctrl+d":
+		if pos < len(buf) {
+			buf = append(buf[:pos], buf[pos+1:]...)
+		}
+	case "ctrl+w", "alt+backspace":
+		start := wordStart(buf, pos)
+		buf = append(buf[:start], buf[pos:]...)
+		pos = start
+	case "alt+d":
+		buf = append(buf[:pos], buf[wordEnd(buf, pos):]...)
+	case "ctrl+u":
+		buf = buf[pos:]
+		pos = 0
+	case "ctrl+k":
+		buf = buf[:pos]
+	default:
+		if msg.Type != tea.KeyRunes && msg.Type != tea.KeySpace {
+			return
+		}
+		runes := msg.Runes
+		if msg.Paste {
+			runes = []rune(strings.Map(pastedRune, string(runes)))
+		}
+		buf = append(buf[:pos], append(runes, buf[pos:]...)...)
+		pos += len(runes)
+	}
+	s.InputBuf, s.InputPos = buf, pos
+}
+
+// Newlines of pasted text become spaces, other control characters are dropped.
+func pastedRune(r rune) rune {
+	switch {
+	case r == '\n' || r == '\t':
+		return ' '
+	case r == '\r' || unicode.IsControl(r):
+		return -1
+	}
+	return r
+}
+
+// Words are letters and digits, so ctrl+w removes one element of a path or a file name.
+func isWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+// Returns position of the start of the word before pos.
+func wordStart(buf []rune, pos int) int {
+	for pos > 0 && !isWordRune(buf[pos-1]) {
+		pos--
+	}
+	for pos > 0 && isWordRune(buf[pos-1]) {
+		pos--
+	}
+	return pos
+}
+
+// Returns position of the end of the word after pos.
+func wordEnd(buf []rune, pos int) int {
+	for pos < len(buf) && !isWordRune(buf[pos]) {
+		pos++
+	}
+	for pos < len(buf) && isWordRune(buf[pos]) {
+		pos++
+	}
+	return pos
+}
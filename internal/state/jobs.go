@@ -0,0 +1,393 @@
+package state
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"path/filepath"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/LeperGnome/bt/internal/config"
+	"github.com/LeperGnome/bt/internal/fileop"
+	"github.com/LeperGnome/bt/internal/i18n"
+	"github.com/LeperGnome/bt/internal/throughput"
+	t "github.com/LeperGnome/bt/internal/tree"
+	"github.com/LeperGnome/bt/pkg/paths"
+)
+
+// Kinds of background file operations.
+const (
+	JobCopy    = "copy"
+	JobMove    = "move"
+	JobDelete  = "delete"
+	JobArchive = "archive" // Paths are packed into Dst
+	JobExtract = "extract" // Src archive is unpacked into Dst directory
+)
+
+// File operation, running in background, so large trees don't block the UI.
+type FileJob struct {
+	Kind     string
+	Src      string
+	Dst      string   // empty for delete
+	Paths    []string // copied, moved or deleted paths, Src is the first of them
+	Targets  []string // of Paths at the same index, Dst is the first of them, nil for delete
+	Progress fileop.Progress
+	FS       string        // destination filesystem, empty - no data transfer
+	Estimate time.Duration // expected duration by previous transfers, 0 - unknown
+	id       int
+	cancel   func()
+	updates  <-chan fileop.Progress
+	done     <-chan error
+	rate     float64 // historical rate to FS, bytes per second
+	fsys     t.FS
+	replace  bool           // existing Dst was removed first
+	replay   bool           // undo or redo, not recorded in the journal
+	stamps   []fileop.Stamp // deleted Paths must match them, copied Targets get them, see fileop.Command
+}
+
+// Jobs, expected to take longer, are warned about before data goes.
+const SlowJob = time.Minute
+
+// Returns expected time to finish the transfer.
+func (j *FileJob) ETA() (time.Duration, bool) {
+	p := j.Progress
+	if j.FS == "" || p.TotalBytes == 0 {
+		return 0, false
+	}
+	return throughput.Estimate(j.rate, p.Bytes, p.TotalBytes-p.Bytes, p.Elapsed)
+}
+
+// Progress update or completion of the running job.
+type FileJobProgress struct {
+	id       int
+	Progress fileop.Progress
+	Done     bool
+	Err      error
+}
+
+// Reports, that another operation is running. Only one operation runs at a time.
+func (s *State) jobBusy() bool {
+	if s.Job == nil {
+		return false
+	}
+	s.ErrBuf = fmt.Sprintf(i18n.T("ui.job-busy"), fmt.Sprintf(i18n.T("ui.job-"+s.Job.Kind), s.Job.Src))
+	return true
+}
+
+// Starts operation on src in fsys in background. With replace, existing dst is removed first.
+func (s *State) startJob(fsys t.FS, kind, src, dst string, replace bool) tea.Cmd {
+	return s.runJob(fsys, &FileJob{Kind: kind, Src: src, Dst: dst, Paths: []string{src}, Targets: []string{dst}}, replace)
+}
+
+// Starts copying or moving each of srcs to dsts at the same index in background.
+func (s *State) startTransfer(fsys t.FS, kind string, srcs, dsts []string) tea.Cmd {
+	return s.runJob(fsys, &FileJob{Kind: kind, Src: srcs[0], Dst: dsts[0], Paths: srcs, Targets: dsts}, false)
+}
+
+// Starts removal of paths in fsys in background.
+func (s *State) startDelete(fsys t.FS, paths []string) tea.Cmd {
+	return s.runJob(fsys, &FileJob{Kind: JobDelete, Src: paths[0], Paths: paths}, false)
+}
+
+func (s *State) runJob(fsys t.FS, job *FileJob, replace bool) tea.Cmd {
+	ctx, cancel := context.WithCancel(context.Background())
+	updates := make(chan fileop.Progress, 1)
+	done := make(chan error, 1)
+	s.jobID++
+	job.id, job.cancel, job.updates, job.done = s.jobID, cancel, updates, done
+	job.fsys, job.replace = fsys, replace
+	s.Job = job
+	kind, src, dst := job.Kind, job.Src, job.Dst
+	if (kind == JobCopy || kind == JobMove) && fsys == t.OS {
+		fs := throughput.Filesystem(filepath.Dir(dst))
+		// move within a filesystem is a rename, nothing is transferred
+		if kind == JobCopy || throughput.Filesystem(src) != fs {
+			s.Job.FS = fs
+			s.Job.rate, _ = s.Throughput.Rate(fs)
+		}
+	}
+
+	// only the latest update is kept, UI doesn't need every one of them
+	report := func(p fileop.Progress) {
+		select {
+		case <-updates:
+		default:
+		}
+		updates <- p
+	}
+	go func() {
+		if kind == JobDelete {
+			if err := checkStamps(fsys, job.Paths, job.stamps); err != nil {
+				done <- err
+				return
+			}
+		}
+		targets := job.Targets
+		if replace {
+			// existing target is removed only, when its replacement is complete
+			targets = []string{filepath.Join(filepath.Dir(dst), ".bt-replace-"+filepath.Base(dst))}
+		}
+		err := doJob(ctx, fsys, job, targets, report)
+		if replace {
+			err = finishReplace(fsys, kind, targets[0], dst, err)
+		}
+		if err == nil && kind == JobCopy {
+			job.stamps = stampAll(fsys, job.Targets)
+		}
+		done <- err
+	}()
+	return s.Job.read()
+}
+
+// Runs job, putting its Paths to targets.
+func doJob(ctx context.Context, fsys t.FS, job *FileJob, targets []string, report fileop.ReportFunc) error {
+	if fsys != t.OS {
+		return fsJob(fsys, job, targets)
+	}
+	switch job.Kind {
+	case JobCopy:
+		return fileop.CopyAll(ctx, job.Paths, targets, report)
+	case JobMove:
+		return fileop.MoveAll(ctx, job.Paths, targets, report)
+	case JobDelete:
+		return fileop.Delete(ctx, job.Paths, report)
+	case JobArchive:
+		return fileop.Archive(ctx, job.Paths, job.Dst, report)
+	case JobExtract:
+		return fileop.Extract(ctx, job.Src, job.Dst, report)
+	}
+	return nil
+}
+
+// Puts complete replacement in place of dst. Failed copy is removed and dst stays as is.
+// Failed move is kept, part of it may be gone from the source already.
+func finishReplace(fsys t.FS, kind, replacement, dst string, err error) error {
+	if err == nil {
+		err = fsys.RemoveAll(dst)
+	}
+	if err != nil {
+		if kind == JobCopy {
+			fsys.RemoveAll(replacement)
+		}
+		return err
+	}
+	return fsys.Rename(replacement, dst)
+}
+
+// Runs operation with what fsys supports itself, without progress. Copying is not supported.
+func fsJob(fsys t.FS, job *FileJob, targets []string) error {
+	switch job.Kind {
+	case JobMove:
+		for i, p := range job.Paths {
+			if err := fsys.Rename(p, targets[i]); err != nil {
+				return err
+			}
+		}
+		return nil
+	case JobDelete:
+		for _, p := range job.Paths {
+			if err := fsys.RemoveAll(p); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	return fmt.Errorf(i18n.T("ui.job-local-only"), fmt.Sprintf(i18n.T("ui.job-"+job.Kind), job.Src))
+}
+
+func loadThroughput() (*throughput.Store, error) {
+	dir, err := config.DataDir()
+	if err != nil {
+		return throughput.Load("")
+	}
+	return throughput.Load(filepath.Join(dir, "throughput.json"))
+}
+
+// Decisions on paste conflicts.
+const (
+	ConflictOverwrite = "o"
+	ConflictSkip      = "s"
+	ConflictRename    = "r"
+)
+
+// Paste, which target already exists.
+type pasteConflict struct {
+	kind string
+	tree *t.Tree // holds the mark
+	dst  string
+}
+
+// Copies or moves marked node to the paste target in background.
+// When the target exists, user decides what to do with it.
+func (s *State) pasteMarked(kind string) tea.Cmd {
+	src, marked := s.marked()
+	if src == nil || s.jobBusy() {
+		return nil
+	}
+	if kind == JobMove {
+		return s.guard(marked.Path, func() tea.Cmd { return s.pasteTo(kind, src) })
+	}
+	return s.pasteTo(kind, src)
+}
+
+func (s *State) pasteTo(kind string, src *t.Tree) tea.Cmd {
+	marked := src.Marked
+	if marked == nil {
+		return nil
+	}
+	target := s.pasteTree(src)
+	if target.FS() != src.FS() {
+		s.ErrBuf = i18n.T("ui.paste-other-fs")
+		return nil
+	}
+	dst := filepath.Join(target.CurrentDir.Path, marked.Info.Name())
+	if dst != marked.Path && paths.Within(marked.Path, dst) {
+		s.ErrBuf = fmt.Sprintf(i18n.T("ui.paste-into-self"), marked.Path)
+		return nil
+	}
+	if _, err := src.FS().Lstat(dst); err != nil {
+		src.DropMark()
+		return s.startJob(src.FS(), kind, marked.Path, dst, false)
+	}
+	s.conflict = &pasteConflict{kind: kind, tree: src, dst: dst}
+	if s.onConflict != "" {
+		return s.resolveConflict(s.onConflict)
+	}
+	s.OpBuf = PasteConflict
+	return nil
+}
+
+// Returns path of the existing paste target, waiting for a decision.
+func (s *State) ConflictPath() string {
+	if s.conflict == nil {
+		return ""
+	}
+	return s.conflict.dst
+}
+
+func (s *State) processKeyPasteConflict(msg tea.KeyMsg) tea.Cmd {
+	key := msg.String()
+	switch strings.ToLower(key) {
+	case ConflictOverwrite, ConflictSkip, ConflictRename:
+		s.OpBuf = Noop
+		if key != strings.ToLower(key) {
+			s.onConflict = strings.ToLower(key)
+		}
+		return s.resolveConflict(strings.ToLower(key))
+	case "esc":
+		s.OpBuf = Noop
+		s.conflict = nil
+	}
+	return nil
+}
+
+func (s *State) resolveConflict(decision string) tea.Cmd {
+	c := s.conflict
+	s.conflict = nil
+	marked := c.tree.Marked
+	if marked == nil {
+		return nil
+	}
+	c.tree.DropMark()
+	switch decision {
+	case ConflictOverwrite:
+		if err := checkOverwrite(marked.Path, c.dst); err != nil {
+			s.ErrBuf = err.Error()
+			return nil
+		}
+		if marked.Path == c.dst {
+			return nil // pasted onto itself
+		}
+		return s.guard(c.dst, func() tea.Cmd { return s.startJob(c.tree.FS(), c.kind, marked.Path, c.dst, true) })
+	case ConflictRename:
+		dst, err := c.tree.FreeName(c.dst)
+		if err != nil {
+			s.ErrBuf = err.Error()
+			return nil
+		}
+		return s.startJob(c.tree.FS(), c.kind, marked.Path, dst, false)
+	}
+	return nil
+}
+
+// Target, that contains the source, can't be replaced with it.
+func checkOverwrite(src, dst string) error {
+	if src != dst && paths.Within(dst, src) {
+		return fmt.Errorf(i18n.T("ui.overwrite-own-content"), dst)
+	}
+	return nil
+}
+
+// Waits for the next progress update or completion.
+func (j *FileJob) read() tea.Cmd {
+	id, updates, done := j.id, j.updates, j.done
+	return func() tea.Msg {
+		select {
+		case p := <-updates:
+			return FileJobProgress{id: id, Progress: p}
+		case err := <-done:
+			// final update is sent before completion, but select may pick either
+			select {
+			case p := <-updates:
+				return FileJobProgress{id: id, Progress: p, Done: true, Err: err}
+			default:
+				return FileJobProgress{id: id, Done: true, Err: err}
+			}
+		}
+	}
+}
+
+func (s *State) processFileJobProgress(msg FileJobProgress) tea.Cmd {
+	j := s.Job
+	if j == nil || j.id != msg.id {
+		return nil
+	}
+	if !msg.Done {
+		j.Progress = msg.Progress
+		if j.rate > 0 {
+			j.Estimate = time.Duration(float64(j.Progress.TotalBytes) / j.rate * float64(time.Second))
+		}
+		return j.read()
+	}
+	if msg.Progress != (fileop.Progress{}) {
+		j.Progress = msg.Progress
+	}
+	j.cancel()
+	s.Job = nil
+	slog.Debug("job done", "kind", j.Kind, "src", j.Src, "dst", j.Dst, "paths", len(j.Paths), "bytes", j.Progress.Bytes, "elapsed", j.Progress.Elapsed, "err", msg.Err)
+	if msg.Err != nil && !errors.Is(msg.Err, context.Canceled) {
+		s.ErrBuf = msg.Err.Error()
+	}
+	if msg.Err == nil {
+		s.recordJob(j)
+	}
+	var changed *changedError
+	if errors.As(msg.Err, &changed) && s.JournalPos < len(s.Journal) {
+		s.JournalPos++ // the copy is still there, so it's not undone
+	}
+	if msg.Err == nil && j.Kind == JobDelete {
+		for _, p := range j.Paths {
+			s.runHook(HookDelete, p)
+		}
+	}
+	if j.Kind == JobDelete && s.Cleanup != nil {
+		s.Cleanup.dropDeleted()
+	}
+	if msg.Err == nil && j.FS != "" {
+		if err := s.Throughput.Record(j.FS, j.Progress.Bytes, j.Progress.Elapsed); err != nil {
+			s.ErrBuf = err.Error()
+		}
+	}
+	return nil
+}
+
+// Stops the running operation. Partial copy is removed, partial delete stays as is.
+func (s *State) cancelJob() {
+	if s.Job != nil {
+		s.Job.cancel()
+	}
+}
@@ -0,0 +1,316 @@
+package state
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"path/filepath"
+	"slices"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/LeperGnome/bt/internal/fileop"
+	"github.com/LeperGnome/bt/internal/i18n"
+	t "github.com/LeperGnome/bt/internal/tree"
+)
+
+const JournalLimit = 100 // older operations are dropped
+
+// File operation in the journal, with the file system it was made in.
+type JournalEntry struct {
+	fileop.Command
+	fsys t.FS
+}
+
+// Adds finished operation to the journal. Undone operations after it can't be redone anymore.
+func (s *State) record(fsys t.FS, c fileop.Command) {
+	c.Time = time.Now()
+	s.Journal = append(s.Journal[:s.JournalPos], JournalEntry{c, fsys})
+	if len(s.Journal) > JournalLimit {
+		s.Journal = s.Journal[len(s.Journal)-JournalLimit:]
+	}
+	s.JournalPos = len(s.Journal)
+}
+
+// Records rename of old path in the focused tree to the new name.
+func (s *State) recordRename(old, name string) {
+	if name == filepath.Base(old) {
+		return
+	}
+	s.record(s.Tree.FS(), fileop.Command{Kind: fileop.OpRename, Paths: []string{old}, Targets: []string{filepath.Join(filepath.Dir(old), name)}})
+}
+
+// Records path, created in the focused tree. Empty path was there already.
+func (s *State) recordCreated(path string, err error) {
+	if err != nil {
+		s.ErrBuf = err.Error()
+		return
+	}
+	if path == "" {
+		return
+	}
+	info, err := s.Tree.FS().Lstat(path)
+	if err != nil {
+		return
+	}
+	s.record(s.Tree.FS(), fileop.Command{Kind: fileop.OpCreate, Paths: []string{path}, Dir: info.IsDir()})
+}
+
+// Records copy, move or removal, finished by a job. Undo and redo aren't recorded again.
+func (s *State) recordJob(j *FileJob) {
+	if j.replay {
+		if j.Kind == JobCopy {
+			s.restamp(j)
+		}
+		return
+	}
+	switch j.Kind {
+	case JobCopy, JobMove:
+		s.record(j.fsys, fileop.Command{Kind: j.Kind, Paths: j.Paths, Targets: j.Targets, Replaced: j.replace, Stamps: j.stamps})
+	case JobDelete:
+		s.record(j.fsys, fileop.Command{Kind: fileop.OpDelete, Paths: j.Paths})
+	}
+}
+
+// Redone copy is a new one, its stamps replace the ones of the first copy.
+func (s *State) restamp(j *FileJob) {
+	for i := range s.Journal {
+		if e := &s.Journal[i]; e.Kind == fileop.OpCopy && slices.Equal(e.Targets, j.Targets) {
+			e.Stamps = j.stamps
+		}
+	}
+}
+
+// Copy target, changed since the copy. Undo doesn't delete it.
+type changedError struct {
+	path string
+}
+
+func (e *changedError) Error() string {
+	return fmt.Sprintf(i18n.T("ui.changed-since-copy"), e.path)
+}
+
+// Returns stamp of the tree at path.
+func stampOf(fsys t.FS, path string) (fileop.Stamp, error) {
+	info, err := fsys.Lstat(path)
+	if err != nil {
+		return fileop.Stamp{}, err
+	}
+	st := fileop.Stamp{Entries: 1, Size: info.Size(), ModTime: info.ModTime()}
+	if !info.IsDir() {
+		return st, nil
+	}
+	entries, err := fsys.ReadDir(path)
+	if err != nil {
+		return fileop.Stamp{}, err
+	}
+	for _, e := range entries {
+		sub, err := stampOf(fsys, filepath.Join(path, e.Name()))
+		if err != nil {
+			return fileop.Stamp{}, err
+		}
+		st.Entries += sub.Entries
+		st.Size += sub.Size
+		if sub.ModTime.After(st.ModTime) {
+			st.ModTime = sub.ModTime
+		}
+	}
+	return st, nil
+}
+
+// Returns stamps of paths, nil if any of them can't be read.
+func stampAll(fsys t.FS, paths []string) []fileop.Stamp {
+	stamps := make([]fileop.Stamp, len(paths))
+	for i, p := range paths {
+		st, err := stampOf(fsys, p)
+		if err != nil {
+			return nil
+		}
+		stamps[i] = st
+	}
+	return stamps
+}
+
+// Fails with changedError, if any of paths doesn't match its stamp anymore. Missing paths match,
+// there's nothing to lose. No stamps - nothing to check.
+func checkStamps(fsys t.FS, paths []string, stamps []fileop.Stamp) error {
+	for i, p := range stamps {
+		st, err := stampOf(fsys, paths[i])
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		if st.Entries != p.Entries || st.Size != p.Size || !st.ModTime.Equal(p.ModTime) {
+			return &changedError{paths[i]}
+		}
+	}
+	return nil
+}
+
+// Undoes the last operation in the journal, which is not undone yet.
+func (s *State) undo() tea.Cmd {
+	if s.JournalPos == 0 {
+		s.ErrBuf = i18n.T("ui.nothing-to-undo")
+		return nil
+	}
+	if s.jobBusy() {
+		return nil
+	}
+	e := s.Journal[s.JournalPos-1]
+	inv, err := e.Inverse()
+	if err != nil {
+		s.ErrBuf = err.Error()
+		return nil
+	}
+	cmd, err := s.replay(e.fsys, inv)
+	if err != nil {
+		s.ErrBuf = fmt.Sprintf(i18n.T("ui.undo-failed"), i18n.T("ui.op-"+e.Kind), err)
+		return nil
+	}
+	s.JournalPos--
+	return cmd
+}
+
+// Does the first undone operation again.
+func (s *State) redo() tea.Cmd {
+	if s.JournalPos == len(s.Journal) {
+		s.ErrBuf = i18n.T("ui.nothing-to-redo")
+		return nil
+	}
+	if s.jobBusy() {
+		return nil
+	}
+	e := s.Journal[s.JournalPos]
+	cmd, err := s.replay(e.fsys, e.Command)
+	if err != nil {
+		s.ErrBuf = fmt.Sprintf(i18n.T("ui.redo-failed"), i18n.T("ui.op-"+e.Kind), err)
+		return nil
+	}
+	s.JournalPos++
+	return cmd
+}
+
+// Runs command without recording it. Copies, moves and removal of whole trees run as jobs.
+// Nothing is overwritten: existing targets fail the command before it starts.
+func (s *State) replay(fsys t.FS, c fileop.Command) (tea.Cmd, error) {
+	switch c.Kind {
+	case fileop.OpRename:
+		return nil, renameAll(fsys, c.Paths, c.Targets)
+	case fileop.OpCreate:
+		for _, p := range c.Paths {
+			if err := create(fsys, p, c.Dir); err != nil {
+				return nil, err
+			}
+		}
+		return nil, nil
+	case fileop.OpDelete:
+		if c.Empty {
+			for _, p := range c.Paths {
+				if err := removeEmpty(fsys, p); err != nil {
+					return nil, err
+				}
+			}
+			return nil, nil
+		}
+		job := &FileJob{Kind: JobDelete, Src: c.Paths[0], Paths: c.Paths, replay: true, stamps: c.Stamps}
+		return s.runJob(fsys, job, false), nil
+	case fileop.OpCopy, fileop.OpMove:
+		for _, p := range c.Targets {
+			if _, err := fsys.Lstat(p); err == nil {
+				return nil, fmt.Errorf(i18n.T("ui.path-exists"), p)
+			}
+		}
+		kind := JobCopy
+		if c.Kind == fileop.OpMove {
+			kind = JobMove
+		}
+		job := &FileJob{Kind: kind, Src: c.Paths[0], Dst: c.Targets[0], Paths: c.Paths, Targets: c.Targets, replay: true}
+		return s.runJob(fsys, job, false), nil
+	}
+	return nil, fmt.Errorf(i18n.T("ui.unknown-operation"), c.Kind)
+}
+
+// Renames paths to targets at the same index. Local renames may swap names, see tree.ApplyRenames.
+func renameAll(fsys t.FS, paths, targets []string) error {
+	renames := make([]t.Rename, len(paths))
+	for i, p := range paths {
+		renames[i] = t.Rename{Old: p, New: targets[i]}
+	}
+	if fsys == t.OS {
+		if err := t.CheckRenames(renames); err != nil {
+			return err
+		}
+		return t.ApplyRenames(renames)
+	}
+	for _, r := range renames {
+		if _, err := fsys.Lstat(r.New); err == nil {
+			return fmt.Errorf(i18n.T("ui.path-exists"), r.New)
+		}
+		if err := fsys.Rename(r.Old, r.New); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func create(fsys t.FS, path string, dir bool) error {
+	if _, err := fsys.Lstat(path); err == nil {
+		return fmt.Errorf(i18n.T("ui.path-exists"), path)
+	}
+	if dir {
+		return fsys.MkdirAll(path)
+	}
+	return fsys.Create(path)
+}
+
+// Removes path, if it's an empty file or directory. Content, written since it was created, stays.
+func removeEmpty(fsys t.FS, path string) error {
+	info, err := fsys.Lstat(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if info.IsDir() {
+		entries, err := fsys.ReadDir(path)
+		if err != nil {
+			return err
+		}
+		if len(entries) > 0 {
+			return fmt.Errorf(i18n.T("ui.not-empty-anymore"), path)
+		}
+	} else if info.Size() > 0 {
+		return fmt.Errorf(i18n.T("ui.not-empty-anymore"), path)
+	}
+	return fsys.RemoveAll(path)
+}
+
+func (s *State) showJournal() {
+	s.JournalOffset = 0
+	s.OpBuf = JournalView
+}
+
+// Scrolls the journal with j / k, undoes and redoes with u / r, any other key closes it.
+func (s *State) processKeyJournal(msg tea.KeyMsg) tea.Cmd {
+	switch msg.String() {
+	case "j", "down":
+		s.JournalOffset = max(min(s.JournalOffset+1, len(s.Journal)-1), 0)
+	case "k", "up":
+		s.JournalOffset = max(s.JournalOffset-1, 0)
+	case "u":
+		if !s.refused(ActionUndo) {
+			return s.undo()
+		}
+	case "r":
+		if !s.refused(ActionRedo) {
+			return s.redo()
+		}
+	default:
+		s.OpBuf = Noop
+	}
+	return nil
+}
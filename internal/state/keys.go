@@ -0,0 +1,69 @@
+package state
+
+import (
+	"strconv"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+const MaxCount = 9999
+
+// Collects the key into the count or the chord, typed so far. Returns action of the completed
+// sequence with its count, 0 - no count. Reports false, while more keys are awaited, or when
+// the chord is unknown, then it's dropped, like in vim.
+func (s *State) readKey(key string) (ActionID, int, bool) {
+	if len(s.chord) == 0 && len(key) == 1 && key[0] >= '0' && key[0] <= '9' && (s.count > 0 || key != "0") {
+		s.count = min(s.count*10+int(key[0]-'0'), MaxCount)
+		return ActionNone, 0, false
+	}
+	keys := append(s.chord, key)
+	action, more := s.Keymap.Match(keys)
+	if more {
+		s.chord = keys
+		return ActionNone, 0, false
+	}
+	count := s.count
+	s.chord, s.count = nil, 0
+	if action == ActionNone && len(keys) > 1 {
+		return ActionNone, 0, false
+	}
+	return action, count, true
+}
+
+// Returns count and chord, typed so far, e.g. "5 g".
+func (s *State) PendingKeys() string {
+	keys := s.chord
+	if s.count > 0 {
+		keys = append([]string{strconv.Itoa(s.count)}, keys...)
+	}
+	return strings.Join(keys, " ")
+}
+
+// Runs action with a count: motions are repeated, going to the first or the last child
+// goes to the child with that number instead, expansion goes that many levels deep.
+// Other actions ignore the count.
+func (s *State) processCounted(action ActionID, count int) tea.Cmd {
+	switch action {
+	case ActionSelectFirst, ActionSelectLast:
+		if !s.HelpToggle {
+			if entries := s.Entries(); len(entries) > 0 {
+				s.Tree.Select(entries[min(count, len(entries))-1])
+			}
+			return nil
+		}
+	case ActionExpandAll:
+		s.expandSelected(count)
+		return nil
+	case ActionSelectNext, ActionSelectPrev, ActionEnterDir, ActionParentDir,
+		ActionPreviewDown, ActionPreviewUp, ActionPreviewPageDown, ActionPreviewPageUp,
+		ActionScrollLeft, ActionScrollRight, ActionSearchNext, ActionSearchPrev,
+		ActionBack, ActionForward, ActionNextTab, ActionPrevTab:
+		cmds := make([]tea.Cmd, 0, count)
+		for range count {
+			cmds = append(cmds, s.processAction(action))
+		}
+		return tea.Batch(cmds...)
+	}
+	return s.processAction(action)
+}
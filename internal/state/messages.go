@@ -0,0 +1,57 @@
+package state
+
+import (
+	"log/slog"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+const MessagesLimit = 200 // older messages are dropped
+
+// Error or notice, shown in the error line and kept in the message log.
+type Message struct {
+	Time  time.Time
+	Text  string
+	Count int // repeated in a row
+}
+
+// Adds text to the message log. Repeated text only counts, so a failing poll doesn't flood the log.
+func (s *State) report(text string) {
+	slog.Debug("message", "text", text)
+	if n := len(s.Messages); n > 0 && s.Messages[n-1].Text == text {
+		s.Messages[n-1].Time = time.Now()
+		s.Messages[n-1].Count++
+		return
+	}
+	s.Messages = append(s.Messages, Message{Time: time.Now(), Text: text, Count: 1})
+	if len(s.Messages) > MessagesLimit {
+		s.Messages = s.Messages[len(s.Messages)-MessagesLimit:]
+	}
+}
+
+// Logs the error line, when it's set to a new text.
+func (s *State) syncMessages() {
+	if s.ErrBuf != "" && s.ErrBuf != s.reportedErr {
+		s.report(s.ErrBuf)
+	}
+	s.reportedErr = s.ErrBuf
+}
+
+func (s *State) showMessages() {
+	s.MessageOffset = 0
+	s.OpBuf = MessageLog
+}
+
+// Scrolls the log with j / k, any other key closes it.
+func (s *State) processKeyMessages(msg tea.KeyMsg) tea.Cmd {
+	switch msg.String() {
+	case "j", "down":
+		s.MessageOffset = max(min(s.MessageOffset+1, len(s.Messages)-1), 0)
+	case "k", "up":
+		s.MessageOffset = max(s.MessageOffset-1, 0)
+	default:
+		s.OpBuf = Noop
+	}
+	return nil
+}
@@ -0,0 +1,73 @@
+package state
+
+import (
+	"fmt"
+
+	"github.com/LeperGnome/bt/internal/config"
+	"github.com/LeperGnome/bt/internal/i18n"
+	t "github.com/LeperGnome/bt/internal/tree"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// Handles Enter on the selected node according to open config.
+func (s *State) openSelected() tea.Cmd {
+	selected := s.Tree.GetSelectedChild()
+	if selected == nil {
+		return nil
+	}
+	if selected.IsDir() {
+		var err error
+		if s.Open.ForDir() == config.OpenEnter {
+			err = s.Tree.SetSelectedChildAsCurrent()
+		} else {
+			err = s.Tree.CollapseOrExpandSelected()
+		}
+		if err != nil {
+			s.ErrBuf = err.Error()
+		}
+		return nil
+	}
+	if s.Pick {
+		s.toggleSelected()
+		return nil
+	}
+	behavior := s.Open.ForFile(selected.Info.Name())
+	if behavior != config.OpenPreview && !s.localOnly() {
+		return nil
+	}
+	s.runHook(HookOpen, selected.Path)
+	switch behavior {
+	case config.OpenEdit:
+		if selected.Info.Mode().IsRegular() {
+			return openEditor(selected.Path)
+		}
+	case config.OpenSystem:
+		return s.openExternal(selected)
+	default:
+		s.PreviewToggle = true
+		s.setFocus(PanePreview)
+		s.fixFocus()
+	}
+	return nil
+}
+
+// Expands the selected directory down to depth levels, 0 - all of them.
+func (s *State) expandSelected(depth int) {
+	limited, err := s.Tree.ExpandSelected(depth)
+	s.reportExpand(limited, err)
+}
+
+// Expands the root down to depth levels, 0 - as deep as it goes. Used on start.
+func (s *State) ExpandRoot(depth int) {
+	limited, err := s.Tree.Expand(s.Tree.Root, depth)
+	s.reportExpand(limited, err)
+}
+
+func (s *State) reportExpand(limited bool, err error) {
+	switch {
+	case err != nil:
+		s.ErrBuf = err.Error()
+	case limited:
+		s.ErrBuf = fmt.Sprintf(i18n.T("ui.expand-limited"), t.ExpandLimit)
+	}
+}
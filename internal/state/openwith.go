@@ -0,0 +1,108 @@
+package state
+
+import (
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/LeperGnome/bt/internal/config"
+	"github.com/LeperGnome/bt/internal/preview"
+	t "github.com/LeperGnome/bt/internal/tree"
+)
+
+// Bytes of a file, sniffed for its MIME type, when the extension doesn't tell it.
+const sniffLen = 512
+
+// Opens node with the first matching opener of open config, or with the system default application.
+func (s *State) openExternal(n *t.Node) tea.Cmd {
+	if o, ok := s.openerFor(n); ok {
+		return openWith(o.Command, n.Path, o.Terminal)
+	}
+	return openSystem(n.Path)
+}
+
+// Returns the first opener, matching the file. Directories are opened by the system.
+func (s *State) openerFor(n *t.Node) (config.Opener, bool) {
+	if n.IsDir() {
+		return config.Opener{}, false
+	}
+	fsys := s.Tree.FS()
+	head := func() []byte {
+		f, err := fsys.Open(n.Path)
+		if err != nil {
+			return nil
+		}
+		defer f.Close()
+		buf := make([]byte, sniffLen)
+		k, _ := io.ReadFull(f, buf)
+		return buf[:k]
+	}
+	for _, o := range s.Open.With {
+		if preview.Matches(o.Match, n.Path, head) {
+			return o, true
+		}
+	}
+	return config.Opener{}, false
+}
+
+// Asks for a command to open the selected node with once, offering its opener.
+func (s *State) promptOpenWith() {
+	selected := s.Tree.GetSelectedChild()
+	if selected == nil {
+		return
+	}
+	command := ""
+	if o, ok := s.openerFor(selected); ok {
+		command = o.Command
+		if o.Terminal {
+			command = "!" + command
+		}
+	}
+	s.OpBuf = OpenWith
+	s.setInput(command)
+}
+
+func (s *State) processKeyOpenWith(msg tea.KeyMsg) tea.Cmd {
+	if msg.String() != "enter" {
+		return s.processKeyAnyInput(msg)
+	}
+	input := strings.TrimSpace(string(s.InputBuf))
+	s.setInput("")
+	s.OpBuf = Noop
+	selected := s.Tree.GetSelectedChild()
+	// leading ! runs command in the terminal, like in shell input
+	terminal := strings.HasPrefix(input, "!")
+	command := strings.TrimSpace(strings.TrimPrefix(input, "!"))
+	if selected == nil || command == "" {
+		return nil
+	}
+	s.runHook(HookOpen, selected.Path)
+	return openWith(command, selected.Path, terminal)
+}
+
+// Runs command on path: {} in its arguments is replaced with path, or path is appended, if there is no {}.
+// Environment variables are expanded, so $EDITOR works.
+func openWith(command, path string, terminal bool) tea.Cmd {
+	args := strings.Fields(os.ExpandEnv(command))
+	if len(args) == 0 {
+		return nil
+	}
+	substituted := false
+	for i, a := range args {
+		if strings.Contains(a, "{}") {
+			args[i] = strings.ReplaceAll(a, "{}", path)
+			substituted = true
+		}
+	}
+	if !substituted {
+		args = append(args, path)
+	}
+	c := exec.Command(args[0], args[1:]...)
+	if terminal {
+		return execInteractive(c)
+	}
+	return startDetached(c)
+}
@@ -0,0 +1,80 @@
+package state
+
+import (
+	t "github.com/LeperGnome/bt/internal/tree"
+)
+
+// Starts forwarding tree changes into the common state channel.
+func (s *State) watchTree(changes <-chan t.NodeChange) {
+	go func() {
+		for ch := range changes {
+			s.nodeChanges <- ch
+		}
+	}()
+}
+
+// Turns dual pane mode on or off. Second pane is opened in the current directory.
+func (s *State) toggleDualPane() error {
+	if s.DualPane {
+		if s.TimeTravel != nil && s.TimeTravel.Active != nil {
+			// leaving snapshot, live tree stays
+			s.setFocus(PaneFirstTree)
+			s.Panes[1].Close()
+			s.Panes[1] = nil
+			s.TimeTravel = nil
+		}
+		s.DualPane = false
+		s.dropMarks()
+		s.Panes = [2]*t.Tree{s.Tree, s.otherPane()}
+		s.setFocus(PaneFirstTree)
+		return nil
+	}
+	if s.Panes[1] == nil {
+		second, changes, err := t.InitTreeFS(s.Tree.FS(), s.Tree.CurrentDir.Path, s.NameOrder.Sorting())
+		if err != nil {
+			return err
+		}
+		s.watchTree(changes)
+		s.Panes[1] = second
+	}
+	s.DualPane = true
+	s.fixFocus()
+	return nil
+}
+
+func (s *State) otherPane() *t.Tree {
+	return s.Panes[1-s.ActivePane]
+}
+
+// Returns tree, holding marked node, and the node itself.
+func (s *State) marked() (*t.Tree, *t.Node) {
+	for _, p := range s.Panes {
+		if p != nil && p.Marked != nil {
+			return p, p.Marked
+		}
+	}
+	return nil, nil
+}
+
+// Returns marked node across all panes.
+func (s *State) MarkedNode() *t.Node {
+	_, n := s.marked()
+	return n
+}
+
+func (s *State) dropMarks() {
+	for _, p := range s.Panes {
+		if p != nil {
+			p.DropMark()
+		}
+	}
+}
+
+// Paste destination: in dual pane mode, pasting from the pane, that holds the mark,
+// goes to the other pane. Otherwise - to the current directory of the focused pane.
+func (s *State) pasteTree(src *t.Tree) *t.Tree {
+	if s.DualPane && src == s.Tree {
+		return s.otherPane()
+	}
+	return s.Tree
+}
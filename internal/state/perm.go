@@ -0,0 +1,75 @@
+package state
+
+import (
+	"fmt"
+	"io/fs"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	t "github.com/LeperGnome/bt/internal/tree"
+)
+
+// Marks selected child and prefills input with its current octal mode.
+func (s *State) startChmod() {
+	if !s.localOnly() {
+		return
+	}
+	if ok := s.Tree.MarkSelectedChild(); ok {
+		mode := s.Tree.Marked.Info.Mode()
+		s.setInput(fmt.Sprintf("%o", octalMode(mode)))
+		s.OpBuf = Chmod
+	}
+}
+
+// Marks selected child and prefills input with its current owner and group.
+func (s *State) startChown() {
+	if !s.localOnly() {
+		return
+	}
+	if ok := s.Tree.MarkSelectedChild(); ok {
+		owner, group := t.Owner(s.Tree.Marked.Info)
+		s.setInput(owner + ":" + group)
+		s.OpBuf = Chown
+	}
+}
+
+func (s *State) processKeyChmod(msg tea.KeyMsg) tea.Cmd {
+	if msg.String() != "enter" {
+		return s.processKeyAnyInput(msg)
+	}
+	return s.applyInput(s.Tree.ChmodMarked)
+}
+
+func (s *State) processKeyChown(msg tea.KeyMsg) tea.Cmd {
+	if msg.String() != "enter" {
+		return s.processKeyAnyInput(msg)
+	}
+	return s.applyInput(s.Tree.ChownMarked)
+}
+
+// Applies input with f. On error input stays open, so it can be corrected.
+func (s *State) applyInput(f func(string) error) tea.Cmd {
+	if err := f(string(s.InputBuf)); err != nil {
+		s.ErrBuf = err.Error()
+		return nil
+	}
+	s.ErrBuf = ""
+	s.OpBuf = Noop
+	s.setInput("")
+	return nil
+}
+
+// Converts mode to chmod octal notation.
+func octalMode(mode fs.FileMode) uint32 {
+	n := uint32(mode.Perm())
+	if mode&fs.ModeSetuid != 0 {
+		n |= 0o4000
+	}
+	if mode&fs.ModeSetgid != 0 {
+		n |= 0o2000
+	}
+	if mode&fs.ModeSticky != 0 {
+		n |= 0o1000
+	}
+	return n
+}
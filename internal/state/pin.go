@@ -0,0 +1,46 @@
+package state
+
+import (
+	"io"
+	"os"
+)
+
+// Pins preview to the selected file, or unpins it, if something is pinned already.
+func (s *State) togglePin() {
+	if s.PinnedPath != "" {
+		s.PinnedPath = ""
+		s.PreviewOffset = 0
+		return
+	}
+	child := s.Tree.GetSelectedChild()
+	if child == nil || !child.Info.Mode().IsRegular() {
+		return
+	}
+	s.PinnedPath = child.Path
+	s.PreviewToggle = true
+	s.fixFocus()
+}
+
+// Returns beginning of the pinned file content.
+func (s *State) PinnedContent() ([]byte, error) {
+	f, err := os.Open(s.PinnedPath)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	buf := make([]byte, s.previewLimit())
+	n, err := io.ReadFull(f, buf)
+	if err != nil && err != io.ErrUnexpectedEOF && err != io.EOF {
+		return nil, err
+	}
+	return buf[:n], nil
+}
+
+// Reports whether transient preview of the selected file is shown below the pinned one.
+func (s *State) ShowTransient() bool {
+	if s.PinnedPath == "" || !s.PinTransient {
+		return false
+	}
+	child := s.Tree.GetSelectedChild()
+	return child != nil && child.Info.Mode().IsRegular() && child.Path != s.PinnedPath
+}
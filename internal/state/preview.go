@@ -0,0 +1,338 @@
+package state
+
+import (
+	"errors"
+	"io"
+	"io/fs"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/LeperGnome/bt/internal/config"
+	"github.com/LeperGnome/bt/internal/git"
+	"github.com/LeperGnome/bt/internal/preview"
+	t "github.com/LeperGnome/bt/internal/tree"
+)
+
+const DefaultPreviewLimit int64 = 10_000
+
+func (s *State) previewLimit() int64 {
+	if s.PreviewLimit > 0 {
+		return s.PreviewLimit
+	}
+	return DefaultPreviewLimit
+}
+
+// Returns beginning of the selected file content.
+func (s *State) PreviewContent() ([]byte, error) {
+	buf := make([]byte, s.previewLimit())
+	n, err := s.Tree.ReadSelectedChildContent(buf, int64(len(buf)))
+	if err != nil {
+		return nil, err
+	}
+	return buf[:n], nil
+}
+
+// Preview of a file, kept until the file changes or another one is previewed.
+type previewCache struct {
+	path    string
+	fsys    t.FS // the file is in, further chunks of text are read from it
+	modTime time.Time
+	size    int64
+	preview preview.Preview
+	lines   int     // in the text of preview
+	err     error   // failed reads are kept too, so they aren't retried on every render
+	running bool    // command of the pending preview is started
+	file    fs.File // kept open between reads, where it can't seek, so it's read on
+	filePos int64   // offset in the open file
+}
+
+// Output of a preview command, kept for the file version it was run for.
+type commandOutput struct {
+	modTime time.Time
+	size    int64
+	out     string
+	err     error
+}
+
+// Outputs of preview commands beyond that are forgotten all at once.
+const commandOutputsLimit = 64
+
+// Sent, when command of a pending preview finishes.
+type PreviewCommandDone struct {
+	Path    string
+	modTime time.Time
+	size    int64
+	Output  string
+	Err     error
+}
+
+// Returned by Preview, when there's nothing to read: a directory or nothing is selected.
+var ErrNoPreview = errors.New("file not selected or is irregular")
+
+// Returns preview of the selected file, made by the previewer chain, that matches it.
+// Preview is cached by path, mtime and size, and dropped, when the file is reported changed.
+// Failed reads are reported to the message log.
+func (s *State) Preview() (preview.Preview, error) {
+	selected := s.Tree.GetSelectedChild()
+	if selected == nil || !selected.Info.Mode().IsRegular() {
+		return preview.Preview{}, ErrNoPreview
+	}
+	return s.cachedPreview(&s.previewMem, s.Tree.FS(), selected.Path, selected.Info, s.PreviewContent)
+}
+
+// Returns preview of the pinned file, see Preview.
+func (s *State) PinnedPreview() (preview.Preview, error) {
+	info, err := os.Stat(s.PinnedPath)
+	if err != nil {
+		return preview.Preview{}, err
+	}
+	return s.cachedPreview(&s.pinnedMem, t.OS, s.PinnedPath, info, s.PinnedContent)
+}
+
+func (s *State) cachedPreview(c *previewCache, fsys t.FS, path string, info fs.FileInfo, read func() ([]byte, error)) (preview.Preview, error) {
+	if c.path == path && c.modTime.Equal(info.ModTime()) && c.size == info.Size() {
+		return c.preview, c.err
+	}
+	c.close()
+	*c = previewCache{path: path, fsys: fsys, modTime: info.ModTime(), size: info.Size()}
+	content, err := read()
+	if err != nil {
+		c.err = err
+		s.report(err.Error())
+		return preview.Preview{}, err
+	}
+	previewers := s.Previewers
+	if fsys != t.OS {
+		previewers = withoutCommands(previewers)
+	}
+	c.preview = preview.Make(previewers, path, info.Size(), content)
+	if o, ok := s.commandOutputs[path]; ok && c.preview.Pending() && o.modTime.Equal(c.modTime) && o.size == c.size {
+		c.preview = c.preview.Finish(o.out, o.err)
+	}
+	c.lines = c.preview.Lines()
+	return c.preview, nil
+}
+
+// Reads previews of shown files ahead of rendering and starts commands of pending ones in background.
+// Until a command finishes, its preview is shown as pending.
+func (s *State) LoadPreview() tea.Cmd {
+	if !s.PreviewToggle {
+		return nil
+	}
+	var cmds []tea.Cmd
+	if s.PinnedPath != "" {
+		s.PinnedPreview()
+		cmds = append(cmds, s.runPreviewCommand(&s.pinnedMem))
+	}
+	if s.PinnedPath == "" || s.ShowTransient() {
+		s.Preview()
+		cmds = append(cmds, s.runPreviewCommand(&s.previewMem))
+	}
+	return tea.Batch(cmds...)
+}
+
+func (s *State) runPreviewCommand(c *previewCache) tea.Cmd {
+	if !c.preview.Pending() || c.running {
+		return nil
+	}
+	c.running = true
+	p, path, modTime, size := c.preview, c.path, c.modTime, c.size
+	return func() tea.Msg {
+		out, err := p.Run()
+		return PreviewCommandDone{Path: path, modTime: modTime, size: size, Output: out, Err: err}
+	}
+}
+
+func (s *State) processPreviewCommandDone(msg PreviewCommandDone) tea.Cmd {
+	if len(s.commandOutputs) >= commandOutputsLimit {
+		s.commandOutputs = map[string]commandOutput{}
+	}
+	s.commandOutputs[msg.Path] = commandOutput{modTime: msg.modTime, size: msg.size, out: msg.Output, err: msg.Err}
+	for _, c := range []*previewCache{&s.previewMem, &s.pinnedMem} {
+		if c.path == msg.Path && c.modTime.Equal(msg.modTime) && c.size == msg.size && c.preview.Pending() {
+			c.preview = c.preview.Finish(msg.Output, msg.Err)
+			c.lines = strings.Count(c.preview.Text, "\n") + 1
+			c.running = false
+		}
+	}
+	return nil
+}
+
+// Returns previewers with command kinds dropped from their chains, commands can't read remote files.
+func withoutCommands(ps []config.Previewer) []config.Previewer {
+	out := make([]config.Previewer, len(ps))
+	for i, p := range ps {
+		p.Chain = slices.DeleteFunc(slices.Clone(p.Chain), func(kind string) bool { return kind == config.PreviewCommand })
+		out[i] = p
+	}
+	return out
+}
+
+// Reads the previewed file on or back by chunks of the preview limit, until its text has lines
+// from..to or the file ends, so huge files are scrolled through without reading them whole.
+// Chunks far from these lines are dropped, so only a window of the file is kept.
+// Returns number of lines of the file up to the end of the text.
+func (s *State) continuePreview(c *previewCache, from, to int) int {
+	if offset, n, ok := c.preview.Back(from); ok {
+		if n > s.previewLimit() {
+			c.preview.Rewind(offset)
+		} else if chunk, err := c.read(offset, n); err != nil {
+			s.report(err.Error())
+		} else if int64(len(chunk)) == n {
+			c.preview.Prepend(offset, chunk)
+		}
+	}
+	for c.preview.Partial() && c.preview.Lines() <= to {
+		chunk, err := c.read(c.preview.Offset(), s.previewLimit())
+		if err != nil {
+			s.report(err.Error())
+			break
+		}
+		if len(chunk) == 0 {
+			break // file got shorter, the change is picked up by the watcher
+		}
+		c.preview.Continue(chunk)
+		c.preview.Trim(from, to)
+	}
+	c.preview.Trim(from, to)
+	c.lines = c.preview.Lines()
+	return c.lines
+}
+
+// Reads up to n bytes of the previewed file, starting at offset. File, that can't seek,
+// is kept open and read on, and opened again only to read back.
+func (c *previewCache) read(offset, n int64) ([]byte, error) {
+	if c.file != nil && offset < c.filePos {
+		c.close()
+	}
+	if c.file == nil {
+		f, err := c.fsys.Open(c.path)
+		if err != nil {
+			return nil, err
+		}
+		c.file, c.filePos = f, 0
+	}
+	var err error
+	if seeker, ok := c.file.(io.Seeker); ok {
+		defer c.close() // seekable files are opened for each read, so nothing is held open
+		_, err = seeker.Seek(offset, io.SeekStart)
+	} else {
+		_, err = io.CopyN(io.Discard, c.file, offset-c.filePos)
+	}
+	if err != nil {
+		c.close()
+		return nil, err
+	}
+	buf := make([]byte, n)
+	read, err := io.ReadFull(c.file, buf)
+	c.filePos = offset + int64(read)
+	if err != nil && err != io.ErrUnexpectedEOF && err != io.EOF {
+		c.close()
+		return nil, err
+	}
+	return buf[:read], nil
+}
+
+// Closes the file, kept open by read.
+func (c *previewCache) close() {
+	if c.file != nil {
+		c.file.Close()
+		c.file = nil
+	}
+}
+
+// Drops cached previews of path, e.g. when it's written within the same second with the same size.
+func (s *State) invalidatePreview(path string) {
+	for _, c := range []*previewCache{&s.previewMem, &s.pinnedMem} {
+		if c.path == path {
+			c.close()
+			*c = previewCache{}
+		}
+	}
+}
+
+const (
+	DefaultSplitRatio = 0.5
+	SplitRatioStep    = 0.05
+	MinSplitRatio     = 0.2
+	MaxSplitRatio     = 0.8
+)
+
+// Sets share of width for the tree pane, clamped to sane bounds.
+func (s *State) SetSplitRatio(r float64) {
+	s.SplitRatio = max(min(r, MaxSplitRatio), MinSplitRatio)
+}
+
+func (s *State) SetWindowSize(height, width int) {
+	s.windowHeight = height
+	s.windowWidth = width
+}
+
+// Scrolls preview by delta lines, keeping at least one line visible.
+// Hidden preview is not scrolled, so files are never read for it.
+func (s *State) scrollPreview(delta int) {
+	if s.Tail != nil {
+		s.Tail.scroll(delta)
+		return
+	}
+	if s.Compare != nil {
+		lines := strings.Count(s.Compare.Diff, "\n")
+		s.PreviewOffset = max(min(s.PreviewOffset+delta, lines-1), 0)
+		return
+	}
+	if !s.PreviewToggle {
+		return
+	}
+	lines := 1
+	// a window of text is kept before the shown lines and a window ahead of them
+	line := max(s.PreviewOffset+delta, 0)
+	from, to := line-s.windowHeight, line+2*s.windowHeight
+	if s.PinnedPath != "" {
+		if _, err := s.PinnedPreview(); err == nil {
+			lines = s.continuePreview(&s.pinnedMem, from, to)
+		}
+	} else if diff, ok := s.PreviewDiff(); ok {
+		lines = strings.Count(diff, "\n") + 1
+	} else if _, err := s.Preview(); err == nil {
+		lines = s.continuePreview(&s.previewMem, from, to)
+	} else if l, err := s.DirPreview(); err == nil {
+		lines = len(l.Entries) + 1
+	}
+	s.PreviewOffset = max(min(s.PreviewOffset+delta, lines-1), 0)
+}
+
+type diffCache struct {
+	path    string
+	modTime time.Time
+	diff    string
+}
+
+// Returns diff of the selected file against git HEAD, if diff preview is on
+// and the file is modified. Diff is cached until the file changes.
+func (s *State) PreviewDiff() (string, bool) {
+	if !s.DiffToggle || !s.Tree.Local() {
+		return "", false
+	}
+	selected := s.Tree.GetSelectedChild()
+	if selected == nil || !selected.Info.Mode().IsRegular() {
+		return "", false
+	}
+	c := s.diffCache
+	if c.path != selected.Path || !c.modTime.Equal(selected.Info.ModTime()) {
+		diff, err := git.DiffHead(selected.Path)
+		if err != nil {
+			diff = ""
+		}
+		s.diffCache = diffCache{path: selected.Path, modTime: selected.Info.ModTime(), diff: diff}
+	}
+	return s.diffCache.diff, s.diffCache.diff != ""
+}
+
+// Half of the window is scrolled on page up / down.
+func (s *State) previewPage() int {
+	return max(s.windowHeight/2, 1)
+}
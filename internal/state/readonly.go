@@ -0,0 +1,41 @@
+package state
+
+import (
+	"slices"
+
+	"github.com/LeperGnome/bt/internal/i18n"
+)
+
+// Actions, that change files. Read-only mode refuses them, so nothing is deleted by accident.
+// Editors, shell commands and programs, paths are sent to, are the user's own and stay available.
+var writeActions = []ActionID{
+	ActionInsert,
+	ActionMove,
+	ActionCopy,
+	ActionCopyTo,
+	ActionMoveTo,
+	ActionArchive,
+	ActionExtract,
+	ActionDelete,
+	ActionToss,
+	ActionBasket,
+	ActionRename,
+	ActionBulkRename,
+	ActionChmod,
+	ActionChown,
+	ActionExport,
+	ActionRestore,
+	ActionUndo,
+	ActionRedo,
+	ActionCleanup,
+	ActionCleanArtifacts,
+}
+
+// Reports whether action is refused in read-only mode, telling so.
+func (s *State) refused(action ActionID) bool {
+	if !s.ReadOnly || !slices.Contains(writeActions, action) {
+		return false
+	}
+	s.ErrBuf = i18n.T("ui.read-only")
+	return true
+}
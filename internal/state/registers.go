@@ -0,0 +1,159 @@
+package state
+
+import (
+	"fmt"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/LeperGnome/bt/internal/i18n"
+	t "github.com/LeperGnome/bt/internal/tree"
+)
+
+// Paths, yanked into a named register, with the file system they are in.
+type Register struct {
+	Paths []string
+	fsys  t.FS
+}
+
+// Returns the register named by the key: a-z, uppercase letter names the same register for appending.
+func registerName(msg tea.KeyMsg) (rune, bool, bool) {
+	if msg.Type != tea.KeyRunes || len(msg.Runes) != 1 {
+		return 0, false, false
+	}
+	r := msg.Runes[0]
+	switch {
+	case r >= 'a' && r <= 'z':
+		return r, false, true
+	case r >= 'A' && r <= 'Z':
+		return r - 'A' + 'a', true, true
+	}
+	return 0, false, false
+}
+
+// Waits for the register name, then for what to do with it.
+func (s *State) processKeyRegisterPick(msg tea.KeyMsg) tea.Cmd {
+	name, appending, ok := registerName(msg)
+	if !ok {
+		s.OpBuf = Noop
+		return nil
+	}
+	s.register, s.registerAppend = name, appending
+	s.OpBuf = RegisterUse
+	return nil
+}
+
+// Yanks into the picked register, pastes copies of its paths, moves them here or clears it.
+func (s *State) processKeyRegisterUse(msg tea.KeyMsg) tea.Cmd {
+	s.OpBuf = Noop
+	switch msg.String() {
+	case "y":
+		s.yankRegister(s.register, s.registerAppend)
+	case "p":
+		if !s.refused(ActionCopy) {
+			return s.pasteRegister(JobCopy, s.register)
+		}
+	case "m":
+		if !s.refused(ActionMove) {
+			return s.pasteRegister(JobMove, s.register)
+		}
+	case "c":
+		delete(s.Registers, s.register)
+	}
+	return nil
+}
+
+// Returns the register being used, for the prompt.
+func (s *State) RegisterName() rune {
+	return s.register
+}
+
+// Puts selected paths, or the child under cursor, into the register. Appended paths
+// must be in the same file system as the ones there.
+func (s *State) yankRegister(name rune, appending bool) {
+	srcs := s.shellTargets()
+	if len(srcs) == 0 {
+		return
+	}
+	if s.Registers == nil {
+		s.Registers = map[rune]*Register{}
+	}
+	r := s.Registers[name]
+	if appending && r != nil {
+		if r.fsys != s.Tree.FS() {
+			s.ErrBuf = i18n.T("ui.register-other-fs")
+			return
+		}
+		for _, p := range srcs {
+			if !slices.Contains(r.Paths, p) {
+				r.Paths = append(r.Paths, p)
+			}
+		}
+	} else {
+		r = &Register{Paths: srcs, fsys: s.Tree.FS()}
+		s.Registers[name] = r
+	}
+	s.ErrBuf = fmt.Sprintf(i18n.T("ui.register-paths"), len(r.Paths), name)
+}
+
+// Copies or moves paths of the register into the current directory, under their names.
+// Moved paths are followed: the register holds their new locations.
+func (s *State) pasteRegister(kind string, name rune) tea.Cmd {
+	r := s.Registers[name]
+	if r == nil {
+		s.ErrBuf = fmt.Sprintf(i18n.T("ui.register-empty"), name)
+		return nil
+	}
+	if s.jobBusy() {
+		return nil
+	}
+	fsys := s.Tree.FS()
+	if r.fsys != fsys {
+		s.ErrBuf = i18n.T("ui.paste-other-fs")
+		return nil
+	}
+	srcs := r.Paths
+	dsts := make([]string, len(srcs))
+	for i, src := range srcs {
+		dsts[i] = filepath.Join(s.Tree.CurrentDir.Path, filepath.Base(src))
+	}
+	if err := checkTransfer(fsys, kind, srcs, dsts); err != nil {
+		s.ErrBuf = err.Error()
+		return nil
+	}
+	if kind == JobCopy {
+		return s.startTransfer(fsys, kind, srcs, dsts)
+	}
+	start := func() tea.Cmd {
+		for _, src := range srcs {
+			s.unselect(src)
+		}
+		r.Paths = dsts
+		return s.startTransfer(fsys, kind, srcs, dsts)
+	}
+	for _, src := range srcs {
+		if s.isProtected(src) {
+			return s.guard(src, start)
+		}
+	}
+	return start()
+}
+
+// Inserts paths of the register, quoted for the shell, at the input cursor.
+func (s *State) insertRegister(name rune) {
+	r := s.Registers[name]
+	if r == nil {
+		s.ErrBuf = fmt.Sprintf(i18n.T("ui.register-empty"), name)
+		return
+	}
+	quoted := make([]string, len(r.Paths))
+	for i, p := range r.Paths {
+		quoted[i] = shellQuote(p)
+	}
+	text := []rune(strings.Join(quoted, " "))
+	pos := min(max(s.InputPos, 0), len(s.InputBuf))
+	s.InputBuf = append(s.InputBuf[:pos], append(text, s.InputBuf[pos:]...)...)
+	s.InputPos = pos + len(text)
+}
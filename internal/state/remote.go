@@ -0,0 +1,53 @@
+package state
+
+import (
+	"slices"
+
+	"github.com/LeperGnome/bt/internal/i18n"
+)
+
+// Actions, that run local programs on paths or read the local disk directly. Remote trees refuse them,
+// so a remote path is never taken for a local one, that happens to have the same name.
+var localActions = []ActionID{
+	ActionEdit,
+	ActionOpen,
+	ActionOpenWith,
+	ActionShell,
+	ActionSubshell,
+	ActionSend,
+	ActionGrep,
+	ActionDirSize,
+	ActionPin,
+	ActionToggleDiff,
+	ActionToggleChurn,
+	ActionTimeTravel,
+	ActionRestore,
+	ActionDrives,
+	ActionArchive,
+	ActionExtract,
+	ActionExport,
+	ActionToss,
+	ActionBasket,
+	ActionChmod,
+	ActionChown,
+	ActionBulkRename,
+	ActionCleanup,
+	ActionCleanArtifacts,
+}
+
+// Reports whether action is refused in a remote tree, telling so.
+func (s *State) remoteRefused(action ActionID) bool {
+	if !slices.Contains(localActions, action) {
+		return false
+	}
+	return !s.localOnly()
+}
+
+// Reports whether the current tree is local. Otherwise tells, that it's not available.
+func (s *State) localOnly() bool {
+	if s.Tree.Local() {
+		return true
+	}
+	s.ErrBuf = i18n.T("ui.local-only")
+	return false
+}
@@ -0,0 +1,33 @@
+package state
+
+import (
+	"os"
+	"path/filepath"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// RevealRequest asks to select path, coming from an external tool (see bt -listen).
+type RevealRequest struct {
+	Path string
+}
+
+// Reveals path in the active tree. Path outside of the root makes its directory a new root.
+func (s *State) processRevealRequest(msg RevealRequest) tea.Cmd {
+	defer s.syncHistory()
+	defer s.syncPreview()
+	if !s.Tree.Contains(msg.Path) {
+		dir := msg.Path
+		if info, err := os.Stat(dir); err == nil && !info.IsDir() {
+			dir = filepath.Dir(dir)
+		}
+		if err := s.Tree.SetRoot(dir); err != nil {
+			s.ErrBuf = err.Error()
+			return nil
+		}
+	}
+	if err := s.Tree.Reveal(msg.Path); err != nil {
+		s.ErrBuf = err.Error()
+	}
+	return nil
+}
@@ -0,0 +1,100 @@
+package state
+
+import (
+	"fmt"
+	"slices"
+	"unicode"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/LeperGnome/bt/internal/i18n"
+	t "github.com/LeperGnome/bt/internal/tree"
+)
+
+// Starts type-ahead search of names in the shown tree.
+func (s *State) startSearch() {
+	s.searchFrom = s.Tree.GetSelectedChild()
+	s.Search = ""
+	s.setInput("")
+	s.OpBuf = SearchInput
+}
+
+// Cursor jumps to the first match while typing. Enter keeps the search for n / N,
+// esc brings the cursor back.
+func (s *State) processKeySearch(msg tea.KeyMsg) tea.Cmd {
+	switch msg.String() {
+	case "enter":
+		s.OpBuf = Noop
+		s.setInput("")
+		return nil
+	case "esc", "ctrl+c":
+		s.OpBuf = Noop
+		s.setInput("")
+		s.Search = ""
+		if s.searchFrom != nil {
+			s.Tree.Select(s.searchFrom)
+		}
+		return nil
+	}
+	s.processKeyAnyInput(msg)
+	s.Search = string(s.InputBuf)
+	if s.Search == "" {
+		return nil
+	}
+	nodes := s.Tree.Shown()
+	from := max(slices.Index(nodes, s.searchFrom), 0)
+	if n := s.nextMatch(nodes, from, 1); n != nil {
+		s.Tree.Select(n)
+	}
+	return nil
+}
+
+// Moves cursor to the next (step 1) or previous (step -1) match, wrapping around.
+func (s *State) searchNext(step int) {
+	if s.Search == "" {
+		return
+	}
+	nodes := s.Tree.Shown()
+	from := slices.Index(nodes, s.Tree.GetSelectedChild()) + step
+	if n := s.nextMatch(nodes, from, step); n != nil {
+		s.Tree.Select(n)
+		return
+	}
+	s.ErrBuf = fmt.Sprintf(i18n.T("ui.no-matches"), s.Search)
+}
+
+// Returns the first matching node, starting at index from and going in direction of step.
+func (s *State) nextMatch(nodes []*t.Node, from, step int) *t.Node {
+	for i := range nodes {
+		n := nodes[((from+i*step)%len(nodes)+len(nodes))%len(nodes)]
+		if start, _ := s.SearchMatch(n.Info.Name()); start >= 0 {
+			return n
+		}
+	}
+	return nil
+}
+
+// Returns rune range of the search query in name, -1 if there is no match.
+// Search ignores case, unless query has upper case letters.
+func (s *State) SearchMatch(name string) (int, int) {
+	if s.Search == "" {
+		return -1, -1
+	}
+	query := []rune(s.Search)
+	runes := []rune(name)
+	fold := !slices.ContainsFunc(query, unicode.IsUpper)
+	for start := 0; start+len(query) <= len(runes); start++ {
+		matched := true
+		for i, q := range query {
+			r := runes[start+i]
+			if r != q && !(fold && unicode.ToLower(r) == q) {
+				matched = false
+				break
+			}
+		}
+		if matched {
+			return start, start + len(query)
+		}
+	}
+	return -1, -1
+}
@@ -0,0 +1,66 @@
+package state
+
+import (
+	"cmp"
+	"slices"
+)
+
+// Adds selected child to the selection or removes it from there.
+// Newly selected paths are passed to SelectionSink, if it's set.
+func (s *State) toggleSelected() {
+	child := s.Tree.GetSelectedChild()
+	if child == nil {
+		return
+	}
+	if s.Selection[child.Path] {
+		s.unselect(child.Path)
+		return
+	}
+	s.Selection[child.Path] = true
+	s.selCount++
+	s.selOrder[child.Path] = s.selCount
+	if size, ok := s.DirSizes[child.Path]; ok {
+		s.selSizes[child.Path] = size
+	} else if !child.IsDir() {
+		s.selSizes[child.Path] = child.Info.Size()
+	}
+	if s.SelectionSink != nil {
+		if err := s.SelectionSink(child.Path); err != nil {
+			s.ErrBuf = err.Error()
+		}
+	}
+}
+
+// Returns selected paths in order.
+func (s *State) SelectedPaths() []string {
+	paths := make([]string, 0, len(s.Selection))
+	for p := range s.Selection {
+		paths = append(paths, p)
+	}
+	slices.Sort(paths)
+	return paths
+}
+
+// Returns selected paths in the order they were selected.
+func (s *State) PickedPaths() []string {
+	paths := s.SelectedPaths()
+	slices.SortStableFunc(paths, func(a, b string) int {
+		return cmp.Compare(s.selOrder[a], s.selOrder[b])
+	})
+	return paths
+}
+
+func (s *State) IsSelected(path string) bool {
+	return s.Selection[path]
+}
+
+// Returns total size of selected paths, as known when they were selected.
+// Directories count only, if their size was computed. With partial, some of them weren't.
+func (s *State) SelectionSize() (total int64, partial bool) {
+	for p := range s.Selection {
+		size, ok := s.selSizes[p]
+		total += size
+		partial = partial || !ok
+	}
+	return total, partial
+}
@@ -0,0 +1,144 @@
+package state
+
+import (
+	"context"
+	"errors"
+	"os/exec"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/LeperGnome/bt/internal/i18n"
+)
+
+// Asks for a program, that gets selected paths, or the child under cursor, as arguments.
+// The last sent command is offered again.
+func (s *State) promptSend() {
+	if len(s.shellTargets()) == 0 {
+		return
+	}
+	if !s.localOnly() {
+		return
+	}
+	s.OpBuf = SendInput
+	s.setInput(s.lastSend)
+}
+
+// Cycles configured commands with tab and runs the typed one on enter.
+func (s *State) processKeySendInput(msg tea.KeyMsg) tea.Cmd {
+	switch msg.String() {
+	case "tab":
+		s.cycleSendCommand()
+		return nil
+	case "enter":
+	default:
+		return s.processKeyAnyInput(msg)
+	}
+	input := strings.TrimSpace(string(s.InputBuf))
+	s.setInput("")
+	s.OpBuf = Noop
+	// leading ! runs program in the terminal, like in shell input
+	interactive := strings.HasPrefix(input, "!")
+	typed := strings.TrimSpace(strings.TrimPrefix(input, "!"))
+	paths := s.shellTargets()
+	if typed == "" || len(paths) == 0 {
+		return nil
+	}
+	args, err := sendArgs(typed, paths)
+	if err != nil {
+		s.ErrBuf = err.Error()
+		return nil
+	}
+	s.lastSend = input
+	if interactive {
+		c := exec.Command(args[0], args[1:]...)
+		c.Dir = s.Tree.CurrentDir.Path
+		return execInteractive(c)
+	}
+	return s.startCommand(typed, func(ctx context.Context) *exec.Cmd {
+		c := exec.CommandContext(ctx, args[0], args[1:]...)
+		c.Dir = s.Tree.CurrentDir.Path
+		return c
+	})
+}
+
+// Replaces input with the configured command after the typed one.
+func (s *State) cycleSendCommand() {
+	if len(s.SendCommands) == 0 {
+		return
+	}
+	next := 0
+	for i, c := range s.SendCommands {
+		if c == string(s.InputBuf) {
+			next = (i + 1) % len(s.SendCommands)
+		}
+	}
+	s.setInput(s.SendCommands[next])
+}
+
+// Returns arguments of the program: standalone {} is replaced with all paths, otherwise they're appended.
+// Paths are passed as they are, no shell is involved.
+func sendArgs(command string, paths []string) ([]string, error) {
+	words, err := splitWords(command)
+	if err != nil {
+		return nil, err
+	}
+	if len(words) == 0 {
+		return nil, errors.New(i18n.T("ui.send-no-program"))
+	}
+	args := make([]string, 0, len(words)+len(paths))
+	substituted := false
+	for _, w := range words {
+		if w == "{}" {
+			args = append(args, paths...)
+			substituted = true
+			continue
+		}
+		args = append(args, w)
+	}
+	if !substituted {
+		args = append(args, paths...)
+	}
+	return args, nil
+}
+
+// Splits command into words like a shell: single quotes keep everything, double quotes
+// and backslash escape spaces. Nothing is expanded.
+func splitWords(command string) ([]string, error) {
+	var words []string
+	var word strings.Builder
+	inWord := false
+	var quote rune
+	escaped := false
+	for _, r := range command {
+		switch {
+		case escaped:
+			word.WriteRune(r)
+			escaped = false
+		case quote == '\'' && r != '\'':
+			word.WriteRune(r)
+		case r == '\\' && quote != '\'':
+			escaped, inWord = true, true
+		case quote != 0 && r == quote:
+			quote = 0
+		case quote == 0 && (r == '\'' || r == '"'):
+			quote, inWord = r, true
+		case quote == 0 && (r == ' ' || r == '\t'):
+			if inWord {
+				words = append(words, word.String())
+				word.Reset()
+				inWord = false
+			}
+		default:
+			word.WriteRune(r)
+			inWord = true
+		}
+	}
+	if quote != 0 || escaped {
+		return nil, errors.New(i18n.T("ui.unfinished-quote"))
+	}
+	if inWord {
+		words = append(words, word.String())
+	}
+	return words, nil
+}
@@ -0,0 +1,130 @@
+package state
+
+import (
+	"path/filepath"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/LeperGnome/bt/internal/config"
+	"github.com/LeperGnome/bt/internal/persist"
+)
+
+const sessionSchema = 1
+
+// Session is the view of a tree, saved on exit and offered for restore on the next start in the same root.
+// Paths are relative to the root.
+type Session struct {
+	Expanded   []string `json:"expanded"`
+	Current    string   `json:"current"`
+	Selected   string   `json:"selected,omitempty"`
+	Filter     string   `json:"filter,omitempty"`
+	SplitRatio float64  `json:"split_ratio"`
+}
+
+func sessionFile() (*persist.File, error) {
+	dir, err := config.DataDir()
+	if err != nil {
+		return nil, err
+	}
+	return &persist.File{Path: filepath.Join(dir, "sessions.json"), Schema: sessionSchema}, nil
+}
+
+// Sessions by absolute tree root.
+func loadSessions() (map[string]Session, *persist.File, error) {
+	sessions := map[string]Session{}
+	f, err := sessionFile()
+	if err != nil {
+		return sessions, nil, err
+	}
+	if _, err := f.Load(&sessions); err != nil {
+		return map[string]Session{}, nil, err
+	}
+	return sessions, f, nil
+}
+
+// Saves view of the focused tree for its root.
+func (s *State) SaveSession() error {
+	if s.ephemeral {
+		return nil
+	}
+	root, err := filepath.Abs(s.Tree.Root.Path)
+	if err != nil {
+		return err
+	}
+	sessions, f, err := loadSessions()
+	if err != nil {
+		return err
+	}
+	rel := func(p string) string {
+		r, err := filepath.Rel(s.Tree.Root.Path, p)
+		if err != nil {
+			return p
+		}
+		return r
+	}
+	sess := Session{
+		Current:    rel(s.Tree.CurrentDir.Path),
+		Filter:     s.Tree.Filter(),
+		SplitRatio: s.SplitRatio,
+	}
+	for _, p := range s.Tree.ExpandedPaths() {
+		sess.Expanded = append(sess.Expanded, rel(p))
+	}
+	if selected := s.Tree.GetSelectedChild(); selected != nil {
+		sess.Selected = rel(selected.Path)
+	}
+	sessions[root] = sess
+	return f.Save(sessions)
+}
+
+// Offers to restore saved session, if there is one for the tree root.
+func (s *State) offerSession() error {
+	root, err := filepath.Abs(s.Tree.Root.Path)
+	if err != nil {
+		return err
+	}
+	sessions, _, err := loadSessions()
+	if sess, ok := sessions[root]; ok {
+		s.session = &sess
+		s.OpBuf = SessionRestore
+	}
+	return err
+}
+
+// Any key, other than y / n / esc, dismisses the offer and works as usual.
+func (s *State) processKeySessionRestore(msg tea.KeyMsg) tea.Cmd {
+	s.OpBuf = Noop
+	sess := s.session
+	s.session = nil
+	switch msg.String() {
+	case "y":
+		s.restoreSession(sess)
+	case "n", "esc":
+	default:
+		return s.processKeyDefault(msg)
+	}
+	return nil
+}
+
+// Restores as much of the session, as still exists on disk.
+func (s *State) restoreSession(sess *Session) {
+	abs := func(rel string) string {
+		return filepath.Join(s.Tree.Root.Path, rel)
+	}
+	for _, p := range sess.Expanded {
+		s.Tree.Reveal(abs(p)) // directory might be gone since then
+	}
+	target := sess.Selected
+	if target == "" {
+		target = sess.Current
+	}
+	if err := s.Tree.Reveal(abs(target)); err != nil {
+		s.Tree.Reveal(abs(sess.Current))
+	}
+	if err := s.Tree.SetFilter(sess.Filter); err != nil {
+		s.ErrBuf = err.Error()
+	}
+	if sess.SplitRatio != 0 {
+		s.SetSplitRatio(sess.SplitRatio)
+	}
+}
@@ -0,0 +1,238 @@
+package state
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/exec"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/LeperGnome/bt/internal/i18n"
+)
+
+// Output beyond that is dropped.
+const ShellOutputLimit = 1 << 20
+
+// Shell command, run from the current directory, with its output.
+type ShellRun struct {
+	Command string // as typed, before placeholders are substituted
+	Output  []string
+	Running bool
+	Exit    int // exit code, -1 - killed or failed to start
+	Offset  int // first output line shown
+	id      int
+	cancel  func()
+}
+
+// Sent, when command, started with ShellRun, exits.
+type ShellFinished struct {
+	id     int
+	Output string
+	Exit   int
+	Err    error
+}
+
+func (s *State) processKeyShellInput(msg tea.KeyMsg) tea.Cmd {
+	if s.registerInput {
+		s.registerInput = false
+		if name, _, ok := registerName(msg); ok {
+			s.insertRegister(name)
+		}
+		return nil
+	}
+	switch msg.String() {
+	case "ctrl+r":
+		s.registerInput = true
+		return nil
+	case "enter":
+		input := strings.TrimSpace(string(s.InputBuf))
+		s.setInput("")
+		s.OpBuf = Noop
+		// leading ! runs command in the terminal, for interactive programs
+		interactive := strings.HasPrefix(input, "!")
+		typed := strings.TrimSpace(strings.TrimPrefix(input, "!"))
+		if typed == "" {
+			return nil
+		}
+		command, err := s.expandShell(typed)
+		if err != nil {
+			s.ErrBuf = err.Error()
+			return nil
+		}
+		if interactive {
+			return execInteractive(s.shellCommand(context.Background(), command))
+		}
+		return s.startShell(typed, command)
+	default:
+		return s.processKeyAnyInput(msg)
+	}
+}
+
+// Substitutes placeholders: %s - selected paths (or the child under cursor), %m - marked path,
+// %d - current directory, %% - percent sign. Paths are quoted for the shell.
+func (s *State) expandShell(command string) (string, error) {
+	var b strings.Builder
+	for i := 0; i < len(command); i++ {
+		if command[i] != '%' || i == len(command)-1 {
+			b.WriteByte(command[i])
+			continue
+		}
+		i++
+		switch command[i] {
+		case 's':
+			paths := s.shellTargets()
+			if len(paths) == 0 {
+				return "", errors.New(i18n.T("ui.shell-no-selected"))
+			}
+			for j, p := range paths {
+				if j > 0 {
+					b.WriteByte(' ')
+				}
+				b.WriteString(shellQuote(p))
+			}
+		case 'm':
+			marked := s.MarkedNode()
+			if marked == nil {
+				return "", errors.New(i18n.T("ui.shell-no-marked"))
+			}
+			b.WriteString(shellQuote(marked.Path))
+		case 'd':
+			b.WriteString(shellQuote(s.Tree.CurrentDir.Path))
+		case '%':
+			b.WriteByte('%')
+		default:
+			b.WriteByte('%')
+			b.WriteByte(command[i])
+		}
+	}
+	return b.String(), nil
+}
+
+// Returns selected paths in order, or the child under cursor, when nothing is selected.
+func (s *State) shellTargets() []string {
+	if len(s.Selection) > 0 {
+		return s.SelectedPaths()
+	}
+	if child := s.Tree.GetSelectedChild(); child != nil {
+		return []string{child.Path}
+	}
+	return nil
+}
+
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// Returns command, run by user's shell in the current directory.
+func (s *State) shellCommand(ctx context.Context, command string) *exec.Cmd {
+	shell := os.Getenv("SHELL")
+	if shell == "" {
+		shell = "sh"
+	}
+	c := exec.CommandContext(ctx, shell, "-c", command)
+	c.Dir = s.Tree.CurrentDir.Path
+	return c
+}
+
+// Runs command in background, collecting its output for the shell pane.
+func (s *State) startShell(typed, command string) tea.Cmd {
+	return s.startCommand(typed, func(ctx context.Context) *exec.Cmd { return s.shellCommand(ctx, command) })
+}
+
+// Runs command, made for the context, in background, collecting its output for the shell pane.
+func (s *State) startCommand(typed string, command func(ctx context.Context) *exec.Cmd) tea.Cmd {
+	s.closeShell()
+	ctx, cancel := context.WithCancel(context.Background())
+	s.shellID++
+	s.Shell = &ShellRun{Command: typed, Running: true, Exit: -1, id: s.shellID, cancel: cancel}
+	s.OpBuf = ShellOutput
+	c := command(ctx)
+	id := s.shellID
+	return func() tea.Msg {
+		out := &limitedBuffer{limit: ShellOutputLimit}
+		c.Stdout, c.Stderr = out, out
+		err := c.Run()
+		var exitErr *exec.ExitError
+		switch {
+		case err == nil:
+			return ShellFinished{id: id, Output: out.String()}
+		case errors.As(err, &exitErr) && exitErr.ExitCode() >= 0:
+			return ShellFinished{id: id, Output: out.String(), Exit: exitErr.ExitCode()}
+		}
+		return ShellFinished{id: id, Output: out.String(), Exit: -1, Err: err}
+	}
+}
+
+func (s *State) processShellFinished(msg ShellFinished) tea.Cmd {
+	r := s.Shell
+	if r == nil || r.id != msg.id {
+		return nil
+	}
+	r.Running = false
+	r.Exit = msg.Exit
+	slog.Debug("shell done", "command", r.Command, "exit", r.Exit, "err", msg.Err)
+	r.Output = strings.Split(strings.TrimRight(msg.Output, "\n"), "\n")
+	if msg.Err != nil && !errors.Is(msg.Err, context.Canceled) {
+		s.ErrBuf = msg.Err.Error()
+	}
+	return nil
+}
+
+func (s *State) processKeyShellOutput(msg tea.KeyMsg) tea.Cmd {
+	r := s.Shell
+	last := max(len(r.Output)-1, 0)
+	switch msg.String() {
+	case "j", "down":
+		r.Offset = min(r.Offset+1, last)
+	case "k", "up":
+		r.Offset = max(r.Offset-1, 0)
+	case "ctrl+d", "pgdown":
+		r.Offset = min(r.Offset+s.previewPage(), last)
+	case "ctrl+u", "pgup":
+		r.Offset = max(r.Offset-s.previewPage(), 0)
+	case "g":
+		r.Offset = 0
+	case "G":
+		r.Offset = last
+	case "esc", "q":
+		s.OpBuf = Noop
+		s.closeShell()
+	}
+	return nil
+}
+
+// Closes shell pane, killing the command, if it's still running.
+func (s *State) closeShell() {
+	if s.Shell != nil {
+		s.Shell.cancel()
+	}
+	s.Shell = nil
+}
+
+// Keeps the first limit bytes, written to it, and notes, that the rest is dropped.
+type limitedBuffer struct {
+	bytes.Buffer
+	limit     int
+	truncated bool
+}
+
+func (b *limitedBuffer) Write(p []byte) (int, error) {
+	if room := b.limit - b.Len(); room < len(p) {
+		b.truncated = true
+		b.Buffer.Write(p[:max(room, 0)])
+		return len(p), nil
+	}
+	return b.Buffer.Write(p)
+}
+
+func (b *limitedBuffer) String() string {
+	if b.truncated {
+		return b.Buffer.String() + fmt.Sprintf(i18n.T("ui.shell-output-cut"), b.limit)
+	}
+	return b.Buffer.String()
+}
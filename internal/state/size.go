@@ -0,0 +1,84 @@
+package state
+
+import (
+	"context"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	t "github.com/LeperGnome/bt/internal/tree"
+	"github.com/LeperGnome/bt/pkg/paths"
+)
+
+type DirSizeResult struct {
+	id   int
+	Path string
+	Size int64
+	Err  error
+}
+
+// Starts computing recursive size of the selected directory in background.
+func (s *State) computeSelectedSize() tea.Cmd {
+	selected := s.Tree.GetSelectedChild()
+	if selected == nil || !selected.IsDir() {
+		return nil
+	}
+	if _, ok := s.DirSizes[selected.Path]; ok {
+		return nil
+	}
+	s.cancelSizing()
+	ctx, cancel := context.WithCancel(context.Background())
+	s.sizingID++
+	s.sizingPath = selected.Path
+	s.sizingCancel = cancel
+	id, path := s.sizingID, selected.Path
+	return func() tea.Msg {
+		size, err := t.DirSize(ctx, path)
+		return DirSizeResult{id: id, Path: path, Size: size, Err: err}
+	}
+}
+
+// Reports whether size of path is being computed.
+func (s *State) IsSizing(path string) bool {
+	return s.sizingPath == path
+}
+
+func (s *State) cancelSizing() {
+	if s.sizingCancel != nil {
+		s.sizingCancel()
+	}
+	s.sizingPath = ""
+	s.sizingCancel = nil
+}
+
+// Cancels sizing, when user moved away from the directory.
+func (s *State) syncSizing() {
+	if s.sizingPath == "" {
+		return
+	}
+	if selected := s.Tree.GetSelectedChild(); selected == nil || selected.Path != s.sizingPath {
+		s.cancelSizing()
+	}
+}
+
+func (s *State) processDirSizeResult(msg DirSizeResult) tea.Cmd {
+	if msg.id != s.sizingID || s.sizingPath == "" {
+		return nil // cancelled
+	}
+	s.sizingPath = ""
+	s.sizingCancel = nil
+	if msg.Err != nil {
+		s.ErrBuf = msg.Err.Error()
+		return nil
+	}
+	s.DirSizes[msg.Path] = msg.Size
+	return nil
+}
+
+// Drops cached sizes of all directories, containing changed path.
+func (s *State) invalidateSizes(changed string) {
+	for dir := range s.DirSizes {
+		if paths.Within(dir, changed) {
+			delete(s.DirSizes, dir)
+		}
+	}
+}
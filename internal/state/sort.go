@@ -0,0 +1,13 @@
+package state
+
+import (
+	t "github.com/LeperGnome/bt/internal/tree"
+)
+
+// Changes order of names in all trees, loaded directories are re-sorted.
+func (s *State) SetNameOrder(o t.NameOrder) {
+	s.NameOrder = o
+	for _, tree := range s.allTrees() {
+		tree.SetSorting(o.Sorting())
+	}
+}
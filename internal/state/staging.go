@@ -0,0 +1,84 @@
+package state
+
+import (
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/LeperGnome/bt/internal/i18n"
+)
+
+// Opens the staging area: selected paths from all directories, reviewed before acting on all of them.
+func (s *State) openStaging() {
+	if len(s.Selection) == 0 {
+		s.ErrBuf = i18n.T("ui.nothing-staged")
+		return
+	}
+	s.StagingCursor = max(min(s.StagingCursor, len(s.Selection)-1), 0)
+	s.OpBuf = StagingView
+}
+
+// Moves through staged paths, unstages them, or starts an operation on all of them.
+func (s *State) processKeyStaging(msg tea.KeyMsg) tea.Cmd {
+	paths := s.SelectedPaths()
+	switch msg.String() {
+	case "j", "down":
+		s.StagingCursor = min(s.StagingCursor+1, max(len(paths)-1, 0))
+	case "k", "up":
+		s.StagingCursor = max(s.StagingCursor-1, 0)
+	case "u", " ":
+		if s.StagingCursor < len(paths) {
+			s.unselect(paths[s.StagingCursor])
+		}
+		s.StagingCursor = max(min(s.StagingCursor, len(s.Selection)-1), 0)
+		if len(s.Selection) == 0 {
+			s.OpBuf = Noop
+		}
+	case "x":
+		for _, p := range paths {
+			s.unselect(p)
+		}
+		s.OpBuf = Noop
+	case "enter":
+		s.OpBuf = Noop
+		if s.StagingCursor < len(paths) {
+			return s.processRevealRequest(RevealRequest{Path: paths[s.StagingCursor]})
+		}
+	case "c":
+		s.OpBuf = Noop
+		if !s.refused(ActionCopyTo) {
+			s.promptTransfer(CopyTo)
+		}
+	case "m":
+		s.OpBuf = Noop
+		if !s.refused(ActionMoveTo) {
+			s.promptTransfer(MoveTo)
+		}
+	case "D":
+		s.OpBuf = Noop
+		if !s.refused(ActionDelete) {
+			return s.deleteSelected()
+		}
+	case "&":
+		s.OpBuf = Noop
+		s.promptSend()
+	case "!":
+		if s.localOnly() {
+			s.setInput("")
+			s.OpBuf = ShellInput
+		}
+	case "esc", "q":
+		s.OpBuf = Noop
+	}
+	return nil
+}
+
+func (s *State) unselect(path string) {
+	delete(s.Selection, path)
+	delete(s.selSizes, path)
+	delete(s.selOrder, path)
+}
+
+// Returns size of the selected path, as known when it was selected.
+func (s *State) SelectedSize(path string) (int64, bool) {
+	size, ok := s.selSizes[path]
+	return size, ok
+}
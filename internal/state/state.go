@@ -1,9 +1,11 @@
 package state
 
 import (
-	"os"
-	"os/exec"
-
+	"github.com/LeperGnome/bt/internal/bookmarks"
+	"github.com/LeperGnome/bt/internal/clipboard"
+	"github.com/LeperGnome/bt/internal/config"
+	"github.com/LeperGnome/bt/internal/i18n"
+	"github.com/LeperGnome/bt/internal/throughput"
 	t "github.com/LeperGnome/bt/internal/tree"
 	tea "github.com/charmbracelet/bubbletea"
 )
@@ -15,29 +17,109 @@ const (
 	Move
 	Copy
 	Delete
-	Go
 	Insert
 	InsertFile
 	InsertDir
 	Rename
+	BookmarkSet
+	BookmarkJump
+	Cleanup
+	CleanupConfirm
+	CleanArtifacts
+	AnchorSet
+	AnchorNote
+	Chmod
+	Chown
+	BulkRename
+	BulkRenameConfirm
+	SnapshotPick
+	Yank
+	GrepInput
+	GrepResultsView
+	FilterInput
+	BasketView
+	BasketConfirm
+	RecentPick
+	SessionRestore
+	PasteConflict
+	GuardConfirm
+	ShellInput
+	ShellOutput
+	SearchInput
+	DeleteConfirm
+	MessageLog
+	CopyTo
+	MoveTo
+	ArchiveName
+	ExtractTo
+	DrivePick
+	JournalView
+	RegisterPick
+	RegisterUse
+	OpenWith
+	SendInput
+	ExportName
+	StagingView
 )
 
 func (o Operation) Repr() string {
-	return []string{
+	key := []string{
 		"",
-		"moving",
-		"copying",
-		"confirm removing (y/n) of",
-		"g",
-		"create new (f)ile/(d)irectory",
-		"enter new file name:",
-		"enter new directory name:",
-		"renaming",
+		"op.moving",
+		"op.copying",
+		"op.confirm-delete",
+		"op.insert",
+		"op.insert-file",
+		"op.insert-dir",
+		"op.renaming",
+		"op.bookmark-set",
+		"op.bookmark-jump",
+		"op.cleanup",
+		"op.confirm-cleanup",
+		"op.confirm-clean-artifacts",
+		"op.anchor-set",
+		"op.anchor-note",
+		"op.chmod",
+		"op.chown",
+		"op.bulk-rename",
+		"op.confirm-bulk-rename",
+		"op.snapshot-pick",
+		"op.yank",
+		"op.grep",
+		"op.grep-results",
+		"op.filter",
+		"op.basket",
+		"op.confirm-basket",
+		"op.recent",
+		"op.session-restore",
+		"op.paste-conflict",
+		"op.guard-confirm",
+		"op.shell",
+		"op.shell-output",
+		"op.search",
+		"op.confirm-delete-listed",
+		"op.messages",
+		"op.copy-to",
+		"op.move-to",
+		"op.archive",
+		"op.extract",
+		"op.drives",
+		"op.journal",
+		"op.register-pick",
+		"op.register-use",
+		"op.open-with",
+		"op.send",
+		"op.export",
+		"op.staging",
 	}[o]
+	if key == "" {
+		return ""
+	}
+	return i18n.T(key)
 }
 func (o Operation) IsInput() bool {
 	switch o {
-	case InsertDir, InsertFile, Rename:
+	case InsertDir, InsertFile, Rename, AnchorNote, Chmod, Chown, BulkRename, GrepInput, FilterInput, GuardConfirm, ShellInput, SearchInput, CopyTo, MoveTo, ArchiveName, ExtractTo, OpenWith, SendInput, ExportName:
 		return true
 	default:
 		return false
@@ -45,37 +127,261 @@ func (o Operation) IsInput() bool {
 }
 
 type State struct {
-	Tree          *t.Tree
-	OpBuf         Operation
-	InputBuf      []rune
-	ErrBuf        string
-	NodeChanges   <-chan t.NodeChange
-	HelpToggle    bool
-	PreviewToggle bool
+	Tree           *t.Tree    // focused tree
+	Panes          [2]*t.Tree // panes of the active tab
+	Tabs           []*Tab
+	ActiveTab      int
+	DualPane       bool
+	ActivePane     int
+	Focus          Pane
+	PreviewOffset  int     // first preview line shown
+	SplitRatio     float64 // share of width, taken by the tree, when right pane is shown
+	HelpOffset     int     // first help line shown
+	TreeScroll     int     // first tree column shown
+	ChurnToggle    bool
+	Churn          *ChurnReport     // nil Files - still computing
+	DirSizes       map[string]int64 // recursive directory sizes by path
+	Cleanup        *CleanupSession  // nil - assistant is closed
+	Artifacts      []string         // build artifacts, pending removal
+	Selection      map[string]bool  // selected paths
+	StagingCursor  int              // in selected paths, reviewed in the staging area
+	Basket         Basket           // paths, waiting for deletion
+	BulkRename     *BulkRenameSession
+	TimeTravel     *TimeTravelSession
+	History        *History // of the active tab
+	Recent         []string // recently visited directories, most recent first
+	RecentCursor   int
+	Drives         []string // roots of drives, offered by the drive switcher
+	DriveCursor    int
+	Messages       []Message          // errors and notices, oldest first
+	MessageOffset  int                // newest messages, scrolled past in the log
+	Journal        []JournalEntry     // executed file operations, oldest first
+	JournalPos     int                // operations from this one on are undone
+	JournalOffset  int                // newest operations, scrolled past in the journal view
+	Registers      map[rune]*Register // named registers of yanked paths
+	Grep           *GrepSession
+	Shell          *ShellRun   // output of the last shell command, nil - pane is closed
+	Compare        *Comparison // diff of marked and selected files, nil - pane is closed
+	Tail           *TailView   // followed file, nil - pane is closed
+	Checksums      *Checksums  // of a file, shown while it's selected
+	Job            *FileJob    // running file operation
+	Deletion       *DeletePlan // directory or selection, waiting for confirmation of removal
+	OpBuf          Operation
+	InputBuf       []rune
+	InputPos       int    // cursor in InputBuf, runes before it
+	InputSelected  bool   // whole input is selected, typing replaces it
+	Search         string // name search query, matches are highlighted and cycled with n / N
+	ErrBuf         string
+	NodeChanges    <-chan t.NodeChange
+	HelpToggle     bool
+	PreviewToggle  bool
+	MarkdownRaw    bool   // show markdown files as plain text
+	LineNumbers    bool   // show line numbers of text files in preview
+	WrapLines      bool   // wrap long lines in preview instead of cutting them
+	DiffToggle     bool   // show diff against git HEAD for modified files
+	PinnedPath     string // file, shown in preview regardless of selection
+	PinTransient   bool   // show selected file below the pinned one
+	DetailToggle   bool   // show permissions, owner, size and mtime columns in trees
+	Columns        bool   // list current directory in columns, when the window is wide, see ColumnView
+	CdOnExit       bool   // current directory should be reported to the shell on exit
+	Pick           bool   // Enter selects files instead of opening them, selection is printed on exit
+	Keymap         Keymap
+	Open           config.Open        // Enter behavior
+	Protected      []string           // paths, that need typed confirmation to be deleted or moved
+	ReadOnly       bool               // no operations, changing files, see readonly.go
+	Previewers     []config.Previewer // how files are previewed, see preview.Make
+	PreviewLimit   int64              // bytes, read for preview at once, 0 - DefaultPreviewLimit
+	SendCommands   []string           // offered by tab, when sending paths to a program
+	Hooks          config.Hooks       // commands, run on events
+	NameOrder      t.NameOrder        // order of names in all trees, see SetNameOrder
+	Bookmarks      *bookmarks.Store
+	Throughput     *throughput.Store // transfer rates by destination filesystem
+	Clipboard      clipboard.Clipboard
+	SelectionSink  func(path string) error // receives newly selected paths, nil - not exported
+	nodeChanges    chan t.NodeChange
+	ephemeral      bool // bt's data is neither read nor written, see InitStateEphemeral
+	previewPath    string
+	anchorKey      string // key of anchor, waiting for a note
+	previewMem     previewCache
+	pinnedMem      previewCache
+	dirMem         dirListingCache
+	windowHeight   int
+	windowWidth    int
+	sizingID       int
+	commandOutputs map[string]commandOutput // of preview commands by path
+	selSizes       map[string]int64         // of selected paths, see SelectionSize
+	selOrder       map[string]int           // when paths were selected, see PickedPaths
+	selCount       int
+	grepID         int
+	shellID        int
+	deleteID       int
+	count          int      // typed before an action, 0 - none
+	chord          []string // keys of a chord, typed so far
+	tailID         int
+	checksumID     int
+	reportedErr    string   // error line, already logged
+	completions    []string // offered by tab in destination input
+	filterBefore   string   // restored, if filter input is cancelled
+	searchFrom     *t.Node  // selected before search, restored, if search is cancelled
+	session        *Session // saved session, offered for restore
+	jobID          int
+	conflict       *pasteConflict // paste, waiting for a decision
+	onConflict     string         // decision for all following conflicts, empty - ask
+	guardPath      string         // protected path, waiting for a typed confirmation
+	register       rune           // picked register, waiting for what to do with it
+	registerAppend bool           // picked by uppercase name, yanked paths are appended
+	registerInput  bool           // ctrl+r in shell input, waiting for the register name
+	lastSend       string         // command, paths were sent to last time
+	guarded        func() tea.Cmd // action on guardPath
+	hookedDir      string         // current directory, hooks were run for
+	hookedPath     string         // selected child, hooks were run for
+	hookCancel     func()         // stops the running select hook
+	diffCache      diffCache
+	sizingPath     string
+	sizingCancel   func()
 }
 
 func InitState(root string) (*State, error) {
-	tree, ncc, err := t.InitTree(root, nil)
+	return InitStateFS(t.OS, root)
+}
+
+// Initializes state with tree, read from fsys, e.g. an in-memory file system in tests.
+func InitStateFS(fsys t.FS, root string) (*State, error) {
+	s, err := initState(fsys, root)
 	if err != nil {
 		return nil, err
 	}
-	return &State{
-		Tree:        tree,
-		OpBuf:       Noop,
-		InputBuf:    []rune{},
-		NodeChanges: ncc,
-	}, nil
+	s.Bookmarks, err = loadBookmarks()
+	if err != nil {
+		s.ErrBuf = err.Error()
+	}
+	s.Throughput, err = loadThroughput()
+	if err != nil {
+		s.ErrBuf = err.Error()
+	}
+	if err := s.offerSession(); err != nil {
+		s.ErrBuf = err.Error()
+	}
+	return s, nil
 }
 
-func (s *State) ProcessNodeChange(nodeChange t.NodeChange) tea.Cmd {
-	err := s.Tree.RefreshNodeParentByPath(nodeChange.Path)
+// Initializes state, that neither reads nor writes bt's data directory: bookmarks and
+// job throughput are kept in memory, saved session isn't offered and SaveSession does nothing.
+func InitStateEphemeral(fsys t.FS, root string) (*State, error) {
+	s, err := initState(fsys, root)
 	if err != nil {
-		s.ErrBuf = err.Error()
+		return nil, err
+	}
+	s.ephemeral = true
+	if s.Bookmarks, err = bookmarks.Load(""); err != nil {
+		return nil, err
+	}
+	if s.Throughput, err = throughput.Load(""); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+func initState(fsys t.FS, root string) (*State, error) {
+	tree, ncc, err := t.InitTreeFS(fsys, root, nil)
+	if err != nil {
+		return nil, err
+	}
+	changes := make(chan t.NodeChange)
+	s := &State{
+		Tree:           tree,
+		Panes:          [2]*t.Tree{tree, nil},
+		Tabs:           []*Tab{{Panes: [2]*t.Tree{tree, nil}}},
+		OpBuf:          Noop,
+		InputBuf:       []rune{},
+		NodeChanges:    changes,
+		Keymap:         DefaultKeymap,
+		SplitRatio:     DefaultSplitRatio,
+		Clipboard:      clipboard.Default(),
+		DirSizes:       map[string]int64{},
+		Selection:      map[string]bool{},
+		selSizes:       map[string]int64{},
+		commandOutputs: map[string]commandOutput{},
+		selOrder:       map[string]int{},
+		nodeChanges:    changes,
+	}
+	s.watchTree(ncc)
+	s.syncHistory()
+	s.syncHooks() // hooks aren't configured yet, starting state is only remembered
+	s.addRecent(tree.CurrentDir.Path)
+	return s, nil
+}
+
+func (s *State) ProcessNodeChange(nodeChange t.NodeChange) tea.Cmd {
+	return tea.Batch(s.processNodeChange(nodeChange), s.LoadPreview())
+}
+
+func (s *State) processNodeChange(nodeChange t.NodeChange) tea.Cmd {
+	defer s.syncMessages()
+	s.invalidateSizes(nodeChange.Path)
+	s.invalidatePreview(nodeChange.Path)
+	for _, p := range s.allTrees() {
+		refresh := p.RefreshNodeParentByPath
+		if nodeChange.Modified {
+			refresh = p.RefreshNodeInfo
+		}
+		if err := refresh(nodeChange.Path); err != nil {
+			s.ErrBuf = err.Error()
+		}
+	}
+	return nil
+}
+
+// Handles results of background commands, started by state.
+func (s *State) ProcessMsg(msg tea.Msg) tea.Cmd {
+	return tea.Batch(s.processMsg(msg), s.LoadPreview())
+}
+
+func (s *State) processMsg(msg tea.Msg) tea.Cmd {
+	defer s.syncMessages()
+	switch msg := msg.(type) {
+	case ExternalCommandFinished:
+		return s.processExternalCommandFinished(msg)
+	case ChurnReport:
+		return s.processChurnReport(msg)
+	case DirSizeResult:
+		return s.processDirSizeResult(msg)
+	case CleanupReport:
+		return s.processCleanupReport(msg)
+	case BulkRenameEdited:
+		return s.processBulkRenameEdited(msg)
+	case SnapshotList:
+		return s.processSnapshotList(msg)
+	case GrepResults:
+		return s.processGrepResults(msg)
+	case RevealRequest:
+		return s.processRevealRequest(msg)
+	case FileJobProgress:
+		return s.processFileJobProgress(msg)
+	case ShellFinished:
+		return s.processShellFinished(msg)
+	case DeleteCounted:
+		return s.processDeleteCounted(msg)
+	case TailRead:
+		return s.processTailRead(msg)
+	case ChecksumsDone:
+		return s.processChecksumsDone(msg)
+	case PreviewCommandDone:
+		return s.processPreviewCommandDone(msg)
 	}
 	return nil
 }
 
 func (s *State) ProcessKey(msg tea.KeyMsg) tea.Cmd {
+	return tea.Batch(s.processKey(msg), s.LoadPreview())
+}
+
+func (s *State) processKey(msg tea.KeyMsg) tea.Cmd {
+	defer s.syncMessages()
+	defer s.syncHooks()
+	defer s.syncHistory()
+	defer s.syncSizing()
+	defer s.syncPreview()
 	switch s.OpBuf {
 	case Noop:
 		return s.processKeyDefault(msg)
@@ -85,8 +391,6 @@ func (s *State) ProcessKey(msg tea.KeyMsg) tea.Cmd {
 		return s.processKeyDelete(msg)
 	case Copy:
 		return s.processKeyCopy(msg)
-	case Go:
-		return s.processKeyGo(msg)
 	case Insert:
 		return s.processKeyInsert(msg)
 	case InsertFile:
@@ -95,6 +399,87 @@ func (s *State) ProcessKey(msg tea.KeyMsg) tea.Cmd {
 		return s.processKeyInsertDir(msg)
 	case Rename:
 		return s.processKeyRename(msg)
+	case BookmarkSet:
+		return s.processKeyBookmarkSet(msg)
+	case BookmarkJump:
+		return s.processKeyBookmarkJump(msg)
+	case Cleanup:
+		return s.processKeyCleanup(msg)
+	case CleanupConfirm:
+		return s.processKeyCleanupConfirm(msg)
+	case CleanArtifacts:
+		return s.processKeyCleanArtifacts(msg)
+	case AnchorSet:
+		return s.processKeyAnchorSet(msg)
+	case AnchorNote:
+		return s.processKeyAnchorNote(msg)
+	case Chmod:
+		return s.processKeyChmod(msg)
+	case Chown:
+		return s.processKeyChown(msg)
+	case BulkRename:
+		return s.processKeyBulkRename(msg)
+	case BulkRenameConfirm:
+		return s.processKeyBulkRenameConfirm(msg)
+	case SnapshotPick:
+		return s.processKeySnapshotPick(msg)
+	case Yank:
+		return s.processKeyYank(msg)
+	case GrepInput:
+		return s.processKeyGrepInput(msg)
+	case GrepResultsView:
+		return s.processKeyGrepResults(msg)
+	case FilterInput:
+		return s.processKeyFilter(msg)
+	case BasketView:
+		return s.processKeyBasket(msg)
+	case BasketConfirm:
+		return s.processKeyBasketConfirm(msg)
+	case RecentPick:
+		return s.processKeyRecentPick(msg)
+	case SessionRestore:
+		return s.processKeySessionRestore(msg)
+	case PasteConflict:
+		return s.processKeyPasteConflict(msg)
+	case GuardConfirm:
+		return s.processKeyGuardConfirm(msg)
+	case ShellInput:
+		return s.processKeyShellInput(msg)
+	case ShellOutput:
+		return s.processKeyShellOutput(msg)
+	case SearchInput:
+		return s.processKeySearch(msg)
+	case DeleteConfirm:
+		return s.processKeyDeleteConfirm(msg)
+	case MessageLog:
+		return s.processKeyMessages(msg)
+	case CopyTo:
+		return s.processKeyPathInput(msg, func(dst string) tea.Cmd { return s.transfer(JobCopy, dst) })
+	case MoveTo:
+		return s.processKeyPathInput(msg, func(dst string) tea.Cmd { return s.transfer(JobMove, dst) })
+	case ArchiveName:
+		return s.processKeyPathInput(msg, s.archive)
+	case ExtractTo:
+		return s.processKeyPathInput(msg, s.extract)
+	case DrivePick:
+		return s.processKeyDrivePick(msg)
+	case JournalView:
+		return s.processKeyJournal(msg)
+	case RegisterPick:
+		return s.processKeyRegisterPick(msg)
+	case RegisterUse:
+		return s.processKeyRegisterUse(msg)
+	case OpenWith:
+		return s.processKeyOpenWith(msg)
+	case SendInput:
+		return s.processKeySendInput(msg)
+	case StagingView:
+		return s.processKeyStaging(msg)
+	case ExportName:
+		return s.processKeyPathInput(msg, func(typed string) tea.Cmd {
+			s.exportTree(typed)
+			return nil
+		})
 	default:
 		return s.processKeyDefault(msg)
 	}
@@ -102,12 +487,16 @@ func (s *State) ProcessKey(msg tea.KeyMsg) tea.Cmd {
 func (s *State) processKeyRename(msg tea.KeyMsg) tea.Cmd {
 	switch msg.String() {
 	case "enter":
-		err := s.Tree.RenameMarked(string(s.InputBuf))
-		if err != nil {
-			s.ErrBuf = err.Error()
+		if marked := s.Tree.Marked; marked != nil {
+			old, name := marked.Path, string(s.InputBuf)
+			if err := s.Tree.RenameMarked(name); err != nil {
+				s.ErrBuf = err.Error()
+			} else {
+				s.recordRename(old, name)
+			}
 		}
 		s.OpBuf = Noop
-		s.InputBuf = []rune{}
+		s.setInput("")
 	default:
 		return s.processKeyAnyInput(msg)
 	}
@@ -128,12 +517,9 @@ func (s *State) processKeyInsert(msg tea.KeyMsg) tea.Cmd {
 func (s *State) processKeyInsertFile(msg tea.KeyMsg) tea.Cmd {
 	switch msg.String() {
 	case "enter":
-		err := s.Tree.CreateFileInCurrent(string(s.InputBuf))
-		if err != nil {
-			s.ErrBuf = err.Error()
-		}
+		s.recordCreated(s.Tree.CreateFileInCurrent(string(s.InputBuf)))
 		s.OpBuf = Noop
-		s.InputBuf = []rune{}
+		s.setInput("")
 	default:
 		return s.processKeyAnyInput(msg)
 	}
@@ -142,53 +528,40 @@ func (s *State) processKeyInsertFile(msg tea.KeyMsg) tea.Cmd {
 func (s *State) processKeyInsertDir(msg tea.KeyMsg) tea.Cmd {
 	switch msg.String() {
 	case "enter":
-		err := s.Tree.CreateDirectoryInCurrent(string(s.InputBuf))
-		if err != nil {
-			s.ErrBuf = err.Error()
-		}
+		s.recordCreated(s.Tree.CreateDirectoryInCurrent(string(s.InputBuf)))
 		s.OpBuf = Noop
-		s.InputBuf = []rune{}
+		s.setInput("")
 	default:
 		return s.processKeyAnyInput(msg)
 	}
 	return nil
 }
 func (s *State) processKeyAnyInput(msg tea.KeyMsg) tea.Cmd {
-	// TODO: better input handling? cursor?
 	switch msg.String() {
 	case "ctrl+c", "esc":
 		s.OpBuf = Noop
-		s.InputBuf = []rune{}
+		s.setInput("")
 		s.Tree.DropMark()
-	case "backspace":
-		if l := len(s.InputBuf); l > 0 {
-			s.InputBuf = s.InputBuf[:l-1]
-		}
 	default:
-		s.InputBuf = append(s.InputBuf, msg.Runes...)
-	}
-	return nil
-}
-func (s *State) processKeyGo(msg tea.KeyMsg) tea.Cmd {
-	switch msg.String() {
-	case "g":
-		s.OpBuf = Noop
-		s.Tree.CurrentDir.SelectFirst()
-	default:
-		s.OpBuf = Noop
-		return s.processKeyDefault(msg)
+		s.editInput(msg)
 	}
 	return nil
 }
 func (s *State) processKeyDelete(msg tea.KeyMsg) tea.Cmd {
 	switch msg.String() {
 	case "y":
-		err := s.Tree.DeleteMarked()
-		if err != nil {
-			s.ErrBuf = err.Error()
+		s.OpBuf = Noop
+		if marked := s.Tree.Marked; marked != nil && !s.jobBusy() {
+			return s.guard(marked.Path, func() tea.Cmd {
+				s.dropMarks()
+				return s.startJob(s.Tree.FS(), JobDelete, marked.Path, "", false)
+			})
 		}
+	case "n", "esc", "ctrl+c":
 		s.OpBuf = Noop
+		s.Tree.DropMark()
 	default:
+		// any other key cancels deletion, so it's never confirmed by accident later
 		s.OpBuf = Noop
 		s.Tree.DropMark()
 		return s.processKeyDefault(msg)
@@ -198,97 +571,278 @@ func (s *State) processKeyDelete(msg tea.KeyMsg) tea.Cmd {
 func (s *State) processKeyMove(msg tea.KeyMsg) tea.Cmd {
 	switch msg.String() {
 	case "p":
-		err := s.Tree.MoveMarkedToCurrentDir()
-		if err != nil {
-			s.ErrBuf = err.Error()
-		}
 		s.OpBuf = Noop
+		return s.pasteMarked(JobMove)
 	default:
 		return s.processKeyDefault(msg)
 	}
-	return nil
 }
 func (s *State) processKeyCopy(msg tea.KeyMsg) tea.Cmd {
 	switch msg.String() {
 	case "p":
-		err := s.Tree.CopyMarkedToCurrentDir()
-		if err != nil {
-			s.ErrBuf = err.Error()
-		}
 		s.OpBuf = Noop
+		return s.pasteMarked(JobCopy)
 	default:
 		return s.processKeyDefault(msg)
 	}
-	return nil
 }
 func (s *State) processKeyDefault(msg tea.KeyMsg) tea.Cmd {
-	switch msg.String() {
-	case "esc":
-		s.Tree.DropMark()
+	action, count, ok := s.readKey(msg.String())
+	if !ok {
+		return nil
+	}
+	if count == 0 {
+		return s.processAction(action)
+	}
+	return s.processCounted(action, count)
+}
+func (s *State) processAction(action ActionID) tea.Cmd {
+	if s.refused(action) || s.remoteRefused(action) {
+		return nil
+	}
+	if s.HelpToggle {
+		return s.processHelpAction(action)
+	}
+	if s.Focus == PanePreview && s.processPreviewAction(action) {
+		return nil
+	}
+	if s.ColumnView() && s.processColumnAction(action) {
+		return nil
+	}
+	switch action {
+	case ActionCancel:
+		s.dropMarks()
+		s.Compare = nil
+		s.Tail = nil
+		s.closeChecksums()
 		s.OpBuf = Noop
 		s.ErrBuf = ""
-	case "ctrl+c", "q":
+	case ActionQuit:
+		return tea.Quit
+	case ActionBookmarkSet:
+		s.OpBuf = BookmarkSet
+	case ActionBookmarkJump:
+		s.OpBuf = BookmarkJump
+	case ActionAnchorSet:
+		if child := s.Tree.GetSelectedChild(); child != nil && child.Info.Mode().IsRegular() {
+			s.OpBuf = AnchorSet
+		}
+	case ActionQuitCd:
+		s.CdOnExit = true
 		return tea.Quit
-	case "j", "down":
+	case ActionSelectNext:
 		s.Tree.SelectNextChild()
-	case "k", "up":
+	case ActionSelectPrev:
 		s.Tree.SelectPreviousChild()
-	case "l", "right":
+	case ActionEnterDir:
 		err := s.Tree.SetSelectedChildAsCurrent()
 		if err != nil {
 			s.ErrBuf = err.Error()
 		}
-	case "h", "left":
+	case ActionParentDir:
 		s.Tree.SetParentAsCurrent()
-	case "y":
+	case ActionCopy:
 		if ok := s.Tree.MarkSelectedChild(); ok {
 			s.OpBuf = Copy
 		}
-	case "d":
+	case ActionCopyTo:
+		s.promptTransfer(CopyTo)
+	case ActionMoveTo:
+		s.promptTransfer(MoveTo)
+	case ActionChecksums:
+		return s.computeChecksums()
+	case ActionArchive:
+		s.promptArchive()
+	case ActionExtract:
+		s.promptExtract()
+	case ActionYank:
+		s.OpBuf = Yank
+	case ActionMove:
 		if ok := s.Tree.MarkSelectedChild(); ok {
 			s.OpBuf = Move
 		}
-	case "D":
-		if ok := s.Tree.MarkSelectedChild(); ok {
-			s.OpBuf = Delete
-		}
-	case "g":
-		s.OpBuf = Go
-	case "G":
-		s.Tree.CurrentDir.SelectLast()
-	case "i":
+	case ActionDelete:
+		return s.deleteSelected()
+	case ActionMessages:
+		s.showMessages()
+	case ActionSelectFirst:
+		s.Tree.SelectFirstChild()
+	case ActionSelectLast:
+		s.Tree.SelectLastChild()
+	case ActionInsert:
 		s.Tree.DropMark()
 		s.OpBuf = Insert
-	case "r":
+	case ActionRename:
 		if ok := s.Tree.MarkSelectedChild(); ok {
-			s.InputBuf = []rune(s.Tree.Marked.Info.Name())
+			s.setNameInput(s.Tree.Marked)
 			s.OpBuf = Rename
 		}
-	case "e":
+	case ActionBulkRename:
+		s.startBulkRename()
+	case ActionChmod:
+		s.startChmod()
+	case ActionChown:
+		s.startChown()
+	case ActionEdit:
 		child := s.Tree.GetSelectedChild()
 		if child != nil && child.Info.Mode().IsRegular() {
+			s.runHook(HookOpen, child.Path)
 			return openEditor(child.Path)
 		}
-	case "?":
+	case ActionOpen:
+		child := s.Tree.GetSelectedChild()
+		if child != nil {
+			s.runHook(HookOpen, child.Path)
+			return s.openExternal(child)
+		}
+	case ActionOpenWith:
+		s.promptOpenWith()
+	case ActionToggleHelp:
 		s.HelpToggle = !s.HelpToggle
-	case "\"":
+		s.HelpOffset = 0
+	case ActionTogglePreview:
 		s.PreviewToggle = !s.PreviewToggle
-	case "enter":
-		err := s.Tree.CollapseOrExpandSelected()
-		if err != nil {
+		s.fixFocus()
+	case ActionToggleDualPane:
+		if err := s.toggleDualPane(); err != nil {
 			s.ErrBuf = err.Error()
 		}
+	case ActionCycleFocus:
+		s.cycleFocus()
+	case ActionTimeTravel:
+		return s.startTimeTravel()
+	case ActionRestore:
+		return s.restoreFromSnapshot()
+	case ActionToggleChurn:
+		return s.toggleChurn()
+	case ActionDirSize:
+		return s.computeSelectedSize()
+	case ActionFilter:
+		s.startFilter()
+	case ActionToggleSelect:
+		s.toggleSelected()
+	case ActionBack:
+		s.historyStep(-1)
+	case ActionForward:
+		s.historyStep(1)
+	case ActionRecent:
+		s.openRecent()
+	case ActionDrives:
+		s.openDrives()
+	case ActionUndo:
+		return s.undo()
+	case ActionRedo:
+		return s.redo()
+	case ActionJournal:
+		s.showJournal()
+	case ActionRegister:
+		s.OpBuf = RegisterPick
+	case ActionPin:
+		s.togglePin()
+	case ActionPinTransient:
+		s.PinTransient = !s.PinTransient
+	case ActionToss:
+		s.tossToBasket()
+	case ActionBasket:
+		s.openBasket()
+	case ActionGrowTree:
+		s.SetSplitRatio(s.SplitRatio + SplitRatioStep)
+	case ActionShrinkTree:
+		s.SetSplitRatio(s.SplitRatio - SplitRatioStep)
+	case ActionToggleNumbers:
+		s.LineNumbers = !s.LineNumbers
+	case ActionToggleWrap:
+		s.WrapLines = !s.WrapLines
+	case ActionToggleColumns:
+		s.Columns = !s.Columns
+	case ActionToggleNatural:
+		o := s.NameOrder
+		o.Natural = !o.Natural
+		s.SetNameOrder(o)
+	case ActionToggleCase:
+		o := s.NameOrder
+		o.CaseSensitive = !o.CaseSensitive
+		s.SetNameOrder(o)
+	case ActionToggleGroups:
+		s.Tree.SetGrouped(!s.Tree.Grouped())
+	case ActionFoldGroup:
+		s.Tree.FoldSelectedGroup()
+	case ActionUnfoldGroups:
+		s.Tree.UnfoldGroups()
+	case ActionCancelJob:
+		s.cancelJob()
+	case ActionScrollLeft:
+		s.scrollTree(-TreeScrollStep)
+	case ActionScrollRight:
+		s.scrollTree(TreeScrollStep)
+	case ActionClearFilter:
+		s.Tree.SetFilter("")
+		s.Tree.SetEntryType(t.AllEntries)
+	case ActionDirsOnly:
+		s.toggleEntryType(t.DirsOnly)
+	case ActionFilesOnly:
+		s.toggleEntryType(t.FilesOnly)
+	case ActionCompare:
+		s.toggleCompare()
+	case ActionTail:
+		return s.toggleTail()
+	case ActionSubshell:
+		return subshell(s.Tree.CurrentDir.Path)
+	case ActionSearch:
+		s.startSearch()
+	case ActionSearchNext:
+		s.searchNext(1)
+	case ActionSearchPrev:
+		s.searchNext(-1)
+	case ActionGrep:
+		s.setInput("")
+		s.OpBuf = GrepInput
+	case ActionShell:
+		s.setInput("")
+		s.OpBuf = ShellInput
+	case ActionSend:
+		s.promptSend()
+	case ActionExport:
+		s.promptExport()
+	case ActionStaging:
+		s.openStaging()
+	case ActionCleanup:
+		return s.startCleanup()
+	case ActionCleanArtifacts:
+		s.findArtifacts()
+	case ActionPreviewDown:
+		s.scrollPreview(1)
+	case ActionPreviewUp:
+		s.scrollPreview(-1)
+	case ActionPreviewPageDown:
+		s.scrollPreview(s.previewPage())
+	case ActionPreviewPageUp:
+		s.scrollPreview(-s.previewPage())
+	case ActionNewTab:
+		if err := s.newTab(); err != nil {
+			s.ErrBuf = err.Error()
+		}
+	case ActionCloseTab:
+		s.closeTab()
+	case ActionNextTab:
+		s.switchTab(1)
+	case ActionPrevTab:
+		s.switchTab(-1)
+	case ActionToggleMarkdown:
+		s.MarkdownRaw = !s.MarkdownRaw
+	case ActionToggleDiff:
+		s.DiffToggle = !s.DiffToggle
+		s.PreviewOffset = 0
+	case ActionToggleDetails:
+		s.DetailToggle = !s.DetailToggle
+	case ActionToggleExpand:
+		return s.openSelected()
+	case ActionExpandAll:
+		s.expandSelected(0)
+	case ActionCollapseUnder:
+		s.Tree.CollapseUnderSelected()
+	case ActionCollapseOthers:
+		s.Tree.CollapseSiblings()
 	}
 	return nil
 }
-
-func openEditor(path string) tea.Cmd {
-	editor := os.Getenv("EDITOR")
-	if editor == "" {
-		editor = "vim"
-	}
-	c := exec.Command(editor, path)
-	return tea.ExecProcess(c, func(err error) tea.Msg {
-		return nil
-	})
-}
@@ -0,0 +1,99 @@
+package state
+
+import (
+	"path/filepath"
+
+	t "github.com/LeperGnome/bt/internal/tree"
+)
+
+// Tab is a separate working context with its own trees, cursors and scroll offsets.
+type Tab struct {
+	Panes      [2]*t.Tree
+	DualPane   bool
+	ActivePane int
+	Focus      Pane
+	TimeTravel *TimeTravelSession
+	History    *History
+}
+
+// Returns short tab name for the tab bar.
+func (tab *Tab) Title() string {
+	return filepath.Base(tab.Panes[tab.ActivePane].CurrentDir.Path)
+}
+
+// Saves live pane state into the active tab.
+func (s *State) storeTab() {
+	tab := s.Tabs[s.ActiveTab]
+	tab.Panes = s.Panes
+	tab.DualPane = s.DualPane
+	tab.ActivePane = s.ActivePane
+	tab.Focus = s.Focus
+	tab.TimeTravel = s.TimeTravel
+	tab.History = s.History
+}
+
+func (s *State) loadTab(idx int) {
+	tab := s.Tabs[idx]
+	s.ActiveTab = idx
+	s.Panes = tab.Panes
+	s.DualPane = tab.DualPane
+	s.ActivePane = tab.ActivePane
+	s.Tree = s.Panes[s.ActivePane]
+	s.Focus = tab.Focus
+	s.TimeTravel = tab.TimeTravel
+	s.History = tab.History
+	s.fixFocus()
+}
+
+// Opens new tab in the current directory and switches to it.
+func (s *State) newTab() error {
+	tree, changes, err := t.InitTreeFS(s.Tree.FS(), s.Tree.CurrentDir.Path, s.NameOrder.Sorting())
+	if err != nil {
+		return err
+	}
+	s.watchTree(changes)
+	s.storeTab()
+	s.Tabs = append(s.Tabs, &Tab{Panes: [2]*t.Tree{tree, nil}})
+	s.loadTab(len(s.Tabs) - 1)
+	return nil
+}
+
+// Closes active tab. The last tab can't be closed.
+func (s *State) closeTab() {
+	if len(s.Tabs) == 1 {
+		return
+	}
+	for _, p := range s.Panes {
+		if p != nil {
+			p.Close()
+		}
+	}
+	s.Tabs = append(s.Tabs[:s.ActiveTab], s.Tabs[s.ActiveTab+1:]...)
+	s.loadTab(min(s.ActiveTab, len(s.Tabs)-1))
+}
+
+// Switches to the tab, that is delta positions away, wrapping around.
+func (s *State) switchTab(delta int) {
+	if len(s.Tabs) == 1 {
+		return
+	}
+	s.storeTab()
+	s.loadTab(((s.ActiveTab+delta)%len(s.Tabs) + len(s.Tabs)) % len(s.Tabs))
+}
+
+// Returns trees from all tabs.
+func (s *State) allTrees() []*t.Tree {
+	trees := []*t.Tree{}
+	for i, tab := range s.Tabs {
+		panes := tab.Panes
+		if i == s.ActiveTab {
+			panes = s.Panes
+		}
+		for _, p := range panes {
+			if p != nil {
+				trees = append(trees, p)
+			}
+		}
+	}
+	return trees
+}
@@ -0,0 +1,149 @@
+package state
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/LeperGnome/bt/internal/i18n"
+	t "github.com/LeperGnome/bt/internal/tree"
+)
+
+const (
+	TailLines     = 1000     // kept in the pane, older lines are dropped
+	TailStartSize = 64 << 10 // read from the end, when following starts or falls behind
+	TailPoll      = 500 * time.Millisecond
+)
+
+// File, followed like with tail -f: the last lines are shown and new ones are appended.
+type TailView struct {
+	Path    string
+	Lines   []string
+	Back    int // lines scrolled back from the end, 0 - following
+	offset  int64
+	partial string // last line, that isn't terminated yet
+	id      int
+}
+
+// Sent by the poll with what was appended to the followed file.
+type TailRead struct {
+	id        int
+	Data      []byte
+	Offset    int64 // where Data starts
+	Truncated bool  // file got shorter, Data starts anew
+	Err       error
+}
+
+// Starts following the selected file, or stops, if it's followed already.
+func (s *State) toggleTail() tea.Cmd {
+	if s.Tail != nil {
+		s.Tail = nil
+		return nil
+	}
+	selected := s.Tree.GetSelectedChild()
+	if selected == nil || !selected.Info.Mode().IsRegular() {
+		s.ErrBuf = i18n.T("ui.select-tail")
+		return nil
+	}
+	s.tailID++
+	s.Tail = &TailView{Path: selected.Path, offset: -1, id: s.tailID}
+	return readTail(s.Tree.FS(), s.Tail.Path, -1, s.tailID, 0)
+}
+
+// Reads everything after offset in background, after delay. Negative offset reads the end of the file.
+func readTail(fsys t.FS, path string, offset int64, id int, delay time.Duration) tea.Cmd {
+	return tea.Tick(delay, func(time.Time) tea.Msg {
+		f, err := fsys.Open(path)
+		if err != nil {
+			return TailRead{id: id, Err: err}
+		}
+		defer f.Close()
+		info, err := f.Stat()
+		if err != nil {
+			return TailRead{id: id, Err: err}
+		}
+		size := info.Size()
+		msg := TailRead{id: id, Offset: offset}
+		if offset > size {
+			msg.Truncated = true
+			offset = 0
+		}
+		if offset < 0 || size-offset > TailStartSize {
+			offset = max(size-TailStartSize, 0)
+		}
+		if offset == size {
+			msg.Offset = offset
+			return msg
+		}
+		seeker, ok := f.(io.Seeker)
+		if !ok {
+			return TailRead{id: id, Err: fmt.Errorf(i18n.T("ui.tail-unseekable"), path)}
+		}
+		if _, err := seeker.Seek(offset, io.SeekStart); err != nil {
+			return TailRead{id: id, Err: err}
+		}
+		msg.Offset = offset
+		msg.Data, msg.Err = io.ReadAll(io.LimitReader(f, size-offset))
+		return msg
+	})
+}
+
+func (s *State) processTailRead(msg TailRead) tea.Cmd {
+	v := s.Tail
+	if v == nil || v.id != msg.id {
+		return nil // stopped
+	}
+	if msg.Err != nil {
+		s.ErrBuf = msg.Err.Error()
+		s.Tail = nil
+		return nil
+	}
+	if msg.Truncated || msg.Offset != v.offset {
+		// file got shorter or fell too far behind, reading starts anew
+		fromStart := msg.Offset == 0
+		v.Lines, v.partial = nil, ""
+		if msg.Truncated {
+			v.Lines = []string{"--- truncated ---"}
+		}
+		if !fromStart {
+			// the first line is cut somewhere in the middle
+			if i := bytes.IndexByte(msg.Data, '\n'); i >= 0 {
+				msg.Data = msg.Data[i+1:]
+			} else {
+				msg.Data = nil
+			}
+		}
+	}
+	v.offset = msg.Offset + int64(len(msg.Data))
+	v.append(string(msg.Data))
+	return readTail(s.Tree.FS(), v.Path, v.offset, v.id, TailPoll)
+}
+
+func (v *TailView) append(data string) {
+	if data == "" {
+		return
+	}
+	lines := strings.Split(v.partial+data, "\n")
+	v.partial = lines[len(lines)-1]
+	v.Lines = append(v.Lines, lines[:len(lines)-1]...)
+	if len(v.Lines) > TailLines {
+		v.Lines = v.Lines[len(v.Lines)-TailLines:]
+	}
+}
+
+// Returns followed lines, with the unterminated last one.
+func (v *TailView) Shown() []string {
+	if v.partial == "" {
+		return v.Lines
+	}
+	return append(v.Lines[:len(v.Lines):len(v.Lines)], v.partial)
+}
+
+// Scrolls back from the end by delta lines, back to following at 0.
+func (v *TailView) scroll(delta int) {
+	v.Back = max(min(v.Back-delta, len(v.Shown())-1), 0)
+}
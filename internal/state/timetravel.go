@@ -0,0 +1,129 @@
+package state
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/LeperGnome/bt/internal/i18n"
+	"github.com/LeperGnome/bt/internal/snapshot"
+	t "github.com/LeperGnome/bt/internal/tree"
+)
+
+// Browsing of snapshots of a directory in the second pane.
+type TimeTravelSession struct {
+	Dir       string              // live directory
+	Snapshots []snapshot.Snapshot // nil - still looking
+	Cursor    int
+	Active    *snapshot.Snapshot // opened in the second pane
+}
+
+type SnapshotList struct {
+	Dir       string
+	Snapshots []snapshot.Snapshot
+	Err       error
+}
+
+// Looks for snapshots of current directory in background and shows picker.
+func (s *State) startTimeTravel() tea.Cmd {
+	dir := s.Tree.CurrentDir.Path
+	s.TimeTravel = &TimeTravelSession{Dir: dir}
+	s.OpBuf = SnapshotPick
+	return func() tea.Msg {
+		snaps, err := snapshot.List(dir)
+		return SnapshotList{Dir: dir, Snapshots: snaps, Err: err}
+	}
+}
+
+func (s *State) processSnapshotList(msg SnapshotList) tea.Cmd {
+	tt := s.TimeTravel
+	if tt == nil || tt.Dir != msg.Dir {
+		return nil
+	}
+	if msg.Err != nil {
+		s.ErrBuf = msg.Err.Error()
+	}
+	if len(msg.Snapshots) == 0 {
+		s.ErrBuf = fmt.Sprintf(i18n.T("ui.no-snapshots"), msg.Dir)
+		s.TimeTravel = nil
+		s.OpBuf = Noop
+		return nil
+	}
+	tt.Snapshots = msg.Snapshots
+	return nil
+}
+
+func (s *State) processKeySnapshotPick(msg tea.KeyMsg) tea.Cmd {
+	tt := s.TimeTravel
+	switch msg.String() {
+	case "j", "down":
+		tt.Cursor = min(tt.Cursor+1, max(len(tt.Snapshots)-1, 0))
+	case "k", "up":
+		tt.Cursor = max(tt.Cursor-1, 0)
+	case "enter":
+		if tt.Cursor >= len(tt.Snapshots) {
+			return nil
+		}
+		s.OpBuf = Noop
+		if err := s.openSnapshot(&tt.Snapshots[tt.Cursor]); err != nil {
+			s.ErrBuf = err.Error()
+		}
+	default:
+		s.OpBuf = Noop
+		if tt.Active == nil {
+			s.TimeTravel = nil
+		}
+	}
+	return nil
+}
+
+// Opens snapshot in the second pane, side by side with the live directory.
+func (s *State) openSnapshot(snap *snapshot.Snapshot) error {
+	tree, changes, err := t.InitTree(snap.Path, s.NameOrder.Sorting())
+	if err != nil {
+		return err
+	}
+	s.watchTree(changes)
+	if s.ActivePane == 1 {
+		s.setFocus(PaneFirstTree)
+	}
+	if old := s.Panes[1]; old != nil {
+		old.Close()
+	}
+	s.Panes[1] = tree
+	s.DualPane = true
+	s.TimeTravel.Active = snap
+	s.setFocus(PaneSecondTree)
+	return nil
+}
+
+// Copies selected entry of the snapshot back to the same place in the live directory, in background.
+// Existing files are kept, restored copy gets a new name then.
+func (s *State) restoreFromSnapshot() tea.Cmd {
+	tt := s.TimeTravel
+	if tt == nil || tt.Active == nil || !s.DualPane || s.Tree != s.Panes[1] {
+		return nil
+	}
+	selected := s.Tree.GetSelectedChild()
+	if selected == nil || s.jobBusy() {
+		return nil
+	}
+	rel, err := filepath.Rel(tt.Active.Path, selected.Path)
+	if err != nil {
+		s.ErrBuf = err.Error()
+		return nil
+	}
+	target := filepath.Join(tt.Dir, rel)
+	if err := os.MkdirAll(filepath.Dir(target), 0o755); err != nil {
+		s.ErrBuf = err.Error()
+		return nil
+	}
+	dst, err := s.Tree.FreeName(target)
+	if err != nil {
+		s.ErrBuf = err.Error()
+		return nil
+	}
+	return s.startJob(t.OS, JobCopy, selected.Path, dst, false)
+}
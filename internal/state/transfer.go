@@ -0,0 +1,159 @@
+package state
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/LeperGnome/bt/internal/i18n"
+	t "github.com/LeperGnome/bt/internal/tree"
+	"github.com/LeperGnome/bt/pkg/paths"
+)
+
+// Asks for the destination of selected paths, or the child under cursor, starting from the current directory.
+func (s *State) promptTransfer(op Operation) {
+	if len(s.shellTargets()) == 0 {
+		return
+	}
+	s.OpBuf = op
+	s.setInput(s.Tree.CurrentDir.Path + string(filepath.Separator))
+}
+
+// Paths, offered by tab completion of a typed path. Cleared by any other key.
+func (s *State) Completions() []string {
+	return s.completions
+}
+
+// Handles input of a path, completed by tab, and submits it on enter.
+func (s *State) processKeyPathInput(msg tea.KeyMsg, submit func(typed string) tea.Cmd) tea.Cmd {
+	s.completions = nil
+	switch msg.String() {
+	case "tab":
+		s.completeDir()
+	case "enter":
+		typed := string(s.InputBuf)
+		s.OpBuf = Noop
+		s.setInput("")
+		return submit(typed)
+	default:
+		return s.processKeyAnyInput(msg)
+	}
+	return nil
+}
+
+// Returns path as typed: ~ is the home directory, relative paths start in the current directory.
+func (s *State) typedPath(typed string) string {
+	if rest, ok := strings.CutPrefix(typed, "~"); ok && s.Tree.Local() && (rest == "" || os.IsPathSeparator(rest[0])) {
+		if home, err := os.UserHomeDir(); err == nil {
+			typed = home + rest
+		}
+	}
+	if !filepath.IsAbs(typed) {
+		typed = filepath.Join(s.Tree.CurrentDir.Path, typed)
+	}
+	return typed
+}
+
+// Completes the last element of the input to a directory name. When several directories match,
+// their common prefix is completed and names are offered in Completions.
+func (s *State) completeDir() {
+	typed := string(s.InputBuf)
+	dir, base := filepath.Split(typed)
+	entries, err := s.Tree.FS().ReadDir(s.typedPath(dir))
+	if err != nil {
+		return
+	}
+	matches := []string{}
+	for _, e := range entries {
+		name := e.Name()
+		if !strings.HasPrefix(name, base) || (strings.HasPrefix(name, ".") && !strings.HasPrefix(base, ".")) {
+			continue
+		}
+		if e.IsDir() || isLinkedDir(s.Tree.FS(), filepath.Join(s.typedPath(dir), name)) {
+			matches = append(matches, name)
+		}
+	}
+	switch len(matches) {
+	case 0:
+		return
+	case 1:
+		s.setInput(dir + matches[0] + string(filepath.Separator))
+		return
+	}
+	slices.Sort(matches)
+	prefix := matches[0]
+	for _, m := range matches[1:] {
+		for !strings.HasPrefix(m, prefix) {
+			prefix = prefix[:len(prefix)-1]
+		}
+	}
+	s.setInput(dir + prefix)
+	s.completions = matches
+}
+
+func isLinkedDir(fsys t.FS, path string) bool {
+	info, err := fsys.Stat(path)
+	return err == nil && info.IsDir()
+}
+
+// Copies or moves selected paths to dst. Existing directory receives them under their names,
+// otherwise the only path is copied or moved to dst itself.
+func (s *State) transfer(kind, typed string) tea.Cmd {
+	srcs := s.shellTargets()
+	if len(srcs) == 0 || s.jobBusy() {
+		return nil
+	}
+	fsys := s.Tree.FS()
+	dst := s.typedPath(typed)
+	if err := paths.CheckName(filepath.Base(dst)); err != nil {
+		s.ErrBuf = err.Error()
+		return nil
+	}
+	dsts := []string{dst}
+	if info, err := fsys.Stat(dst); err == nil && info.IsDir() {
+		dsts = dsts[:0]
+		for _, src := range srcs {
+			dsts = append(dsts, filepath.Join(dst, filepath.Base(src)))
+		}
+	} else if len(srcs) > 1 {
+		s.ErrBuf = fmt.Sprintf(i18n.T("ui.not-directory"), dst)
+		return nil
+	}
+	if err := checkTransfer(fsys, kind, srcs, dsts); err != nil {
+		s.ErrBuf = err.Error()
+		return nil
+	}
+	start := func() tea.Cmd {
+		if kind == JobMove {
+			for _, src := range srcs {
+				s.unselect(src)
+			}
+		}
+		return s.startTransfer(fsys, kind, srcs, dsts)
+	}
+	if kind == JobMove {
+		for _, src := range srcs {
+			if s.isProtected(src) {
+				return s.guard(src, start)
+			}
+		}
+	}
+	return start()
+}
+
+// Paths can't be copied or moved into themselves, nor onto existing targets.
+func checkTransfer(fsys t.FS, kind string, srcs, dsts []string) error {
+	for i, src := range srcs {
+		if paths.Within(src, dsts[i]) {
+			return fmt.Errorf(i18n.T("ui."+kind+"-into-self"), src)
+		}
+		if _, err := fsys.Lstat(dsts[i]); err == nil {
+			return fmt.Errorf(i18n.T("ui.path-exists"), dsts[i])
+		}
+	}
+	return nil
+}
@@ -0,0 +1,17 @@
+//go:build !unix
+
+package throughput
+
+import "path/filepath"
+
+// Returns key of the filesystem, path is on. Without mount information it's the volume name.
+func Filesystem(path string) string {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return ""
+	}
+	if vol := filepath.VolumeName(existing(abs)); vol != "" {
+		return vol
+	}
+	return "/"
+}
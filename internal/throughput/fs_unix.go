@@ -0,0 +1,74 @@
+//go:build unix
+
+package throughput
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"strings"
+	"syscall"
+)
+
+// Returns key of the filesystem, path is on: its type, when known, and mount point,
+// e.g. "nfs4:/mnt/share". Path may not exist yet, then its closest existing ancestor is used.
+func Filesystem(path string) string {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return ""
+	}
+	mount := mountPoint(existing(abs))
+	if typ := fsType(mount); typ != "" {
+		return typ + ":" + mount
+	}
+	return mount
+}
+
+// Walks up from dir, while the device stays the same.
+func mountPoint(dir string) string {
+	dev, ok := device(dir)
+	if !ok {
+		return dir
+	}
+	for {
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return dir
+		}
+		if d, ok := device(parent); !ok || d != dev {
+			return dir
+		}
+		dir = parent
+	}
+}
+
+func device(path string) (uint64, bool) {
+	var st syscall.Stat_t
+	if err := syscall.Stat(path, &st); err != nil {
+		return 0, false
+	}
+	return uint64(st.Dev), true
+}
+
+// Spaces and other special characters are octal escaped in mount table.
+var mountUnescape = strings.NewReplacer(`\040`, " ", `\011`, "\t", `\012`, "\n", `\134`, `\`)
+
+// Looks type of filesystem, mounted at mount, up in the mount table. Empty, if there is none,
+// e.g. outside of linux.
+func fsType(mount string) string {
+	f, err := os.Open("/proc/self/mounts")
+	if err != nil {
+		return ""
+	}
+	defer f.Close()
+	typ := ""
+	sc := bufio.NewScanner(f)
+	for sc.Scan() {
+		fields := strings.Fields(sc.Text())
+		// the latest mount over the same point wins
+		if len(fields) >= 3 && mountUnescape.Replace(fields[1]) == mount {
+			typ = fields[2]
+		}
+	}
+	return typ
+}
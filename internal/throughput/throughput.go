@@ -0,0 +1,121 @@
+// Package throughput keeps observed transfer speeds per destination filesystem,
+// so copy time can be estimated before and during the copy.
+package throughput
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/LeperGnome/bt/internal/persist"
+)
+
+// Schema version of the throughput file.
+const schema = 1
+
+const (
+	// Transfers, smaller or shorter than this, are dominated by latency and caches
+	// and aren't recorded.
+	MinSample   = 4 << 20
+	minDuration = 500 * time.Millisecond
+	// Weight of the latest transfer in the average.
+	weight = 0.3
+)
+
+// Rate is an average speed of transfers to a filesystem.
+type Rate struct {
+	BytesPerSec float64   `json:"bytes_per_sec"`
+	Samples     int       `json:"samples"`
+	Updated     time.Time `json:"updated"`
+}
+
+// Store keeps rates, keyed by filesystem (see Filesystem), and persists them to a file.
+type Store struct {
+	Rates map[string]Rate `json:"rates"`
+	file  *persist.File   // nil - not persisted
+}
+
+// Loads rates from path. Missing or damaged file results in an empty store,
+// file of a newer schema is left untouched and store is not persisted.
+func Load(path string) (*Store, error) {
+	s := &Store{Rates: map[string]Rate{}}
+	if path == "" {
+		return s, nil
+	}
+	s.file = &persist.File{Path: path, Schema: schema}
+	_, err := s.file.Load(s)
+	var newer *persist.NewerSchemaError
+	if errors.As(err, &newer) {
+		s.file = nil
+	}
+	if s.Rates == nil {
+		s.Rates = map[string]Rate{}
+	}
+	return s, err
+}
+
+// Adds transfer of n bytes, that took d, to the average of fs and saves the store.
+// Small transfers are ignored.
+func (s *Store) Record(fs string, n int64, d time.Duration) error {
+	if n < MinSample || d < minDuration {
+		return nil
+	}
+	speed := float64(n) / d.Seconds()
+	r, ok := s.Rates[fs]
+	if ok {
+		r.BytesPerSec = r.BytesPerSec*(1-weight) + speed*weight
+	} else {
+		r.BytesPerSec = speed
+	}
+	r.Samples++
+	r.Updated = time.Now()
+	s.Rates[fs] = r
+	return s.save()
+}
+
+// Returns average speed of transfers to fs in bytes per second.
+func (s *Store) Rate(fs string) (float64, bool) {
+	r, ok := s.Rates[fs]
+	return r.BytesPerSec, ok && r.BytesPerSec > 0
+}
+
+// Estimates time, left to transfer left bytes, from the historical rate and,
+// once enough is transferred, the rate of the current transfer, running for elapsed.
+func Estimate(historical float64, done, left int64, elapsed time.Duration) (time.Duration, bool) {
+	rate := historical
+	if done >= MinSample && elapsed > 0 {
+		current := float64(done) / elapsed.Seconds()
+		if rate > 0 {
+			// current rate gets more trust, as the transfer goes
+			rate = (rate + current*2) / 3
+		} else {
+			rate = current
+		}
+	}
+	if rate <= 0 {
+		return 0, false
+	}
+	return time.Duration(float64(left) / rate * float64(time.Second)), true
+}
+
+func (s *Store) save() error {
+	if s.file == nil {
+		return nil
+	}
+	return s.file.Save(s)
+}
+
+// Returns closest existing ancestor of path, or path itself.
+func existing(path string) string {
+	for {
+		if _, err := os.Stat(path); err == nil {
+			return path
+		}
+		parent := filepath.Dir(path)
+		if parent == path {
+			return path
+		}
+		path = parent
+	}
+}
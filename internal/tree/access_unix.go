@@ -0,0 +1,25 @@
+//go:build unix
+
+package tree
+
+import "syscall"
+
+// Modes of access(2).
+const (
+	accessRead  = 4
+	accessWrite = 2
+	accessExec  = 1
+)
+
+// Checks, whether the current user can read (list and enter, for directories) and change path.
+// Read-only mounts are reported as not writable.
+func access(path string, dir bool) (readable, writable bool) {
+	read := uint32(accessRead)
+	if dir {
+		read |= accessExec
+	}
+	if syscall.Access(path, read|accessWrite) == nil {
+		return true, true
+	}
+	return syscall.Access(path, read) == nil, syscall.Access(path, accessWrite) == nil
+}
@@ -0,0 +1,94 @@
+package tree
+
+// Directories, read by one recursive expansion. Deeper levels of huge subtrees are left
+// unread, so the expansion doesn't hang.
+const ExpandLimit = 1000
+
+// Expands the selected directory, see Expand.
+func (t *Tree) ExpandSelected(depth int) (bool, error) {
+	selected := t.GetSelectedChild()
+	if selected == nil || !selected.IsDir() {
+		return false, nil
+	}
+	return t.Expand(selected, depth)
+}
+
+// Expands directory n down to depth levels (0 - up to MaxDepth), level by level.
+// Loaded directories aren't read again, linked directories and loops aren't descended into.
+// Dependency and build directories stay collapsed, unless n is one of them itself.
+// Reports whether reading stopped at ExpandLimit. Returns the first read error, others are skipped.
+func (t *Tree) Expand(n *Node, depth int) (bool, error) {
+	if depth <= 0 {
+		depth = t.MaxDepth
+	}
+	type level struct {
+		node  *Node
+		depth int
+	}
+	queue := []level{{n, 1}}
+	read := 0
+	inArtifact := n.Artifact
+	for p := n.Parent; p != nil && !inArtifact; p = p.Parent {
+		inArtifact = p.Artifact
+	}
+	var firstErr error
+	for len(queue) > 0 {
+		l := queue[0]
+		queue = queue[1:]
+		if l.node.Children == nil {
+			if read == ExpandLimit {
+				return true, firstErr
+			}
+			read++
+			if err := t.expandNode(l.node); err != nil {
+				if firstErr == nil {
+					firstErr = err
+				}
+				continue
+			}
+		}
+		if l.depth == depth {
+			continue
+		}
+		for _, ch := range l.node.Children {
+			if ch.Info.IsDir() && !ch.Loop && (!ch.Artifact || inArtifact) {
+				queue = append(queue, level{ch, l.depth + 1})
+			}
+		}
+	}
+	return false, firstErr
+}
+
+// Collapses directories under the selected one, leaving its own entries shown.
+func (t *Tree) CollapseUnderSelected() {
+	selected := t.GetSelectedChild()
+	if selected == nil {
+		return
+	}
+	for _, ch := range selected.Children {
+		t.collapse(ch)
+	}
+}
+
+// Collapses directories next to the selected entry, so only its branch stays open.
+func (t *Tree) CollapseSiblings() {
+	selected := t.GetSelectedChild()
+	for _, ch := range t.CurrentDir.Children {
+		if ch != selected {
+			t.collapse(ch)
+		}
+	}
+}
+
+// Collapses the node, no longer watching it and its expanded descendants.
+func (t *Tree) collapse(n *Node) {
+	if n.Children == nil {
+		return
+	}
+	for _, ch := range n.Children {
+		t.collapse(ch)
+	}
+	t.watcher.Remove(n.Path)
+	n.orphanChildren()
+	t.generation++
+}
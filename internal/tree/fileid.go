@@ -0,0 +1,52 @@
+package tree
+
+import "io/fs"
+
+// FileID identifies file on a system regardless of the path it's reached by
+// (hardlinks, bind mounts, symlinked directories).
+type FileID struct {
+	Dev uint64
+	Ino uint64
+}
+
+// Returns identity of the file, info describes. Not every system and file system has one.
+func FileIDOf(info fs.FileInfo) (FileID, bool) {
+	return fileIDOf(info)
+}
+
+// Returns identity of the file, node points to. For symlinked directories it's the target identity.
+func (n *Node) ID() (FileID, bool) {
+	return n.id, n.hasID
+}
+
+// Only directories and files with multiple hardlinks can be met in the tree more than once.
+func (n *Node) mayRepeat() bool {
+	return n.hasID && (n.IsDir() || linkCount(n.Info) > 1)
+}
+
+// Returns mapping from nodes to the first loaded node with the same identity.
+// Result is cached until tree structure changes.
+func (t *Tree) Duplicates() map[*Node]*Node {
+	if t.dups != nil && t.dupsGeneration == t.generation {
+		return t.dups
+	}
+	seen := map[FileID]*Node{}
+	dups := map[*Node]*Node{}
+	var walk func(n *Node)
+	walk = func(n *Node) {
+		if n.mayRepeat() {
+			if first, ok := seen[n.id]; ok {
+				dups[n] = first
+			} else {
+				seen[n.id] = n
+			}
+		}
+		for _, ch := range n.Children {
+			walk(ch)
+		}
+	}
+	walk(t.Root)
+	t.dups = dups
+	t.dupsGeneration = t.generation
+	return dups
+}
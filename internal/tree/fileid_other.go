@@ -0,0 +1,13 @@
+//go:build !unix
+
+package tree
+
+import "io/fs"
+
+func fileIDOf(info fs.FileInfo) (FileID, bool) {
+	return FileID{}, false
+}
+
+func linkCount(info fs.FileInfo) uint64 {
+	return 1
+}
@@ -0,0 +1,134 @@
+package tree
+
+import (
+	"path/filepath"
+	"strings"
+)
+
+// Kinds of entries, the tree shows.
+type EntryType int
+
+const (
+	AllEntries EntryType = iota
+	DirsOnly             // files are hidden
+	FilesOnly            // directories stay only on the way to files and to the current directory
+)
+
+// Sets glob, that names of shown files must match. Directories stay visible,
+// if they match themselves, contain visible entries among expanded ones or lead
+// to the current directory. Pattern without wildcards matches as a substring.
+// Empty pattern removes the filter.
+func (t *Tree) SetFilter(pattern string) error {
+	if pattern != "" && !strings.ContainsAny(pattern, "*?[") {
+		pattern = "*" + pattern + "*"
+	}
+	if _, err := filepath.Match(pattern, ""); err != nil {
+		return err
+	}
+	t.filter = pattern
+	t.visible = nil
+	t.generation++
+	return nil
+}
+
+// Returns active filter glob or an empty string.
+func (t *Tree) Filter() string {
+	return t.filter
+}
+
+// Shows only entries of the type. It's applied together with the glob filter:
+// entries must pass both.
+func (t *Tree) SetEntryType(et EntryType) {
+	t.entryType = et
+	t.visible = nil
+	t.generation++
+}
+
+func (t *Tree) EntryType() EntryType {
+	return t.entryType
+}
+
+// Reports whether node passes the filter and entry type, and is not in a folded group.
+func (t *Tree) Visible(n *Node) bool {
+	if t.foldedAway(n) {
+		return false
+	}
+	if t.filter == "" && t.entryType == AllEntries {
+		return true
+	}
+	t.computeVisible()
+	return t.visible[n]
+}
+
+// Visibility is cached until tree structure, current directory or filter changes.
+func (t *Tree) computeVisible() {
+	if t.visible != nil && t.visibleGeneration == t.generation && t.visibleCurrent == t.CurrentDir {
+		return
+	}
+	t.visible = map[*Node]bool{}
+	t.visibleGeneration = t.generation
+	t.visibleCurrent = t.CurrentDir
+	for n := t.CurrentDir; n != nil; n = n.Parent {
+		t.visible[n] = true
+	}
+	t.markVisible(t.Root)
+}
+
+func (t *Tree) markVisible(n *Node) bool {
+	ok := t.filter == ""
+	if !ok {
+		ok, _ = filepath.Match(t.filter, n.Info.Name())
+	}
+	switch t.entryType {
+	case DirsOnly:
+		ok = ok && n.IsDir()
+	case FilesOnly:
+		ok = ok && !n.IsDir()
+	}
+	for _, ch := range n.Children {
+		if t.markVisible(ch) {
+			ok = true
+		}
+	}
+	if ok {
+		t.visible[n] = true
+	}
+	return t.visible[n]
+}
+
+// Moves selection of n to the closest visible child, preferring the following ones.
+func (t *Tree) fixSelection(n *Node) {
+	if len(n.Children) == 0 || t.Visible(n.Children[n.selectedChildIdx]) {
+		return
+	}
+	for i := n.selectedChildIdx + 1; i < len(n.Children); i++ {
+		if t.Visible(n.Children[i]) {
+			n.selectedChildIdx = i
+			return
+		}
+	}
+	for i := n.selectedChildIdx - 1; i >= 0; i-- {
+		if t.Visible(n.Children[i]) {
+			n.selectedChildIdx = i
+			return
+		}
+	}
+}
+
+func (t *Tree) SelectFirstChild() {
+	for i, ch := range t.CurrentDir.Children {
+		if t.Visible(ch) {
+			t.CurrentDir.selectedChildIdx = i
+			return
+		}
+	}
+}
+
+func (t *Tree) SelectLastChild() {
+	for i := len(t.CurrentDir.Children) - 1; i >= 0; i-- {
+		if t.Visible(t.CurrentDir.Children[i]) {
+			t.CurrentDir.selectedChildIdx = i
+			return
+		}
+	}
+}
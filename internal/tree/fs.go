@@ -0,0 +1,127 @@
+package tree
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/fsnotify/fsnotify"
+
+	"github.com/LeperGnome/bt/pkg/paths"
+)
+
+// FS is a file system, trees are read from. OS is the default one. Others, like zip archives,
+// embedded files or in-memory file systems in tests, are plugged in with FromFS,
+// remote ones implement FS themselves.
+type FS interface {
+	ReadDir(path string) ([]fs.DirEntry, error)
+	Stat(path string) (fs.FileInfo, error)  // follows symlinks
+	Lstat(path string) (fs.FileInfo, error) // describes symlink itself
+	EvalSymlinks(path string) (string, error)
+	Open(path string) (fs.File, error)
+	// Starts watching for changes. Events of watched directories are sent to the channel,
+	// which is closed with the watcher.
+	Watch() (Watcher, <-chan NodeChange, error)
+
+	// Changes. Read-only file systems fail with errors.ErrUnsupported.
+	Rename(oldPath, newPath string) error
+	RemoveAll(path string) error
+	MkdirAll(path string) error
+	Create(path string) error // creates empty file, path must not exist
+}
+
+// Watcher reports changes of added directories.
+type Watcher interface {
+	Add(path string) error
+	Remove(path string) error
+	WatchList() []string
+	Close() error
+}
+
+// OS is the local file system. Long paths are passed to the system as such, see paths.Long.
+var OS FS = osFS{}
+
+type osFS struct{}
+
+func (osFS) ReadDir(path string) ([]fs.DirEntry, error) { return os.ReadDir(paths.Long(path)) }
+func (osFS) Stat(path string) (fs.FileInfo, error)      { return os.Stat(paths.Long(path)) }
+func (osFS) Lstat(path string) (fs.FileInfo, error)     { return os.Lstat(paths.Long(path)) }
+func (osFS) EvalSymlinks(path string) (string, error)   { return filepath.EvalSymlinks(path) }
+func (osFS) Open(path string) (fs.File, error)          { return os.Open(paths.Long(path)) }
+func (osFS) Watch() (Watcher, <-chan NodeChange, error) { return watchOS() }
+func (osFS) RemoveAll(path string) error                { return os.RemoveAll(paths.Long(path)) }
+func (osFS) MkdirAll(path string) error                 { return os.MkdirAll(paths.Long(path), os.ModePerm) }
+
+func (osFS) Rename(oldPath, newPath string) error {
+	return os.Rename(paths.Long(oldPath), paths.Long(newPath))
+}
+
+func (osFS) Create(path string) error {
+	f, err := os.OpenFile(paths.Long(path), os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o666)
+	if err != nil {
+		return err
+	}
+	return f.Close()
+}
+
+func watchOS() (Watcher, <-chan NodeChange, error) {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, nil, err
+	}
+	return watcher, runFSWatcher(watcher), nil
+}
+
+// Returns FS, reading from fsys. Tree paths are fsys names: root is ".", its children are
+// "name", "dir/name" and so on. fs.FS has no symlinks and changes are never reported.
+func FromFS(fsys fs.FS) FS {
+	return ioFS{fsys}
+}
+
+type ioFS struct {
+	fsys fs.FS
+}
+
+func (f ioFS) name(path string) string {
+	return filepath.ToSlash(filepath.Clean(path))
+}
+
+func (f ioFS) ReadDir(path string) ([]fs.DirEntry, error) { return fs.ReadDir(f.fsys, f.name(path)) }
+func (f ioFS) Stat(path string) (fs.FileInfo, error)      { return fs.Stat(f.fsys, f.name(path)) }
+func (f ioFS) Lstat(path string) (fs.FileInfo, error)     { return fs.Stat(f.fsys, f.name(path)) }
+func (f ioFS) EvalSymlinks(path string) (string, error)   { return filepath.Clean(path), nil }
+func (f ioFS) Open(path string) (fs.File, error)          { return f.fsys.Open(f.name(path)) }
+
+func (f ioFS) Watch() (Watcher, <-chan NodeChange, error) { return Unwatched() }
+
+func (f ioFS) Rename(oldPath, newPath string) error { return readOnly("rename", oldPath) }
+func (f ioFS) RemoveAll(path string) error          { return readOnly("remove", path) }
+func (f ioFS) MkdirAll(path string) error           { return readOnly("mkdir", path) }
+func (f ioFS) Create(path string) error             { return readOnly("create", path) }
+
+func readOnly(op, path string) error {
+	return &fs.PathError{Op: op, Path: path, Err: errors.ErrUnsupported}
+}
+
+// Returns watcher, that never reports changes, for file systems without notifications.
+func Unwatched() (Watcher, <-chan NodeChange, error) {
+	w := &nopWatcher{changes: make(chan NodeChange)}
+	return w, w.changes, nil
+}
+
+// Watcher of a file system, that never changes.
+type nopWatcher struct {
+	changes chan NodeChange
+	once    sync.Once
+}
+
+func (w *nopWatcher) Add(path string) error    { return nil }
+func (w *nopWatcher) Remove(path string) error { return nil }
+func (w *nopWatcher) WatchList() []string      { return nil }
+
+func (w *nopWatcher) Close() error {
+	w.once.Do(func() { close(w.changes) })
+	return nil
+}
@@ -5,12 +5,14 @@ import (
 )
 
 type NodeChange struct {
-	Path string
+	Path     string
+	Modified bool // content or attributes of Path changed, directory entries are the same
 }
 
 func runFSWatcher(watcher *fsnotify.Watcher) <-chan NodeChange {
 	ch := make(chan NodeChange)
 	go func() {
+		defer close(ch)
 		defer watcher.Close()
 		for {
 			select {
@@ -20,6 +22,8 @@ func runFSWatcher(watcher *fsnotify.Watcher) <-chan NodeChange {
 				}
 				if event.Has(fsnotify.Remove) || event.Has(fsnotify.Create) || event.Has(fsnotify.Rename) {
 					ch <- NodeChange{Path: event.Name}
+				} else if event.Has(fsnotify.Write) || event.Has(fsnotify.Chmod) {
+					ch <- NodeChange{Path: event.Name, Modified: true}
 				}
 			case _, ok := <-watcher.Errors:
 				if !ok {
@@ -0,0 +1,130 @@
+package tree
+
+import (
+	"cmp"
+	"mime"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/LeperGnome/bt/pkg/paths"
+)
+
+// Group is a kind of directory entries, that are clustered together in grouped view.
+type Group int
+
+const (
+	GroupDirectories Group = iota
+	GroupCode
+	GroupImages
+	GroupDocuments
+	GroupOther
+)
+
+var codeExts = map[string]bool{
+	".go": true, ".c": true, ".h": true, ".cc": true, ".cpp": true, ".hpp": true, ".rs": true,
+	".py": true, ".rb": true, ".js": true, ".jsx": true, ".ts": true, ".tsx": true, ".java": true,
+	".kt": true, ".swift": true, ".cs": true, ".php": true, ".lua": true, ".sh": true, ".bash": true,
+	".zsh": true, ".fish": true, ".sql": true, ".html": true, ".css": true, ".scss": true,
+	".json": true, ".yaml": true, ".yml": true, ".toml": true, ".xml": true, ".mod": true, ".sum": true,
+}
+
+var documentExts = map[string]bool{
+	".md": true, ".markdown": true, ".txt": true, ".rst": true, ".pdf": true, ".doc": true,
+	".docx": true, ".odt": true, ".rtf": true, ".xls": true, ".xlsx": true, ".ods": true,
+	".ppt": true, ".pptx": true, ".odp": true, ".csv": true, ".epub": true,
+}
+
+// Returns group of the node by its type, extension and MIME type, guessed from it.
+func GroupOf(n *Node) Group {
+	if n.IsDir() {
+		return GroupDirectories
+	}
+	ext := strings.ToLower(filepath.Ext(n.Info.Name()))
+	switch {
+	case codeExts[ext]:
+		return GroupCode
+	case documentExts[ext]:
+		return GroupDocuments
+	}
+	mimeType := mime.TypeByExtension(ext)
+	switch {
+	case strings.HasPrefix(mimeType, "image/"):
+		return GroupImages
+	case strings.HasPrefix(mimeType, "text/"):
+		return GroupDocuments
+	}
+	return GroupOther
+}
+
+// Turns grouped view on or off. Loaded directories are re-sorted, keeping selection.
+func (t *Tree) SetGrouped(on bool) {
+	t.grouped = on
+	t.resort(t.Root)
+	t.generation++
+}
+
+// Reports whether entries are clustered by group.
+func (t *Tree) Grouped() bool {
+	return t.grouped
+}
+
+// Hides entries of the selected child group in the current directory.
+func (t *Tree) FoldSelectedGroup() {
+	selected := t.GetSelectedChild()
+	if !t.grouped || selected == nil {
+		return
+	}
+	if t.folded == nil {
+		t.folded = map[*Node]map[Group]bool{}
+	}
+	if t.folded[t.CurrentDir] == nil {
+		t.folded[t.CurrentDir] = map[Group]bool{}
+	}
+	t.folded[t.CurrentDir][GroupOf(selected)] = true
+	t.fixSelection(t.CurrentDir)
+	t.generation++
+}
+
+// Shows entries of all groups in the current directory.
+func (t *Tree) UnfoldGroups() {
+	delete(t.folded, t.CurrentDir)
+	t.generation++
+}
+
+// Reports whether group of dir entries is hidden.
+func (t *Tree) Folded(dir *Node, g Group) bool {
+	return t.grouped && t.folded[dir][g]
+}
+
+// Folded entries are hidden, unless they lead to the current directory.
+func (t *Tree) foldedAway(n *Node) bool {
+	if n.Parent == nil || !t.Folded(n.Parent, GroupOf(n)) {
+		return false
+	}
+	return !paths.Within(n.Path, t.CurrentDir.Path)
+}
+
+func (t *Tree) sorting() NodeSortingFunc {
+	if !t.grouped {
+		return t.sortingFunc
+	}
+	return func(a, b *Node) int {
+		if c := cmp.Compare(GroupOf(a), GroupOf(b)); c != 0 {
+			return c
+		}
+		return t.sortingFunc(a, b)
+	}
+}
+
+func (t *Tree) resort(n *Node) {
+	if len(n.Children) == 0 {
+		return
+	}
+	selected := n.Children[n.selectedChildIdx]
+	slices.SortStableFunc(n.Children, t.sorting())
+	n.selectedChildIdx = slices.Index(n.Children, selected)
+	for _, ch := range n.Children {
+		t.resort(ch)
+	}
+}
@@ -2,10 +2,12 @@ package tree
 
 import (
 	"io/fs"
-	"os"
+	"log/slog"
 	"path/filepath"
 	"slices"
-	"strings"
+	"time"
+
+	"github.com/LeperGnome/bt/internal/artifacts"
 )
 
 type NodeSortingFunc func(a, b *Node) int
@@ -15,9 +17,58 @@ type Node struct {
 	Info             fs.FileInfo
 	Children         []*Node // nil - not read or it's a file
 	Parent           *Node
+	Loop             bool // symlink, pointing to one of the node's ancestors
+	Artifact         bool // dependency or build directory, see artifacts package
+	Unreadable       bool // current user can't read the file or list the directory, local trees only
+	Unwritable       bool // current user can't change it, or it's on a read-only mount, local trees only
+	linkedDir        bool // symlink, pointing to a directory
+	realPath         string
+	id               FileID
+	hasID            bool
 	selectedChildIdx int
 }
 
+// Reports whether node is a directory or a symlink to one.
+func (n *Node) IsDir() bool {
+	return n.Info.IsDir() || n.linkedDir
+}
+
+// Number of ancestors up to the tree root.
+func (n *Node) Depth() int {
+	d := 0
+	for p := n.Parent; p != nil; p = p.Parent {
+		d++
+	}
+	return d
+}
+
+// Returns path with all symlinks resolved. Result is cached.
+func (n *Node) resolvedPath(fsys FS) string {
+	if n.realPath == "" {
+		p, err := fsys.EvalSymlinks(n.Path)
+		if err != nil {
+			p = n.Path
+		}
+		if abs, err := filepath.Abs(p); err == nil {
+			p = abs
+		}
+		n.realPath = p
+	}
+	return n.realPath
+}
+
+// Looks for an ancestor, that resolves to the same directory as n.
+// Both resolved paths and file identities are compared, so bind mount loops are caught too.
+func (n *Node) findLoop(fsys FS) *Node {
+	real := n.resolvedPath(fsys)
+	for p := n.Parent; p != nil; p = p.Parent {
+		if p.resolvedPath(fsys) == real || (n.hasID && p.hasID && p.id == n.id) {
+			return p
+		}
+	}
+	return nil
+}
+
 func (n *Node) SelectLast() {
 	// can I just check for len here?
 	if n.Children != nil && len(n.Children) > 0 {
@@ -27,15 +78,21 @@ func (n *Node) SelectLast() {
 func (n *Node) SelectFirst() {
 	n.selectedChildIdx = 0
 }
-func (n *Node) readChildren(sortFunc NodeSortingFunc) error {
-	if !n.Info.IsDir() {
+func (n *Node) readChildren(fsys FS, sortFunc NodeSortingFunc) error {
+	if !n.IsDir() {
 		return nil
 	}
-	children, err := os.ReadDir(n.Path)
+	start := time.Now()
+	children, err := fsys.ReadDir(n.Path)
+	slog.Debug("read dir", "path", n.Path, "entries", len(children), "took", time.Since(start), "err", err)
 	if err != nil {
 		return err
 	}
 	chNodes := []*Node{}
+	names := make([]string, 0, len(children))
+	for _, ch := range children {
+		names = append(names, ch.Name())
+	}
 
 	for _, ch := range children {
 		ch := ch
@@ -60,6 +117,19 @@ func (n *Node) readChildren(sortFunc NodeSortingFunc) error {
 				Children: nil,
 				Parent:   n,
 			}
+			idInfo := chInfo
+			if chInfo.Mode()&fs.ModeSymlink != 0 {
+				if target, err := fsys.Stat(childToAdd.Path); err == nil && target.IsDir() {
+					childToAdd.linkedDir = true
+					idInfo = target
+				}
+			}
+			childToAdd.id, childToAdd.hasID = fileIDOf(idInfo)
+		}
+		childToAdd.Artifact = childToAdd.IsDir() && artifacts.Match(chInfo.Name(), names)
+		if fsys == OS {
+			readable, writable := access(childToAdd.Path, childToAdd.IsDir())
+			childToAdd.Unreadable, childToAdd.Unwritable = !readable, !writable
 		}
 		chNodes = append(chNodes, childToAdd)
 	}
@@ -74,14 +144,4 @@ func (n *Node) orphanChildren() {
 	n.Children = nil
 }
 
-func defaultNodeSorting(a, b *Node) int {
-	// dirs first
-	if a.Info.IsDir() != b.Info.IsDir() {
-		if a.Info.IsDir() {
-			return -1
-		} else {
-			return 1
-		}
-	}
-	return strings.Compare(strings.ToLower(a.Info.Name()), strings.ToLower(b.Info.Name()))
-}
+var defaultNodeSorting = NameOrder{}.Sorting()
@@ -0,0 +1,9 @@
+//go:build !unix
+
+package tree
+
+import "io/fs"
+
+func Owner(info fs.FileInfo) (string, string) {
+	return "-", "-"
+}
@@ -0,0 +1,49 @@
+//go:build unix
+
+package tree
+
+import (
+	"io/fs"
+	"os/user"
+	"strconv"
+	"syscall"
+)
+
+var (
+	userNames  = map[uint32]string{}
+	groupNames = map[uint32]string{}
+)
+
+// Returns names of file owner and group, falling back to numeric ids. Lookups are cached.
+func Owner(info fs.FileInfo) (string, string) {
+	st, ok := info.Sys().(*syscall.Stat_t)
+	if !ok {
+		return "-", "-"
+	}
+	return lookupName(userNames, st.Uid, func(id string) (string, error) {
+			u, err := user.LookupId(id)
+			if err != nil {
+				return "", err
+			}
+			return u.Username, nil
+		}), lookupName(groupNames, st.Gid, func(id string) (string, error) {
+			g, err := user.LookupGroupId(id)
+			if err != nil {
+				return "", err
+			}
+			return g.Name, nil
+		})
+}
+
+func lookupName(cache map[uint32]string, id uint32, lookup func(string) (string, error)) string {
+	if name, ok := cache[id]; ok {
+		return name
+	}
+	s := strconv.FormatUint(uint64(id), 10)
+	name, err := lookup(s)
+	if err != nil {
+		name = s
+	}
+	cache[id] = name
+	return name
+}
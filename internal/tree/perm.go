@@ -0,0 +1,210 @@
+package tree
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"os/user"
+	"strconv"
+	"strings"
+)
+
+// Parses octal ("644", "0755") or symbolic ("u+x,go-w", "a=r") mode,
+// applying the latter to cur. Only permission and setuid/setgid/sticky bits are changed.
+func ParseMode(spec string, cur fs.FileMode) (fs.FileMode, error) {
+	spec = strings.TrimSpace(spec)
+	if spec == "" {
+		return 0, fmt.Errorf("empty mode")
+	}
+	if spec[0] >= '0' && spec[0] <= '9' {
+		n, err := strconv.ParseUint(spec, 8, 32)
+		if err != nil || n > 0o7777 {
+			return 0, fmt.Errorf("invalid octal mode '%s'", spec)
+		}
+		return cur&^(fs.ModePerm|fs.ModeSetuid|fs.ModeSetgid|fs.ModeSticky) | octalToMode(uint32(n)), nil
+	}
+	mode := cur
+	for _, clause := range strings.Split(spec, ",") {
+		var err error
+		mode, err = applyClause(clause, mode)
+		if err != nil {
+			return 0, fmt.Errorf("invalid symbolic mode '%s': %w", spec, err)
+		}
+	}
+	return mode, nil
+}
+
+func octalToMode(n uint32) fs.FileMode {
+	m := fs.FileMode(n) & fs.ModePerm
+	if n&0o4000 != 0 {
+		m |= fs.ModeSetuid
+	}
+	if n&0o2000 != 0 {
+		m |= fs.ModeSetgid
+	}
+	if n&0o1000 != 0 {
+		m |= fs.ModeSticky
+	}
+	return m
+}
+
+// Applies single clause like "ug+rw" to mode.
+func applyClause(clause string, mode fs.FileMode) (fs.FileMode, error) {
+	i := strings.IndexAny(clause, "+-=")
+	if i < 0 {
+		return 0, fmt.Errorf("missing operator in '%s'", clause)
+	}
+	who, op, perms := clause[:i], clause[i], clause[i+1:]
+
+	var whoMask fs.FileMode // rwx bits of the affected classes
+	if who == "" {
+		who = "a"
+	}
+	for _, c := range who {
+		switch c {
+		case 'u':
+			whoMask |= 0o700
+		case 'g':
+			whoMask |= 0o070
+		case 'o':
+			whoMask |= 0o007
+		case 'a':
+			whoMask |= 0o777
+		default:
+			return 0, fmt.Errorf("unknown class '%c'", c)
+		}
+	}
+
+	var bits fs.FileMode
+	for _, c := range perms {
+		switch c {
+		case 'r':
+			bits |= 0o444 & whoMask
+		case 'w':
+			bits |= 0o222 & whoMask
+		case 'x':
+			bits |= 0o111 & whoMask
+		case 'X':
+			if mode.IsDir() || mode&0o111 != 0 {
+				bits |= 0o111 & whoMask
+			}
+		case 's':
+			if whoMask&0o700 != 0 {
+				bits |= fs.ModeSetuid
+			}
+			if whoMask&0o070 != 0 {
+				bits |= fs.ModeSetgid
+			}
+		case 't':
+			bits |= fs.ModeSticky
+		default:
+			return 0, fmt.Errorf("unknown permission '%c'", c)
+		}
+	}
+
+	switch op {
+	case '+':
+		mode |= bits
+	case '-':
+		mode &^= bits
+	case '=':
+		mode = mode&^whoMask | bits
+	}
+	return mode, nil
+}
+
+// Changes permissions of the marked node. Remote trees are not supported.
+func (t *Tree) ChmodMarked(spec string) error {
+	if t.Marked == nil {
+		return nil
+	}
+	if !t.Local() {
+		return readOnly("chmod", t.Marked.Path)
+	}
+	mode, err := ParseMode(spec, t.Marked.Info.Mode())
+	if err != nil {
+		return err
+	}
+	if err := os.Chmod(t.Marked.Path, mode); err != nil {
+		return err
+	}
+	return t.refreshMarked()
+}
+
+// Changes owner of the marked node. Spec is "user", "user:group" or ":group",
+// names or numeric ids are accepted.
+func (t *Tree) ChownMarked(spec string) error {
+	if t.Marked == nil {
+		return nil
+	}
+	if !t.Local() {
+		return readOnly("chown", t.Marked.Path)
+	}
+	uid, gid, err := parseOwner(spec)
+	if err != nil {
+		return err
+	}
+	if err := os.Lchown(t.Marked.Path, uid, gid); err != nil {
+		return err
+	}
+	return t.refreshMarked()
+}
+
+// Re-reads info of the marked node, as watcher keeps existing nodes as is.
+func (t *Tree) refreshMarked() error {
+	info, err := os.Lstat(t.Marked.Path)
+	if err != nil {
+		return err
+	}
+	t.Marked.Info = info
+	t.Marked = nil
+	return nil
+}
+
+// Returns uid and gid to pass to chown, -1 means unchanged.
+func parseOwner(spec string) (int, int, error) {
+	spec = strings.TrimSpace(spec)
+	if spec == "" {
+		return 0, 0, fmt.Errorf("empty owner")
+	}
+	userPart, groupPart, _ := strings.Cut(spec, ":")
+	uid, gid := -1, -1
+	if userPart != "" {
+		id, err := lookupID(userPart, func(name string) (string, error) {
+			u, err := user.Lookup(name)
+			if err != nil {
+				return "", err
+			}
+			return u.Uid, nil
+		})
+		if err != nil {
+			return 0, 0, fmt.Errorf("unknown user '%s'", userPart)
+		}
+		uid = id
+	}
+	if groupPart != "" {
+		id, err := lookupID(groupPart, func(name string) (string, error) {
+			g, err := user.LookupGroup(name)
+			if err != nil {
+				return "", err
+			}
+			return g.Gid, nil
+		})
+		if err != nil {
+			return 0, 0, fmt.Errorf("unknown group '%s'", groupPart)
+		}
+		gid = id
+	}
+	return uid, gid, nil
+}
+
+func lookupID(nameOrID string, lookup func(string) (string, error)) (int, error) {
+	if id, err := strconv.Atoi(nameOrID); err == nil && id >= 0 {
+		return id, nil
+	}
+	s, err := lookup(nameOrID)
+	if err != nil {
+		return 0, err
+	}
+	return strconv.Atoi(s)
+}
@@ -0,0 +1,110 @@
+package tree
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+
+	"github.com/LeperGnome/bt/pkg/paths"
+)
+
+// Rename of a single path within its directory.
+type Rename struct {
+	Old string
+	New string
+}
+
+// Renames of paths, which names match find, to names with replacement applied.
+// Paths, that stay the same, are skipped.
+func PlanRegexRename(paths []string, find *regexp.Regexp, replace string) []Rename {
+	renames := []Rename{}
+	for _, p := range paths {
+		name := filepath.Base(p)
+		newName := find.ReplaceAllString(name, replace)
+		if newName != name {
+			renames = append(renames, Rename{Old: p, New: filepath.Join(filepath.Dir(p), newName)})
+		}
+	}
+	return renames
+}
+
+// Renames of paths to the given names, matched by position. Unchanged names are skipped.
+func PlanListRename(paths []string, names []string) ([]Rename, error) {
+	if len(paths) != len(names) {
+		return nil, fmt.Errorf("expected %d names, got %d", len(paths), len(names))
+	}
+	renames := []Rename{}
+	for i, p := range paths {
+		if names[i] != filepath.Base(p) {
+			renames = append(renames, Rename{Old: p, New: filepath.Join(filepath.Dir(p), names[i])})
+		}
+	}
+	return renames, nil
+}
+
+// Checks, that renames don't collide with each other or with existing files.
+// Returns errors for every conflicting rename.
+func CheckRenames(renames []Rename) error {
+	sources := map[string]bool{}
+	for _, r := range renames {
+		sources[r.Old] = true
+	}
+	targets := map[string]string{}
+	var errs []error
+	for _, r := range renames {
+		name := filepath.Base(r.New)
+		// empty names and names with separators end up in another directory
+		if name == "." || name == ".." || filepath.Dir(r.New) != filepath.Dir(r.Old) {
+			errs = append(errs, fmt.Errorf("invalid new name for '%s'", filepath.Base(r.Old)))
+			continue
+		}
+		if err := paths.CheckName(name); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if other, ok := targets[r.New]; ok {
+			errs = append(errs, fmt.Errorf("'%s' and '%s' both become '%s'", filepath.Base(other), filepath.Base(r.Old), name))
+			continue
+		}
+		targets[r.New] = r.Old
+		if _, err := os.Lstat(r.New); err == nil && !sources[r.New] {
+			errs = append(errs, fmt.Errorf("'%s' already exists", name))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Applies renames all or nothing. Every path is first moved to a temporary name,
+// so swaps and chains work. On failure, done renames are rolled back.
+func ApplyRenames(renames []Rename) error {
+	if err := CheckRenames(renames); err != nil {
+		return err
+	}
+	type step struct{ from, to string }
+	done := []step{}
+	rollback := func(err error) error {
+		for i := len(done) - 1; i >= 0; i-- {
+			if rbErr := os.Rename(done[i].to, done[i].from); rbErr != nil {
+				err = errors.Join(err, rbErr)
+			}
+		}
+		return err
+	}
+	temps := make([]string, len(renames))
+	for i, r := range renames {
+		temps[i] = filepath.Join(filepath.Dir(r.Old), fmt.Sprintf(".bt-rename-%d-%d", os.Getpid(), i))
+		if err := os.Rename(r.Old, temps[i]); err != nil {
+			return rollback(err)
+		}
+		done = append(done, step{r.Old, temps[i]})
+	}
+	for i, r := range renames {
+		if err := os.Rename(temps[i], r.New); err != nil {
+			return rollback(err)
+		}
+		done = append(done, step{temps[i], r.New})
+	}
+	return nil
+}
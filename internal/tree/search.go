@@ -0,0 +1,33 @@
+package tree
+
+// Returns nodes, shown in the tree (read and visible), in depth first order, root excluded.
+func (t *Tree) Shown() []*Node {
+	nodes := []*Node{}
+	var walk func(n *Node)
+	walk = func(n *Node) {
+		for _, ch := range n.Children {
+			if !t.Visible(ch) {
+				continue
+			}
+			nodes = append(nodes, ch)
+			walk(ch)
+		}
+	}
+	walk(t.Root)
+	return nodes
+}
+
+// Selects node, making its parent the current directory.
+func (t *Tree) Select(n *Node) {
+	if n.Parent == nil {
+		return
+	}
+	for c := n; c.Parent != nil; c = c.Parent {
+		for i, ch := range c.Parent.Children {
+			if ch == c {
+				c.Parent.selectedChildIdx = i
+			}
+		}
+	}
+	t.CurrentDir = n.Parent
+}
@@ -0,0 +1,17 @@
+package tree
+
+// Returns paths of expanded directories below the root, parents before children.
+func (t *Tree) ExpandedPaths() []string {
+	paths := []string{}
+	var walk func(n *Node)
+	walk = func(n *Node) {
+		for _, ch := range n.Children {
+			if ch.Children != nil {
+				paths = append(paths, ch.Path)
+				walk(ch)
+			}
+		}
+	}
+	walk(t.Root)
+	return paths
+}
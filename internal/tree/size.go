@@ -0,0 +1,50 @@
+package tree
+
+import (
+	"context"
+	"io/fs"
+	"path/filepath"
+
+	"github.com/LeperGnome/bt/internal/artifacts"
+)
+
+// Computes total size of files under path. Hardlinked files and directories,
+// reachable by multiple paths (bind mounts), are counted once.
+// Symlinks are not followed. Unreadable subdirectories are skipped, as well as
+// dependency and build directories, unless path is one of them itself.
+func DirSize(ctx context.Context, path string) (int64, error) {
+	seen := map[FileID]struct{}{}
+	var total int64
+	err := filepath.WalkDir(path, func(p string, d fs.DirEntry, err error) error {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return ctxErr
+		}
+		if err != nil {
+			if d != nil && d.IsDir() && p != path {
+				return fs.SkipDir
+			}
+			return nil
+		}
+		if d.IsDir() && p != path && artifacts.IsArtifactDir(p) {
+			return fs.SkipDir
+		}
+		info, err := d.Info()
+		if err != nil {
+			return nil
+		}
+		if id, ok := fileIDOf(info); ok {
+			if _, dup := seen[id]; dup {
+				if d.IsDir() {
+					return fs.SkipDir
+				}
+				return nil
+			}
+			seen[id] = struct{}{}
+		}
+		if info.Mode().IsRegular() {
+			total += info.Size()
+		}
+		return nil
+	})
+	return total, err
+}
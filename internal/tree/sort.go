@@ -0,0 +1,96 @@
+package tree
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Order of names within a directory. Zero value compares names ignoring case, char by char.
+type NameOrder struct {
+	Natural       bool // runs of digits compare by value: file2 goes before file10
+	CaseSensitive bool // upper case goes before lower case: B before a
+}
+
+// Returns sorting of nodes: directories first, then names in the order.
+func (o NameOrder) Sorting() NodeSortingFunc {
+	return func(a, b *Node) int {
+		if a.IsDir() != b.IsDir() {
+			if a.IsDir() {
+				return -1
+			}
+			return 1
+		}
+		return o.Compare(a.Info.Name(), b.Info.Name())
+	}
+}
+
+// Compares names in the order. Names, equal ignoring case, are ordered case-sensitively,
+// so the order is stable between reads.
+func (o NameOrder) Compare(a, b string) int {
+	if !o.CaseSensitive {
+		if c := o.compare(a, b, true); c != 0 {
+			return c
+		}
+	}
+	return o.compare(a, b, false)
+}
+
+func (o NameOrder) compare(a, b string, fold bool) int {
+	for a != "" && b != "" {
+		if o.Natural && isDigit(a[0]) && isDigit(b[0]) {
+			var na, nb string
+			na, a = digitRun(a)
+			nb, b = digitRun(b)
+			if c := compareNumbers(na, nb); c != 0 {
+				return c
+			}
+			continue
+		}
+		ra, sa := utf8.DecodeRuneInString(a)
+		rb, sb := utf8.DecodeRuneInString(b)
+		a, b = a[sa:], b[sb:]
+		if fold {
+			ra, rb = unicode.ToLower(ra), unicode.ToLower(rb)
+		}
+		if ra != rb {
+			if ra < rb {
+				return -1
+			}
+			return 1
+		}
+	}
+	return len(a) - len(b)
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+// Splits leading digits off s.
+func digitRun(s string) (string, string) {
+	i := 0
+	for i < len(s) && isDigit(s[i]) {
+		i++
+	}
+	return s[:i], s[i:]
+}
+
+// Compares decimal numbers of any length by value, then fewer leading zeros first.
+func compareNumbers(a, b string) int {
+	ta, tb := strings.TrimLeft(a, "0"), strings.TrimLeft(b, "0")
+	if len(ta) != len(tb) {
+		return len(ta) - len(tb)
+	}
+	if c := strings.Compare(ta, tb); c != 0 {
+		return c
+	}
+	return len(a) - len(b)
+}
+
+// Changes sorting of the tree. Loaded directories are re-sorted, keeping selection.
+func (t *Tree) SetSorting(sortingFunc NodeSortingFunc) {
+	t.sortingFunc = sortingFunc
+	t.resort(t.Root)
+	t.generation++
+}
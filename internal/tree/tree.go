@@ -1,29 +1,57 @@
 package tree
 
 import (
+	"errors"
 	"fmt"
 	"io"
 	"io/fs"
 	"os"
-	"os/exec"
 	"path/filepath"
 	"slices"
-	"strings"
 
-	"github.com/fsnotify/fsnotify"
+	"github.com/LeperGnome/bt/pkg/paths"
 )
 
+// Safety limit for expanding nested directories.
+const DefaultMaxDepth = 64
+
 type Tree struct {
 	Root        *Node
 	CurrentDir  *Node
 	Marked      *Node
+	MaxDepth    int
 	sortingFunc NodeSortingFunc
-	watcher     *fsnotify.Watcher
+	fsys        FS
+	watcher     Watcher
+
+	generation     int // incremented on every structure change
+	dups           map[*Node]*Node
+	dupsGeneration int
+
+	filter            string
+	entryType         EntryType
+	visible           map[*Node]bool
+	visibleGeneration int
+	visibleCurrent    *Node
+
+	grouped bool
+	folded  map[*Node]map[Group]bool // hidden groups of directory entries
+}
+
+// Returns number of changes of the tree structure or of what is shown of it. Anything, laid out
+// from the tree, is up to date, while it stays the same and current directory is the same too.
+func (t *Tree) Generation() int {
+	return t.generation
 }
 
 func (t *Tree) GetSelectedChild() *Node {
 	if len(t.CurrentDir.Children) > 0 {
-		return t.CurrentDir.Children[t.CurrentDir.selectedChildIdx]
+		t.fixSelection(t.CurrentDir)
+		selected := t.CurrentDir.Children[t.CurrentDir.selectedChildIdx]
+		if !t.Visible(selected) {
+			return nil
+		}
+		return selected
 	}
 	return nil
 }
@@ -34,60 +62,171 @@ func (t *Tree) RefreshNodeParentByPath(path string) error {
 outer:
 	for {
 		if parentDir == cur.Path {
-			return cur.readChildren(t.sortingFunc)
+			t.generation++
+			return cur.readChildren(t.fsys, t.sorting())
+		}
+		for _, ch := range cur.Children {
+			if paths.Within(ch.Path, path) {
+				cur = ch
+				continue outer
+			}
 		}
+		return nil
+	}
+}
+
+// Re-reads info of the loaded node at path, e.g. after it's written. Missing node is not an error.
+func (t *Tree) RefreshNodeInfo(path string) error {
+	cur := t.Root
+outer:
+	for cur.Path != path {
 		for _, ch := range cur.Children {
-			if strings.HasPrefix(path, ch.Path) {
+			if paths.Within(ch.Path, path) {
 				cur = ch
 				continue outer
 			}
 		}
 		return nil
 	}
+	info, err := t.fsys.Lstat(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil // removal is reported on its own
+	}
+	if err != nil {
+		return err
+	}
+	cur.Info = info
+	t.generation++
+	return nil
 }
 func (t *Tree) RenameMarked(name string) error {
 	if t.Marked == nil {
 		return nil
 	}
-	err := os.Rename(t.Marked.Path, filepath.Join(t.Marked.Parent.Path, name))
+	if err := paths.CheckName(name); err != nil {
+		return err
+	}
+	err := t.fsys.Rename(t.Marked.Path, filepath.Join(t.Marked.Parent.Path, name))
 	if err != nil {
 		return err
 	}
 	t.Marked = nil
 	return nil
 }
-func (t *Tree) CreateFileInCurrent(name string) error {
-	_, err := os.Create(filepath.Join(t.CurrentDir.Path, name))
-	return err
+
+// Creates file at name, relative to the current directory, e.g. src/utils/helpers.go,
+// with missing intermediate directories. Trailing separator creates a directory instead.
+// Created node gets selected. Returns its path.
+func (t *Tree) CreateFileInCurrent(name string) (string, error) {
+	if name != "" && os.IsPathSeparator(name[len(name)-1]) {
+		return t.CreateDirectoryInCurrent(name)
+	}
+	path, err := t.pathInCurrent(name)
+	if err != nil {
+		return "", err
+	}
+	if err := t.fsys.MkdirAll(filepath.Dir(path)); err != nil {
+		return "", err
+	}
+	if err := t.fsys.Create(path); err != nil {
+		return "", err
+	}
+	return path, t.selectCreated(path)
 }
-func (t *Tree) CreateDirectoryInCurrent(name string) error {
-	return os.Mkdir(filepath.Join(t.CurrentDir.Path, name), os.ModePerm)
+
+// Creates directory at name, relative to the current directory, with missing parents,
+// and selects it. Returns its path, empty if the directory already existed.
+func (t *Tree) CreateDirectoryInCurrent(name string) (string, error) {
+	path, err := t.pathInCurrent(name)
+	if err != nil {
+		return "", err
+	}
+	created := path
+	if _, err := t.fsys.Lstat(path); err == nil {
+		created = ""
+	}
+	if err := t.fsys.MkdirAll(path); err != nil {
+		return "", err
+	}
+	return created, t.selectCreated(path)
+}
+
+func (t *Tree) pathInCurrent(name string) (string, error) {
+	rel := filepath.Clean(filepath.FromSlash(name))
+	// checked first: reserved names aren't local on Windows either
+	for _, elem := range paths.Split(rel) {
+		if err := paths.CheckName(elem); err != nil {
+			return "", err
+		}
+	}
+	if !filepath.IsLocal(rel) {
+		return "", fmt.Errorf("'%s' is outside of the current directory", name)
+	}
+	return filepath.Join(t.CurrentDir.Path, rel), nil
+}
+
+// Selects just created path, re-reading loaded directories on the way,
+// so it doesn't wait for file system events.
+func (t *Tree) selectCreated(path string) error {
+	rel, ok := paths.Rel(t.CurrentDir.Path, path)
+	if !ok {
+		return fmt.Errorf("'%s' is outside of the current directory", path)
+	}
+	cur := t.CurrentDir
+	for _, name := range paths.Split(rel) {
+		if cur.Children == nil {
+			break // not loaded, read on reveal
+		}
+		if err := cur.readChildren(t.fsys, t.sorting()); err != nil {
+			return err
+		}
+		idx := slices.IndexFunc(cur.Children, func(n *Node) bool { return n.Info.Name() == name })
+		if idx < 0 {
+			break
+		}
+		cur = cur.Children[idx]
+	}
+	t.generation++
+	if err := t.Reveal(filepath.Dir(path)); err != nil {
+		return err
+	}
+	name := filepath.Base(path)
+	if idx := slices.IndexFunc(t.CurrentDir.Children, func(n *Node) bool { return n.Info.Name() == name }); idx >= 0 {
+		t.CurrentDir.selectedChildIdx = idx
+	}
+	return nil
 }
 func (t *Tree) ReadSelectedChildContent(buf []byte, limit int64) (int, error) {
 	selectedNode := t.GetSelectedChild()
 	if selectedNode == nil || !selectedNode.Info.Mode().IsRegular() {
 		return 0, fmt.Errorf("file not selected or is irregular")
 	}
-	f, err := os.Open(selectedNode.Path)
-	defer f.Close()
+	f, err := t.fsys.Open(selectedNode.Path)
 	if err != nil {
 		return 0, err
 	}
-	limitedReader := io.LimitReader(f, limit)
-	n, err := limitedReader.Read(buf)
-	if err != nil {
+	defer f.Close()
+	// network file systems return short reads, buffer is filled up to the limit
+	n, err := io.ReadFull(f, buf[:min(int64(len(buf)), limit)])
+	if err != nil && err != io.ErrUnexpectedEOF && err != io.EOF {
 		return 0, err
 	}
 	return n, nil
 }
 func (t *Tree) SelectNextChild() {
-	if t.CurrentDir.selectedChildIdx < len(t.CurrentDir.Children)-1 {
-		t.CurrentDir.selectedChildIdx += 1
+	for i := t.CurrentDir.selectedChildIdx + 1; i < len(t.CurrentDir.Children); i++ {
+		if t.Visible(t.CurrentDir.Children[i]) {
+			t.CurrentDir.selectedChildIdx = i
+			return
+		}
 	}
 }
 func (t *Tree) SelectPreviousChild() {
-	if t.CurrentDir.selectedChildIdx > 0 {
-		t.CurrentDir.selectedChildIdx -= 1
+	for i := t.CurrentDir.selectedChildIdx - 1; i >= 0; i-- {
+		if t.Visible(t.CurrentDir.Children[i]) {
+			t.CurrentDir.selectedChildIdx = i
+			return
+		}
 	}
 }
 func (t *Tree) SetSelectedChildAsCurrent() error {
@@ -95,15 +234,17 @@ func (t *Tree) SetSelectedChildAsCurrent() error {
 	if selectedChild == nil {
 		return nil
 	}
-	if !selectedChild.Info.IsDir() {
+	if !selectedChild.IsDir() {
 		return nil
 	}
 	if selectedChild.Children == nil {
-		err := selectedChild.readChildren(t.sortingFunc)
+		err := t.expandNode(selectedChild)
 		if err != nil {
 			return err
 		}
-		t.watcher.Add(selectedChild.Path)
+		if selectedChild.Loop {
+			return nil
+		}
 	}
 	t.CurrentDir = selectedChild
 	return nil
@@ -128,84 +269,150 @@ func (t *Tree) MarkSelectedChild() bool {
 func (t *Tree) DropMark() {
 	t.Marked = nil
 }
-func (t *Tree) DeleteMarked() error {
-	if t.Marked == nil {
+
+// Returns path, that doesn't exist yet, prefixing the name with "copy_" as many times, as needed.
+func (t *Tree) FreeName(path string) (string, error) {
+	dir := filepath.Dir(path)
+	name, err := generateNewFileName(t.fsys, filepath.Base(path), dir)
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, name), nil
+}
+func (t *Tree) CollapseOrExpandSelected() error {
+	selectedChild := t.GetSelectedChild()
+	if selectedChild == nil {
 		return nil
 	}
-	cmd := exec.Command("rm", "-r", t.Marked.Path)
-	err := cmd.Run()
-	if err != nil {
-		return err // todo: this is not the same error...?
+	if selectedChild.Children != nil {
+		t.collapse(selectedChild)
+	} else {
+		return t.expandNode(selectedChild)
 	}
-	t.Marked = nil
 	return nil
 }
-func (t *Tree) CopyMarkedToCurrentDir() error {
-	if t.Marked == nil {
+
+// Reads node children and starts watching it.
+// Nodes, that resolve to one of their ancestors, are marked as loops and not expanded.
+func (t *Tree) expandNode(n *Node) error {
+	if !n.IsDir() {
 		return nil
 	}
-	targetDir := t.CurrentDir.Path
-	targetFileName, err := generateNewFileName(t.Marked.Info.Name(), targetDir)
-	if err != nil {
-		return err
+	if n.Depth() >= t.MaxDepth {
+		return fmt.Errorf("max depth %d reached, not expanding '%s'", t.MaxDepth, n.Path)
 	}
-	targetPath := filepath.Join(targetDir, targetFileName)
-
-	cmd := exec.Command("cp", "-r", t.Marked.Path, targetPath)
-	err = cmd.Run()
+	if n.findLoop(t.fsys) != nil {
+		n.Loop = true
+		return nil
+	}
+	err := n.readChildren(t.fsys, t.sorting())
 	if err != nil {
-		return err // todo: this is not the same error...?
+		return err
 	}
-	t.Marked = nil
+	t.generation++
+	t.watcher.Add(n.Path)
 	return nil
 }
-func (t *Tree) MoveMarkedToCurrentDir() error {
-	if t.Marked == nil {
-		return nil
-	}
-	targetDir := t.CurrentDir.Path
-	targetFileName, err := generateNewFileName(t.Marked.Info.Name(), targetDir)
+
+// Returns file system, the tree is read from.
+func (t *Tree) FS() FS {
+	return t.fsys
+}
+
+// Reports whether the tree is on the local file system.
+func (t *Tree) Local() bool {
+	return t.fsys == OS
+}
+
+// Stops watching the tree for changes.
+func (t *Tree) Close() error {
+	return t.watcher.Close()
+}
+
+// Makes dir a new tree root, dropping all expanded state.
+func (t *Tree) SetRoot(dir string) error {
+	root, err := newRootNode(t.fsys, dir, t.sorting())
 	if err != nil {
 		return err
 	}
-	targetPath := filepath.Join(targetDir, targetFileName)
-
-	cmd := exec.Command("mv", t.Marked.Path, targetPath)
-	err = cmd.Run()
+	for _, p := range t.watcher.WatchList() {
+		t.watcher.Remove(p)
+	}
+	err = t.watcher.Add(root.Path)
 	if err != nil {
-		return err // todo: this is not the same error...?
+		return err
 	}
+	t.Root = root
+	t.CurrentDir = root
 	t.Marked = nil
+	t.folded = nil
+	t.generation++
 	return nil
 }
-func (t *Tree) CollapseOrExpandSelected() error {
-	selectedChild := t.GetSelectedChild()
-	if selectedChild == nil {
-		return nil
+
+// Expands all directories on the way from root to path and selects it.
+// If path is a directory, it becomes current.
+func (t *Tree) Reveal(path string) error {
+	rel, err := t.relToRoot(path)
+	if err != nil {
+		return err
 	}
-	if selectedChild.Children != nil {
-		selectedChild.orphanChildren()
-		t.watcher.Remove(selectedChild.Path)
-	} else {
-		err := selectedChild.readChildren(t.sortingFunc)
-		if err != nil {
+	cur := t.Root
+	for _, name := range paths.Split(rel) {
+		if cur.Children == nil {
+			if err := t.expandNode(cur); err != nil {
+				return err
+			}
+		}
+		idx := slices.IndexFunc(cur.Children, func(n *Node) bool { return n.Info.Name() == name })
+		if idx < 0 {
+			return fmt.Errorf("'%s' not found", path)
+		}
+		cur.selectedChildIdx = idx
+		cur = cur.Children[idx]
+	}
+	if cur.IsDir() && cur.Children == nil {
+		if err := t.expandNode(cur); err != nil {
 			return err
 		}
-		t.watcher.Add(selectedChild.Path)
+	}
+	if cur.IsDir() && !cur.Loop {
+		t.CurrentDir = cur
+	} else if cur.Parent != nil {
+		t.CurrentDir = cur.Parent
 	}
 	return nil
 }
 
-func InitTree(dir string, sortingFunc NodeSortingFunc) (*Tree, <-chan NodeChange, error) {
-	rootInfo, err := os.Lstat(dir)
+// Reports whether path is inside of the tree root.
+func (t *Tree) Contains(path string) bool {
+	_, err := t.relToRoot(path)
+	return err == nil
+}
+
+func (t *Tree) relToRoot(path string) (string, error) {
+	absRoot, err := filepath.Abs(t.Root.Path)
 	if err != nil {
-		return nil, nil, err
+		return "", err
 	}
-	if !rootInfo.IsDir() {
-		return nil, nil, fmt.Errorf("%s is not a directory", dir)
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return "", err
 	}
-	if sortingFunc == nil {
-		sortingFunc = defaultNodeSorting
+	rel, ok := paths.Rel(absRoot, absPath)
+	if !ok {
+		return "", fmt.Errorf("'%s' is outside of '%s'", path, t.Root.Path)
+	}
+	return rel, nil
+}
+
+func newRootNode(fsys FS, dir string, sortingFunc NodeSortingFunc) (*Node, error) {
+	rootInfo, err := fsys.Lstat(dir)
+	if err != nil {
+		return nil, err
+	}
+	if !rootInfo.IsDir() {
+		return nil, fmt.Errorf("%s is not a directory", dir)
 	}
 
 	root := &Node{
@@ -214,29 +421,48 @@ func InitTree(dir string, sortingFunc NodeSortingFunc) (*Tree, <-chan NodeChange
 		Parent:   nil,
 		Children: []*Node{},
 	}
+	root.id, root.hasID = fileIDOf(rootInfo)
 
-	err = root.readChildren(sortingFunc)
+	err = root.readChildren(fsys, sortingFunc)
 	if err != nil {
-		return nil, nil, err
+		return nil, err
 	}
 	if len(root.Children) == 0 {
-		return nil, nil, fmt.Errorf("Can't initialize on empty directory '%s'", dir)
+		return nil, fmt.Errorf("Can't initialize on empty directory '%s'", dir)
+	}
+	return root, nil
+}
+
+func InitTree(dir string, sortingFunc NodeSortingFunc) (*Tree, <-chan NodeChange, error) {
+	return InitTreeFS(OS, dir, sortingFunc)
+}
+
+// Initializes tree, read from fsys, see FromFS.
+func InitTreeFS(fsys FS, dir string, sortingFunc NodeSortingFunc) (*Tree, <-chan NodeChange, error) {
+	if sortingFunc == nil {
+		sortingFunc = defaultNodeSorting
+	}
+	root, err := newRootNode(fsys, dir, sortingFunc)
+	if err != nil {
+		return nil, nil, err
 	}
 
-	watcher, err := fsnotify.NewWatcher()
+	watcher, changeChan, err := fsys.Watch()
 	if err != nil {
 		return nil, nil, err
 	}
-	changeChan := runFSWatcher(watcher)
 	err = watcher.Add(root.Path)
 	if err != nil {
+		watcher.Close()
 		return nil, nil, err
 	}
 
 	tree := &Tree{
 		Root:        root,
 		CurrentDir:  root,
+		MaxDepth:    DefaultMaxDepth,
 		sortingFunc: sortingFunc,
+		fsys:        fsys,
 		watcher:     watcher,
 	}
 	return tree, changeChan, nil
@@ -244,8 +470,8 @@ func InitTree(dir string, sortingFunc NodeSortingFunc) (*Tree, <-chan NodeChange
 
 // Checks if fname already exists in targetDir.
 // Adds "copy_" prefix (multiple times), until new file name becomes unique in derecotry.
-func generateNewFileName(fname, targetDir string) (string, error) {
-	currentDirContent, err := os.ReadDir(targetDir)
+func generateNewFileName(fsys FS, fname, targetDir string) (string, error) {
+	currentDirContent, err := fsys.ReadDir(targetDir)
 	if err != nil {
 		return "", err
 	}
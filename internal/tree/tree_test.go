@@ -0,0 +1,154 @@
+package tree
+
+import (
+	"io/fs"
+	"path/filepath"
+	"slices"
+	"testing"
+	"testing/fstest"
+)
+
+func testTree(t *testing.T) *Tree {
+	t.Helper()
+	fsys := fstest.MapFS{
+		"root/b.txt":          {Data: []byte("b")},
+		"root/copy_a.txt":     {Data: []byte("copy")},
+		"root/a.txt":          {Data: []byte("a")},
+		"root/src/main.go":    {Data: []byte("package main")},
+		"root/src/lib/lib.go": {Data: []byte("package lib")},
+		"root/docs/index.md":  {Data: []byte("# docs")},
+	}
+	tree, _, err := InitTreeFS(FromFS(fsys), "root", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { tree.Close() })
+	return tree
+}
+
+func names(nodes []*Node) []string {
+	out := make([]string, len(nodes))
+	for i, n := range nodes {
+		out[i] = n.Info.Name()
+	}
+	return out
+}
+
+func TestInitTreeFS(t *testing.T) {
+	tree := testTree(t)
+	if tree.CurrentDir != tree.Root {
+		t.Errorf("current dir is %s, want root", tree.CurrentDir.Path)
+	}
+	want := []string{"docs", "src", "a.txt", "b.txt", "copy_a.txt"}
+	if got := names(tree.Root.Children); !slices.Equal(got, want) {
+		t.Errorf("root children are %v, want %v", got, want)
+	}
+	for _, ch := range tree.Root.Children {
+		if ch.Parent != tree.Root {
+			t.Errorf("parent of %s is not root", ch.Path)
+		}
+		if ch.Children != nil {
+			t.Errorf("%s is read before it's expanded", ch.Path)
+		}
+	}
+	if selected := tree.GetSelectedChild(); selected == nil || selected.Path != filepath.Join("root", "docs") {
+		t.Errorf("selected %v, want root/docs", selected)
+	}
+}
+
+func TestInitTreeFSErrors(t *testing.T) {
+	fsys := FromFS(fstest.MapFS{
+		"file.txt":  {Data: []byte("text")},
+		"empty/dir": {Mode: fs.ModeDir | 0o755},
+	})
+	for _, dir := range []string{"missing", "file.txt", "empty/dir"} {
+		if _, _, err := InitTreeFS(fsys, dir, nil); err == nil {
+			t.Errorf("tree is initialized on %s", dir)
+		}
+	}
+}
+
+func TestCollapseOrExpandSelected(t *testing.T) {
+	tree := testTree(t)
+	tree.SelectNextChild() // src
+	src := tree.GetSelectedChild()
+	generation := tree.Generation()
+
+	if err := tree.CollapseOrExpandSelected(); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := names(src.Children), []string{"lib", "main.go"}; !slices.Equal(got, want) {
+		t.Errorf("expanded src has %v, want %v", got, want)
+	}
+	if tree.Generation() == generation {
+		t.Error("generation is the same after expand")
+	}
+	if err := tree.SetSelectedChildAsCurrent(); err != nil {
+		t.Fatal(err)
+	}
+	if err := tree.CollapseOrExpandSelected(); err != nil { // lib
+		t.Fatal(err)
+	}
+	lib := src.Children[0]
+	if lib.Children == nil {
+		t.Fatal("lib isn't expanded")
+	}
+
+	tree.SetParentAsCurrent()
+	generation = tree.Generation()
+	if err := tree.CollapseOrExpandSelected(); err != nil {
+		t.Fatal(err)
+	}
+	if src.Children != nil || lib.Children != nil {
+		t.Error("src is collapsed with its subdirectories expanded")
+	}
+	if tree.Generation() == generation {
+		t.Error("generation is the same after collapse")
+	}
+}
+
+func TestExpandFile(t *testing.T) {
+	tree := testTree(t)
+	for range 2 {
+		tree.SelectNextChild()
+	}
+	file := tree.GetSelectedChild()
+	if err := tree.CollapseOrExpandSelected(); err != nil {
+		t.Fatal(err)
+	}
+	if file.Children != nil {
+		t.Errorf("file %s got children", file.Path)
+	}
+}
+
+func TestExpandMaxDepth(t *testing.T) {
+	tree := testTree(t)
+	tree.MaxDepth = 1
+	tree.SelectNextChild() // src
+	if err := tree.CollapseOrExpandSelected(); err == nil {
+		t.Error("src is expanded beyond max depth")
+	}
+}
+
+func TestFreeName(t *testing.T) {
+	tree := testTree(t)
+	for _, c := range []struct{ path, want string }{
+		{"root/new.txt", "root/new.txt"},
+		{"root/b.txt", "root/copy_b.txt"},
+		{"root/a.txt", "root/copy_copy_a.txt"},
+		{"root/src", "root/copy_src"},
+		{"root/src/main.go", "root/src/copy_main.go"},
+	} {
+		got, err := tree.FreeName(filepath.FromSlash(c.path))
+		if err != nil {
+			t.Errorf("FreeName(%s): %v", c.path, err)
+			continue
+		}
+		if want := filepath.FromSlash(c.want); got != want {
+			t.Errorf("FreeName(%s) = %s, want %s", c.path, got, want)
+		}
+	}
+	if _, err := tree.FreeName("root/missing/file"); err == nil {
+		t.Error("free name is found in a missing directory")
+	}
+}
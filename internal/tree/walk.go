@@ -0,0 +1,41 @@
+package tree
+
+import "fmt"
+
+// Reads directory tree down to depth levels (0 - up to DefaultMaxDepth), without watching it.
+// Unreadable directories are reported to onErr and left without children.
+// Symlinks to directories are not descended into, like in `tree` command.
+func Walk(dir string, depth int, onErr func(path string, err error)) (*Node, error) {
+	info, err := OS.Stat(dir)
+	if err != nil {
+		return nil, err
+	}
+	if !info.IsDir() {
+		return nil, fmt.Errorf("%s is not a directory", dir)
+	}
+	if depth <= 0 {
+		depth = DefaultMaxDepth
+	}
+	root := &Node{Path: dir, Info: info}
+	root.id, root.hasID = fileIDOf(info)
+	walkNode(root, depth, onErr)
+	return root, nil
+}
+
+func walkNode(n *Node, depth int, onErr func(path string, err error)) {
+	if depth == 0 {
+		return
+	}
+	if err := n.readChildren(OS, defaultNodeSorting); err != nil {
+		if onErr != nil {
+			onErr(n.Path, err)
+		}
+		return
+	}
+	for _, ch := range n.Children {
+		if !ch.Info.IsDir() {
+			continue
+		}
+		walkNode(ch, depth-1, onErr)
+	}
+}
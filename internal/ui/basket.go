@@ -0,0 +1,31 @@
+package ui
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/LeperGnome/bt/internal/i18n"
+	"github.com/LeperGnome/bt/internal/state"
+)
+
+// Renders paths in the basket, keeping cursor in view.
+func (r *Renderer) renderBasket(b *state.Basket, height, width int) string {
+	lines := []string{i18n.T("ui.basket-hint")}
+	for i, p := range b.Paths {
+		arrow := r.listCursor(i == b.Cursor)
+		size := ""
+		if info, err := os.Lstat(p); err == nil {
+			if info.IsDir() {
+				p += string(os.PathSeparator)
+			} else {
+				size = formatSize(float64(info.Size()), 1024.0)
+			}
+		}
+		lines = append(lines, fmt.Sprintf("%s%9s  %s", arrow, size, p))
+	}
+	cursorLine := b.Cursor + 1
+	start := max(min(cursorLine-height/2, len(lines)-height), 0)
+	end := min(start+height, len(lines))
+	return r.Style.CleanupContent.MaxWidth(width).Render(strings.Join(lines[start:end], "\n"))
+}
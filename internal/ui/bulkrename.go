@@ -0,0 +1,27 @@
+package ui
+
+import (
+	"path/filepath"
+	"strings"
+
+	"github.com/LeperGnome/bt/internal/i18n"
+	"github.com/LeperGnome/bt/internal/state"
+)
+
+// Renders planned renames as "old → new", conflicts first.
+func (r *Renderer) renderBulkRename(b *state.BulkRenameSession, height, width int) string {
+	lines := []string{}
+	if b.Err != nil {
+		for _, l := range strings.Split(b.Err.Error(), "\n") {
+			lines = append(lines, r.Style.ErrBar.Render(l))
+		}
+	}
+	if len(b.Renames) == 0 && b.Err == nil {
+		lines = append(lines, i18n.T("ui.bulk-rename-hint"))
+	}
+	for _, rn := range b.Renames {
+		lines = append(lines, filepath.Base(rn.Old)+" "+r.Style.Glyphs.RenameArrow+" "+r.Style.BulkRenameNew.Render(filepath.Base(rn.New)))
+	}
+	lines = lines[:min(len(lines), height)]
+	return r.Style.BulkRenameContent.MaxWidth(width).Render(strings.Join(lines, "\n"))
+}
@@ -0,0 +1,31 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/LeperGnome/bt/internal/i18n"
+	"github.com/LeperGnome/bt/internal/state"
+)
+
+const churnBarWidth = 10
+
+// Renders most changed files with commit counts and bars, relative to the hottest file.
+func (r *Renderer) renderChurn(report *state.ChurnReport, height, width int) string {
+	if report.Files == nil {
+		return i18n.T("ui.churn-loading")
+	}
+	if len(report.Files) == 0 {
+		return i18n.T("ui.churn-empty")
+	}
+	top := report.Files[0].Commits
+	countWidth := len(fmt.Sprint(top))
+	lines := []string{}
+	for _, f := range report.Files[:min(height, len(report.Files))] {
+		filled := max(f.Commits*churnBarWidth/top, 1)
+		bar := r.Style.ChurnBar.Render(strings.Repeat(r.Style.Glyphs.BarDone, filled)) + strings.Repeat(" ", churnBarWidth-filled)
+		line := fmt.Sprintf("%*d %s %s", countWidth, f.Commits, bar, f.Path)
+		lines = append(lines, line)
+	}
+	return r.Style.ChurnContent.MaxWidth(width).Render(strings.Join(lines, "\n"))
+}
@@ -0,0 +1,67 @@
+package ui
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/LeperGnome/bt/internal/cleanup"
+	"github.com/LeperGnome/bt/internal/i18n"
+	"github.com/LeperGnome/bt/internal/state"
+)
+
+var reasonKeys = map[cleanup.Reason]string{
+	cleanup.ReasonCache:     "ui.cleanup-cache",
+	cleanup.ReasonDuplicate: "ui.cleanup-duplicate",
+	cleanup.ReasonLarge:     "ui.cleanup-large",
+	cleanup.ReasonOld:       "ui.cleanup-old",
+}
+
+// Renders candidates grouped by reason, keeping cursor in view.
+func (r *Renderer) renderCleanup(c *state.CleanupSession, height, width int) string {
+	if c.Report == nil {
+		return r.Style.CleanupContent.Render(i18n.T("ui.cleanup-scanning"))
+	}
+	if len(c.Report.Groups) == 0 {
+		return r.Style.CleanupContent.Render(i18n.T("ui.cleanup-empty"))
+	}
+	lines := []string{
+		fmt.Sprintf(i18n.T("ui.cleanup-selected"), formatSize(float64(c.SelectedSize()), 1024.0)),
+	}
+	cursorLine := 0
+	idx := 0
+	for _, g := range c.Report.Groups {
+		header := fmt.Sprintf(
+			"%s (%d, %s)",
+			i18n.T(reasonKeys[g.Reason]), len(g.Candidates), formatSize(float64(g.Savings()), 1024.0),
+		)
+		lines = append(lines, r.Style.CleanupGroup.Render(header))
+		for _, cand := range g.Candidates {
+			mark := "[ ]"
+			if c.Selected[cand.Path] {
+				mark = r.Style.CleanupSelected.Render("[x]")
+			}
+			arrow := r.listCursor(idx == c.Cursor)
+			if idx == c.Cursor {
+				cursorLine = len(lines)
+			}
+			rel, err := filepath.Rel(c.Root, cand.Path)
+			if err != nil {
+				rel = cand.Path
+			}
+			line := fmt.Sprintf("%s%s %9s  %s", arrow, mark, formatSize(float64(cand.Size), 1024.0), rel)
+			if cand.Note != "" {
+				if noteRel, err := filepath.Rel(c.Root, cand.Note); err == nil && g.Reason == cleanup.ReasonDuplicate {
+					line += " = " + noteRel
+				} else {
+					line += " (" + cand.Note + ")"
+				}
+			}
+			lines = append(lines, line)
+			idx++
+		}
+	}
+	start := max(min(cursorLine-height/2, len(lines)-height), 0)
+	end := min(start+height, len(lines))
+	return r.Style.CleanupContent.MaxWidth(width).Render(strings.Join(lines[start:end], "\n"))
+}
@@ -0,0 +1,54 @@
+package ui
+
+import (
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/mattn/go-runewidth"
+
+	"github.com/LeperGnome/bt/internal/state"
+)
+
+// Renders entries of the current directory in columns, filled top to bottom, like ls -C.
+// Rows scroll to keep the selected entry visible, the first line names the directory.
+func (r *Renderer) renderColumns(s *state.State, height, width int, focused bool) string {
+	arrowStyle := r.Style.TreeSelectionArrow
+	if !focused {
+		arrowStyle = r.Style.TreeSelectionArrowUnfocused
+	}
+	tree := s.Tree
+	lines := []string{r.Style.TreeDirecotryName.Render(sanitize(tree.CurrentDir.Info.Name()))}
+
+	entries := s.Entries()
+	if len(entries) == 0 {
+		lines = append(lines, emptydirContentName+arrowStyle.Render(r.Style.Glyphs.Cursor))
+		return lipgloss.NewStyle().MaxWidth(width).Render(strings.Join(lines, "\n"))
+	}
+	rows, cellWidth := s.ColumnGrid(entries)
+	nameWidth := cellWidth - state.ColumnGap
+
+	selectedRow := 0
+	selected := tree.GetSelectedChild()
+	for i, e := range entries {
+		if e == selected {
+			selectedRow = i % rows
+		}
+	}
+	offset, limit := r.cropTree(tree, rows, selectedRow, max(height-1, 1))
+
+	for row := offset; row < limit; row++ {
+		var line strings.Builder
+		for i := row; i < len(entries); i += rows {
+			node := entries[i]
+			mark := strings.Repeat(" ", state.ColumnGap)
+			if node == selected {
+				mark = arrowStyle.Render(r.Style.Glyphs.ColumnCursor)
+			}
+			name := truncateMarked(sanitize(node.Info.Name()), nameWidth, r.Style.Glyphs.Ellipsis)
+			pad := strings.Repeat(" ", max(nameWidth-runewidth.StringWidth(name), 0))
+			line.WriteString(mark + r.markName(s, tree, node, r.nameStyle(node).Render(name)) + pad)
+		}
+		lines = append(lines, strings.TrimRight(line.String(), " "))
+	}
+	return lipgloss.NewStyle().MaxWidth(width).Render(strings.Join(lines, "\n"))
+}
@@ -0,0 +1,44 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/LeperGnome/bt/internal/i18n"
+	"github.com/LeperGnome/bt/internal/state"
+)
+
+// Renders totals and what is going to be removed: entries of a single directory or removed paths.
+func (r *Renderer) renderDeletion(p *state.DeletePlan, height, width int) string {
+	var summary string
+	switch {
+	case p.Counting:
+		summary = i18n.T("ui.delete-counting")
+	case p.Err != nil:
+		summary = r.Style.ErrBar.Render(fmt.Sprintf(i18n.T("ui.delete-failed"), p.Err))
+	case p.Files > 0 || p.Bytes > 0:
+		summary = fmt.Sprintf(i18n.T("ui.delete-total"), p.Files, formatSize(float64(p.Bytes), 1024.0))
+	default:
+		summary = i18n.T("ui.delete-uncounted")
+	}
+	lines := []string{summary, i18n.T("ui.delete-hint")}
+	listed := p.Paths
+	if len(p.Paths) == 1 {
+		listed = p.Entries
+		if len(listed) == 0 {
+			listed = []string{i18n.T("ui.delete-empty-dir")}
+		}
+	}
+	rows := max(height-len(lines), 0)
+	for i, l := range listed {
+		if i == rows-1 && len(listed) > rows {
+			lines = append(lines, fmt.Sprintf(i18n.T("ui.delete-more"), len(listed)-i))
+			break
+		}
+		if i == rows {
+			break
+		}
+		lines = append(lines, "  "+sanitize(l))
+	}
+	return r.Style.CleanupContent.MaxWidth(width).Render(strings.Join(lines, "\n"))
+}
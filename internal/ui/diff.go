@@ -0,0 +1,29 @@
+package ui
+
+import (
+	"strings"
+)
+
+// Renders unified diff with added and removed lines colored.
+func (r *Renderer) renderDiff(diff string, offset, height, width int) string {
+	lines := strings.Split(strings.TrimSuffix(diff, "\n"), "\n")
+	lines = lines[min(offset, len(lines)):]
+	lines = lines[:max(min(height, len(lines)), 0)]
+	for i, line := range lines {
+		text := truncateToWidth(expandTabs(line), width)
+		switch {
+		case strings.HasPrefix(line, "+++"), strings.HasPrefix(line, "---"),
+			strings.HasPrefix(line, "diff "), strings.HasPrefix(line, "index "):
+			lines[i] = r.Style.DiffHeader.Render(text)
+		case strings.HasPrefix(line, "@@"):
+			lines[i] = r.Style.DiffHunk.Render(text)
+		case strings.HasPrefix(line, "+"):
+			lines[i] = r.Style.DiffAdded.Render(text)
+		case strings.HasPrefix(line, "-"):
+			lines[i] = r.Style.DiffRemoved.Render(text)
+		default:
+			lines[i] = text
+		}
+	}
+	return r.Style.ContentPreview.MaxWidth(width).Render(strings.Join(lines, "\n"))
+}
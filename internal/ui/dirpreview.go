@@ -0,0 +1,45 @@
+package ui
+
+import (
+	"fmt"
+	"io/fs"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+
+	"github.com/LeperGnome/bt/internal/i18n"
+	"github.com/LeperGnome/bt/internal/state"
+)
+
+// Renders listing of the selected directory: counts and total size first, then entries
+// with sizes of files, starting from offset entry.
+func (r *Renderer) renderDirPreview(l state.DirListing, offset, height, width int) string {
+	lines := []string{r.Style.TreeDetails.Render(fmt.Sprintf(i18n.T("ui.dir-preview"), l.Dirs, l.Files, formatSize(float64(l.Size), 1024.0)))}
+	if len(l.Entries) == 0 {
+		lines = append(lines, r.Style.ContentPreview.Render(i18n.T("ui.dir-empty")))
+	}
+	offset = min(offset, len(l.Entries))
+	for _, e := range l.Entries[offset:] {
+		if len(lines) >= height {
+			break
+		}
+		size := ""
+		style := r.Style.TreeRegularFileName
+		switch {
+		case e.Mode.IsDir():
+			style = r.Style.TreeDirecotryName
+		case e.Mode&fs.ModeSymlink != 0:
+			style = r.Style.TreeLinkName
+		default:
+			size = formatSize(float64(e.Size), 1024.0)
+		}
+		if st, ok := r.Names.Style(e.Name, e.Mode); ok {
+			style = st
+		}
+		lines = append(lines, fmt.Sprintf("%9s  %s", size, style.Render(sanitize(e.Name))))
+	}
+	if l.More > 0 && len(lines) < height {
+		lines = append(lines, r.Style.TreeDetails.Render(fmt.Sprintf(i18n.T("ui.dir-more"), l.More)))
+	}
+	return lipgloss.NewStyle().MaxWidth(width).Render(strings.Join(lines, "\n"))
+}
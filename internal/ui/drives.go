@@ -0,0 +1,19 @@
+package ui
+
+import (
+	"strings"
+
+	"github.com/LeperGnome/bt/internal/state"
+)
+
+// Renders roots of drives, one per line, with the cursor.
+func (r *Renderer) renderDrives(s *state.State, height, width int) string {
+	lines := make([]string, 0, len(s.Drives))
+	for i, drive := range s.Drives {
+		arrow := r.listCursor(i == s.DriveCursor)
+		lines = append(lines, arrow+drive)
+	}
+	start := max(min(s.DriveCursor-height/2, len(lines)-height), 0)
+	end := min(start+height, len(lines))
+	return r.Style.BookmarkPicker.MaxWidth(width).Render(strings.Join(lines[start:end], "\n"))
+}
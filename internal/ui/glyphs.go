@@ -0,0 +1,114 @@
+package ui
+
+import (
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/mattn/go-runewidth"
+)
+
+// Characters, the interface is drawn with. Simple ones are plain ASCII, with textual marks
+// instead of styles, for screen readers and terminals without Unicode.
+type Glyphs struct {
+	Cursor            string // after the entry under cursor
+	ColumnCursor      string // before the entry under cursor in columns, ColumnGap wide
+	ListCursor        string // before the line under cursor in pickers
+	Loop              string
+	Locked            string // after names of entries, that can't be changed
+	IndentParent      string
+	IndentCurrent     string
+	IndentCurrentLast string
+	Ellipsis          string // end of cut text
+	Separator         string // between fields of heading and status line
+	Dash              string // before notes
+	RenameArrow       string // between old and new names
+	Compare           string // between names of compared files
+	BarDone           string
+	BarLeft           string
+	GroupOpen         string
+	GroupFolded       string
+	// After names of entries, that can't be read, empty - only styles show it.
+	Unreadable string
+	// Before names, empty - only styles show it.
+	SelectedMark string
+	TossedMark   string
+	MarkedMark   string
+}
+
+var DefaultGlyphs = Glyphs{
+	Cursor:            " <-",
+	ColumnCursor:      " > ",
+	ListCursor:        "> ",
+	Loop:              " ↻",
+	Locked:            " 🔒",
+	IndentParent:      "│  ",
+	IndentCurrent:     "├─ ",
+	IndentCurrentLast: "└─ ",
+	Ellipsis:          "…",
+	Separator:         "│",
+	Dash:              "—",
+	RenameArrow:       "→",
+	Compare:           "↔",
+	BarDone:           "█",
+	BarLeft:           "░",
+	GroupOpen:         "▾ ",
+	GroupFolded:       "▸ ",
+}
+
+var SimpleGlyphs = Glyphs{
+	Cursor:            " [*]",
+	ColumnCursor:      "[*]",
+	ListCursor:        "[*] ",
+	Loop:              " (loop)",
+	Locked:            " (read-only)",
+	IndentParent:      "|  ",
+	IndentCurrent:     "|- ",
+	IndentCurrentLast: "`- ",
+	Ellipsis:          "~",
+	Separator:         "|",
+	Dash:              "-",
+	RenameArrow:       "->",
+	Compare:           "<->",
+	BarDone:           "#",
+	BarLeft:           ".",
+	GroupOpen:         "[-] ",
+	GroupFolded:       "[+] ",
+	Unreadable:        " (no access)",
+	SelectedMark:      "[x] ",
+	TossedMark:        "[del] ",
+	MarkedMark:        "[mark] ",
+}
+
+// Pane border of plain ASCII characters.
+var asciiBorder = lipgloss.Border{
+	Top:          "-",
+	Bottom:       "-",
+	Left:         "|",
+	Right:        "|",
+	TopLeft:      "+",
+	TopRight:     "+",
+	BottomLeft:   "+",
+	BottomRight:  "+",
+	MiddleLeft:   "+",
+	MiddleRight:  "+",
+	Middle:       "+",
+	MiddleTop:    "+",
+	MiddleBottom: "+",
+}
+
+// Builds styles for the simple mode: no colors, no Unicode glyphs, an explicit cursor mark.
+func NewSimpleStylesheet() Stylesheet {
+	s := NewStylesheet(MonoTheme)
+	s.Glyphs = SimpleGlyphs
+	s.PaneBorder = asciiBorder
+	s.TreeMarkedNode = lipgloss.NewStyle()
+	return s
+}
+
+// Returns cursor mark of a picker line, or blank of the same width.
+func (r *Renderer) listCursor(on bool) string {
+	if on {
+		return r.Style.CleanupCursor.Render(r.Style.Glyphs.ListCursor)
+	}
+	return strings.Repeat(" ", runewidth.StringWidth(r.Style.Glyphs.ListCursor))
+}
@@ -0,0 +1,37 @@
+package ui
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/LeperGnome/bt/internal/i18n"
+	"github.com/LeperGnome/bt/internal/state"
+)
+
+// Renders search matches as "path:line: text", keeping cursor in view.
+func (r *Renderer) renderGrep(g *state.GrepSession, height, width int) string {
+	status := fmt.Sprintf(i18n.T("ui.grep-count"), len(g.Matches))
+	if !g.Done {
+		status += " " + i18n.T("ui.grep-searching")
+		if r.ReducedMotion {
+			status = i18n.T("ui.grep-searching")
+		}
+	}
+	lines := []string{status}
+	for i, m := range g.Matches {
+		arrow := r.listCursor(i == g.Cursor)
+		rel, err := filepath.Rel(g.Root, m.Path)
+		if err != nil {
+			rel = m.Path
+		}
+		loc := r.Style.GrepLocation.Render(fmt.Sprintf("%s:%d:", rel, m.Line))
+		lines = append(lines, arrow+loc+" "+expandTabs(m.Text))
+	}
+	// status line stays on top
+	rows := height - 1
+	start := max(min(g.Cursor-rows/2, len(g.Matches)-rows), 0)
+	end := min(start+rows, len(g.Matches))
+	visible := append([]string{lines[0]}, lines[1+start:1+end]...)
+	return r.Style.GrepContent.MaxWidth(width).Render(strings.Join(visible, "\n"))
+}
@@ -0,0 +1,286 @@
+package ui
+
+import (
+	"fmt"
+	"math"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/mattn/go-runewidth"
+
+	"github.com/LeperGnome/bt/internal/i18n"
+	"github.com/LeperGnome/bt/internal/state"
+	t "github.com/LeperGnome/bt/internal/tree"
+	"github.com/LeperGnome/bt/pkg/paths"
+)
+
+// Heading is the part of the screen above panes: tab bar, selected path,
+// file info, operation bar, input and error. Lines are wrapped to the width,
+// so the heading knows its real height and can shrink to fit.
+type heading struct {
+	style         *Stylesheet
+	s             *state.State
+	width         int
+	reducedMotion bool
+}
+
+type headingLine struct {
+	text string
+	drop int // lines with higher values are dropped first, 0 - never dropped
+}
+
+// Lines are dropped in this order, when space is tight.
+const (
+	keepLine = iota
+	dropTabBar
+	dropFileInfo
+)
+
+// Renders heading of at most maxHeight lines and returns it with its height.
+func (h heading) Render(maxHeight int) (string, int) {
+	lines := h.lines()
+	for _, drop := range []int{dropFileInfo, dropTabBar} {
+		if h.height(lines) <= maxHeight {
+			break
+		}
+		kept := lines[:0]
+		for _, l := range lines {
+			if l.drop != drop {
+				kept = append(kept, l)
+			}
+		}
+		lines = kept
+	}
+	rendered := make([]string, 0, len(lines))
+	for _, l := range lines {
+		rendered = append(rendered, h.wrap(l.text))
+	}
+	out := lipgloss.NewStyle().MaxHeight(max(maxHeight, 1)).Render(strings.Join(rendered, "\n"))
+	return out, lipgloss.Height(out)
+}
+
+func (h heading) height(lines []headingLine) int {
+	total := 0
+	for _, l := range lines {
+		total += lipgloss.Height(h.wrap(l.text))
+	}
+	return total
+}
+
+func (h heading) wrap(text string) string {
+	return lipgloss.NewStyle().Width(h.width).Render(text)
+}
+
+func (h heading) lines() []headingLine {
+	lines := []headingLine{}
+	if len(h.s.Tabs) > 1 {
+		lines = append(lines, headingLine{h.tabBar(), dropTabBar})
+	}
+	lines = append(lines,
+		headingLine{h.pathLine(), keepLine},
+		headingLine{h.fileInfo(), dropFileInfo},
+		headingLine{h.style.OperationBar.Render(h.operationBar()), keepLine},
+	)
+	if c := h.s.SelectedChecksums(); c != nil {
+		lines = append(lines, h.checksums(c)...)
+	}
+	if j := h.s.Job; j != nil && j.Estimate >= state.SlowJob {
+		warning := fmt.Sprintf(i18n.T("ui.job-slow"),
+			formatSize(float64(j.Progress.TotalBytes), 1024.0), j.FS, formatDuration(j.Estimate))
+		lines = append(lines, headingLine{h.style.ErrBar.Render(warning), keepLine})
+	}
+	if h.s.OpBuf.IsInput() {
+		input := "-> " + h.inputWithCursor()
+		lines = append(lines, headingLine{h.style.OperationBar.Render(input), keepLine})
+		if c := h.s.Completions(); len(c) > 0 {
+			lines = append(lines, headingLine{h.style.StatusBar.Render(strings.Join(c, "  ")), keepLine})
+		}
+	}
+	if h.s.ErrBuf != "" {
+		lines = append(lines, headingLine{h.style.ErrBar.Render(h.s.ErrBuf), keepLine})
+	}
+	return lines
+}
+
+// Returns lines with checksums, or a line, that they're being computed.
+func (h heading) checksums(c *state.Checksums) []headingLine {
+	if c.Computing {
+		return []headingLine{{h.style.StatusBar.Render(i18n.T("ui.checksums")), dropFileInfo}}
+	}
+	if c.Err != nil {
+		return nil
+	}
+	lines := []headingLine{}
+	for _, sum := range [][2]string{{"md5   ", c.MD5}, {"sha1  ", c.SHA1}, {"sha256", c.SHA256}} {
+		lines = append(lines, headingLine{h.style.StatusBar.Render(sum[0]) + " " + sum[1], dropFileInfo})
+	}
+	return lines
+}
+
+// Renders input with the cursor, shown on the character under it or after the end.
+// Selected input is highlighted as a whole.
+func (h heading) inputWithCursor() string {
+	buf := h.s.InputBuf
+	if h.s.InputSelected {
+		return h.style.InputCursor.Render(sanitize(string(buf)))
+	}
+	pos := min(max(h.s.InputPos, 0), len(buf))
+	under := " "
+	after := ""
+	if pos < len(buf) {
+		under = string(buf[pos])
+		after = string(buf[pos+1:])
+	}
+	return h.style.OperationBarInput.Render(sanitize(string(buf[:pos]))) +
+		h.style.InputCursor.Render(sanitize(under)) +
+		h.style.OperationBarInput.Render(sanitize(after))
+}
+
+// Selected path with the help hint on the right. Hint is omitted, when it doesn't fit.
+func (h heading) pathLine() string {
+	path := paths.Join(h.s.Tree.CurrentDir.Path, "...") // NOTE: special case for empty dir
+	if selected := h.s.Tree.GetSelectedChild(); selected != nil {
+		path = selected.Path
+	}
+	rawPath := "> " + sanitize(path)
+	hint := i18n.T("ui.help-hint")
+	gap := h.width - runewidth.StringWidth(rawPath) - runewidth.StringWidth(hint)
+	if gap < 1 {
+		return h.style.SelectedPath.Render(rawPath)
+	}
+	return h.style.SelectedPath.Render(rawPath) + strings.Repeat(" ", gap) + h.style.HelpMsg.Render(hint)
+}
+
+func (h heading) fileInfo() string {
+	changeTime := "--"
+	size := "0 B"
+	perm := "--"
+	if selected := h.s.Tree.GetSelectedChild(); selected != nil {
+		changeTime = selected.Info.ModTime().Format(time.RFC822)
+		size = formatSize(float64(selected.Info.Size()), 1024.0)
+		perm = selected.Info.Mode().String()
+		if total, ok := h.s.DirSizes[selected.Path]; ok {
+			size += " " + fmt.Sprintf(i18n.T("ui.total-size"), formatSize(float64(total), 1024.0))
+		} else if h.s.IsSizing(selected.Path) {
+			size += " " + i18n.T("ui.sizing")
+		}
+	}
+	return fmt.Sprintf(
+		"%s %s %v %s %s",
+		h.style.FinfoPermissions.Render(perm),
+		h.style.FinfoSep.Render(h.style.Glyphs.Separator),
+		h.style.FinfoLastUpdated.Render(changeTime),
+		h.style.FinfoSep.Render(h.style.Glyphs.Separator),
+		h.style.FinfoSize.Render(size),
+	)
+}
+
+func (h heading) operationBar() string {
+	bar := fmt.Sprintf(": %s", h.s.OpBuf.Repr())
+	if keys := h.s.PendingKeys(); keys != "" {
+		bar = strings.TrimRight(bar, " ") + " " + keys
+	}
+	if f := h.s.Tree.Filter(); f != "" && h.s.OpBuf != state.FilterInput {
+		bar += " " + h.style.FilterIndicator.Render(fmt.Sprintf(i18n.T("ui.filter"), f))
+	}
+	switch h.s.Tree.EntryType() {
+	case t.DirsOnly:
+		bar += " " + h.style.FilterIndicator.Render(i18n.T("ui.dirs-only"))
+	case t.FilesOnly:
+		bar += " " + h.style.FilterIndicator.Render(i18n.T("ui.files-only"))
+	}
+	if n := len(h.s.Basket.Paths); n > 0 && h.s.OpBuf != state.BasketView && h.s.OpBuf != state.BasketConfirm {
+		bar += " " + h.style.FilterIndicator.Render(fmt.Sprintf(i18n.T("ui.basket"), n))
+	}
+	if conflict := h.s.ConflictPath(); conflict != "" {
+		bar += fmt.Sprintf(" [%s]", conflict)
+	} else if guarded := h.s.GuardPath(); guarded != "" {
+		bar += fmt.Sprintf(" [%s]", guarded)
+	} else if marked := h.s.MarkedNode(); marked != nil {
+		bar += fmt.Sprintf(" [%s]", marked.Path)
+	}
+	if h.s.Job != nil {
+		bar += " " + h.jobProgress(h.s.Job)
+	}
+	if len(h.s.Artifacts) > 0 {
+		names := make([]string, 0, len(h.s.Artifacts))
+		for _, p := range h.s.Artifacts {
+			names = append(names, filepath.Base(p))
+		}
+		bar += fmt.Sprintf(" [%s]", strings.Join(names, ", "))
+	}
+	return bar
+}
+
+const progressBarWidth = 20
+
+var jobTitles = map[string]string{
+	state.JobCopy:    "ui.job-copy",
+	state.JobMove:    "ui.job-move",
+	state.JobDelete:  "ui.job-delete",
+	state.JobArchive: "ui.job-archive",
+	state.JobExtract: "ui.job-extract",
+}
+
+// Renders title, progress bar and counters of the running file operation.
+func (h heading) jobProgress(j *state.FileJob) string {
+	p := j.Progress
+	done := 0.0
+	switch {
+	case p.TotalBytes > 0:
+		done = float64(p.Bytes) / float64(p.TotalBytes)
+	case p.TotalFiles > 0:
+		done = float64(p.Files) / float64(p.TotalFiles)
+	}
+	if h.reducedMotion {
+		done = math.Floor(done*10) / 10 // progress moves by tenths, not on every chunk copied
+	}
+	filled := int(min(done, 1) * progressBarWidth)
+	bar := h.style.JobProgressDone.Render(strings.Repeat(h.style.Glyphs.BarDone, filled)) +
+		h.style.JobProgressLeft.Render(strings.Repeat(h.style.Glyphs.BarLeft, progressBarWidth-filled))
+	counters := fmt.Sprintf(i18n.T("ui.job-progress"),
+		p.Files, p.TotalFiles,
+		formatSize(float64(p.Bytes), 1024.0), formatSize(float64(p.TotalBytes), 1024.0))
+	if eta, ok := j.ETA(); ok {
+		counters += ", " + fmt.Sprintf(i18n.T("ui.job-eta"), formatDuration(eta))
+	}
+	hint := ""
+	if keys := h.s.Keymap[state.ActionCancelJob]; len(keys) > 0 {
+		hint = ", " + fmt.Sprintf(i18n.T("ui.job-cancel"), keys[0])
+	}
+	if h.reducedMotion {
+		// ticking counters and ETA are left out
+		counters, hint = "", strings.TrimPrefix(hint, ", ")
+	}
+	name := filepath.Base(j.Src)
+	if len(j.Paths) > 1 {
+		name += fmt.Sprintf(" +%d", len(j.Paths)-1)
+	}
+	title := fmt.Sprintf(i18n.T(jobTitles[j.Kind]), name)
+	return strings.TrimSpace(fmt.Sprintf("[%s %s %d%% %s", title, bar, int(min(done, 1)*100), counters+hint)) + "]"
+}
+
+// Formats duration rounded to seconds, e.g. 1h2m3s.
+func formatDuration(d time.Duration) string {
+	return max(d.Round(time.Second), time.Second).String()
+}
+
+func (h heading) tabBar() string {
+	tabs := make([]string, 0, len(h.s.Tabs))
+	for i, tab := range h.s.Tabs {
+		title := tab.Title()
+		if i == h.s.ActiveTab {
+			title = filepath.Base(h.s.Tree.CurrentDir.Path)
+		}
+		label := fmt.Sprintf(" %d:%s ", i+1, title)
+		if i == h.s.ActiveTab {
+			label = h.style.TabActive.Render(label)
+		} else {
+			label = h.style.TabInactive.Render(label)
+		}
+		tabs = append(tabs, label)
+	}
+	return lipgloss.NewStyle().MaxWidth(h.width).Render(strings.Join(tabs, ""))
+}
@@ -0,0 +1,69 @@
+package ui
+
+import (
+	"strings"
+
+	"github.com/mattn/go-runewidth"
+
+	"github.com/LeperGnome/bt/internal/state"
+)
+
+const (
+	minHelpColumnWidth = 50
+	helpColumnGap      = 4
+)
+
+// Returns keys, bound to the action in the keymap.
+func helpKeys(km state.Keymap, id state.ActionID) string {
+	keys := km[id]
+	if len(keys) == 0 {
+		return "-"
+	}
+	return strings.Join(keys, " / ")
+}
+
+// Renders all actions with their current bindings. Rows are split into columns,
+// when that makes them fit. Otherwise, a single column is scrolled by HelpOffset.
+func (r *Renderer) renderHelp(s *state.State, height, width int) string {
+	keys := make([]string, len(state.Actions))
+	keyWidth := 0
+	for i, id := range state.Actions {
+		keys[i] = helpKeys(s.Keymap, id)
+		keyWidth = max(keyWidth, runewidth.StringWidth(keys[i]))
+	}
+	keyWidth += 2
+
+	cols := 1
+	if height > 0 && len(keys) > height {
+		need := (len(keys) + height - 1) / height
+		if need*minHelpColumnWidth+(need-1)*helpColumnGap <= width {
+			cols = need
+		}
+	}
+	colWidth := (width - (cols-1)*helpColumnGap) / cols
+	perCol := (len(keys) + cols - 1) / cols
+
+	offset := 0
+	if cols == 1 {
+		offset = max(min(s.HelpOffset, len(keys)-height), 0)
+	}
+	lines := make([]string, 0, min(perCol, max(height, 0)))
+	for row := offset; row < perCol && row-offset < height; row++ {
+		line := ""
+		for c := 0; c < cols; c++ {
+			i := c*perCol + row
+			if i >= len(keys) {
+				break
+			}
+			key := truncateToWidth(runewidth.FillRight(keys[i], keyWidth), colWidth)
+			desc := truncateToWidth(state.Actions[i].Description(), colWidth-runewidth.StringWidth(key))
+			if c > 0 {
+				line += strings.Repeat(" ", helpColumnGap)
+			}
+			line += r.Style.HelpKey.Render(key) +
+				r.Style.HelpContent.Render(runewidth.FillRight(desc, colWidth-runewidth.StringWidth(key)))
+		}
+		lines = append(lines, line)
+	}
+	return strings.Join(lines, "\n")
+}
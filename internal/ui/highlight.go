@@ -0,0 +1,45 @@
+package ui
+
+import (
+	"strings"
+
+	"github.com/alecthomas/chroma/v2/formatters"
+	"github.com/alecthomas/chroma/v2/lexers"
+	"github.com/alecthomas/chroma/v2/styles"
+)
+
+type highlightCache struct {
+	text, lexer, style string
+	out                string
+}
+
+// Highlights source text with the named lexer. The last result is cached,
+// as preview is redrawn on every key press. Returns false, when theme has no code style.
+func (r *Renderer) highlight(text, lexer string) (string, bool) {
+	if r.Style.CodeStyle == "" {
+		return "", false
+	}
+	c := r.highlightMem
+	if c.text == text && c.lexer == lexer && c.style == r.Style.CodeStyle {
+		return c.out, true
+	}
+	l := lexers.Get(lexer)
+	if l == nil {
+		return "", false
+	}
+	// tabs are expanded before highlighting, escape sequences would break tab stops
+	lines := strings.Split(text, "\n")
+	for i, line := range lines {
+		lines[i] = expandTabs(line)
+	}
+	it, err := l.Tokenise(nil, strings.Join(lines, "\n"))
+	if err != nil {
+		return "", false
+	}
+	var b strings.Builder
+	if err := formatters.TTY256.Format(&b, styles.Get(r.Style.CodeStyle), it); err != nil {
+		return "", false
+	}
+	r.highlightMem = highlightCache{text: text, lexer: lexer, style: r.Style.CodeStyle, out: b.String()}
+	return r.highlightMem.out, true
+}
@@ -0,0 +1,37 @@
+package ui
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/LeperGnome/bt/internal/i18n"
+	"github.com/LeperGnome/bt/internal/state"
+)
+
+// Renders the journal of file operations, newest first, skipping JournalOffset newest ones.
+// Undone operations and the ones, that can't be undone, are marked.
+func (r *Renderer) renderJournal(s *state.State, height, width int) string {
+	if len(s.Journal) == 0 {
+		return r.Style.StatusBar.Render(i18n.T("ui.no-journal"))
+	}
+	lines := make([]string, 0, height)
+	for i := len(s.Journal) - 1 - s.JournalOffset; i >= 0 && len(lines) < height; i-- {
+		e := s.Journal[i]
+		text := i18n.T("ui.op-"+e.Kind) + " " + sanitize(filepath.Base(e.Paths[0]))
+		if len(e.Targets) > 0 {
+			text += " " + r.Style.Glyphs.RenameArrow + " " + sanitize(e.Targets[0])
+		}
+		if len(e.Paths) > 1 {
+			text += fmt.Sprintf(" (+%d)", len(e.Paths)-1)
+		}
+		switch {
+		case i >= s.JournalPos:
+			text = r.Style.StatusBar.Render(text + " " + i18n.T("ui.undone"))
+		case !e.Reversible():
+			text += " " + r.Style.ErrBar.Render(i18n.T("ui.irreversible"))
+		}
+		lines = append(lines, r.Style.StatusBar.Render(e.Time.Format("15:04:05"))+" "+text)
+	}
+	return r.Style.BookmarkPicker.MaxWidth(width).Render(strings.Join(lines, "\n"))
+}
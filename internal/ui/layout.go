@@ -0,0 +1,97 @@
+package ui
+
+import (
+	"math"
+
+	"github.com/LeperGnome/bt/internal/state"
+)
+
+type rightPaneKind int
+
+const (
+	rightNone rightPaneKind = iota
+	rightPreview
+	rightSecondTree
+	rightBookmarks
+	rightChurn
+	rightCleanup
+	rightBulkRename
+	rightSnapshots
+	rightGrep
+	rightBasket
+	rightRecent
+	rightShell
+	rightCompare
+	rightDeletion
+	rightTail
+	rightMessages
+	rightDrives
+	rightJournal
+	rightRegisters
+	rightStaging
+)
+
+// Describes how the space below heading is split between panes.
+type layout struct {
+	height     int
+	leftWidth  int
+	rightWidth int
+	right      rightPaneKind
+	rightFocus bool
+}
+
+func computeLayout(s *state.State, height, width int) layout {
+	l := layout{height: height}
+	switch {
+	case s.OpBuf == state.BookmarkJump:
+		l.right = rightBookmarks
+	case s.OpBuf == state.RecentPick:
+		l.right = rightRecent
+	case s.OpBuf == state.MessageLog:
+		l.right = rightMessages
+	case s.OpBuf == state.DrivePick:
+		l.right = rightDrives
+	case s.OpBuf == state.JournalView:
+		l.right = rightJournal
+	case s.OpBuf == state.RegisterPick || s.OpBuf == state.RegisterUse:
+		l.right = rightRegisters
+	case s.OpBuf == state.StagingView:
+		l.right = rightStaging
+	case s.OpBuf == state.SnapshotPick:
+		l.right = rightSnapshots
+	case s.OpBuf == state.BasketView || s.OpBuf == state.BasketConfirm:
+		l.right = rightBasket
+	case s.Deletion != nil:
+		l.right = rightDeletion
+	case s.Shell != nil:
+		l.right = rightShell
+	case s.Compare != nil:
+		l.right = rightCompare
+	case s.Tail != nil:
+		l.right = rightTail
+	case s.Grep != nil:
+		l.right = rightGrep
+	case s.BulkRename != nil:
+		l.right = rightBulkRename
+	case s.Cleanup != nil:
+		l.right = rightCleanup
+		l.rightFocus = true
+	case s.ChurnToggle:
+		l.right = rightChurn
+	case s.DualPane:
+		l.right = rightSecondTree
+		l.rightFocus = s.Focus == state.PaneSecondTree
+	case s.PreviewToggle:
+		l.right = rightPreview
+		l.rightFocus = s.Focus == state.PanePreview
+	}
+	if l.right == rightNone {
+		l.leftWidth = width
+		return l
+	}
+	// section is devided vertically by split ratio
+	// left for tree, right for file preview
+	l.leftWidth = int(math.Floor(s.SplitRatio * float64(width)))
+	l.rightWidth = width - l.leftWidth
+	return l
+}
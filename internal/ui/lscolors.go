@@ -0,0 +1,206 @@
+package ui
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"strconv"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// Colors of entry names by file type and name suffix, as in LS_COLORS.
+type NameColors struct {
+	types    map[string]lipgloss.Style // by LS_COLORS type key: di, ln, ex, ...
+	suffixes []suffixStyle             // later rules win
+}
+
+type suffixStyle struct {
+	suffix string // lower case
+	style  lipgloss.Style
+}
+
+// Value of ls_colors in config, that turns LS_COLORS off.
+const LsColorsOff = "off"
+
+// Resolves name colors from $LS_COLORS (env) and rules from config on top of it.
+// Broken entries of env are skipped, like ls does, broken config entries are errors.
+// Returns nil, when there are no rules, colors are disabled or config turns them off.
+func ResolveNameColors(env, configured string) (*NameColors, error) {
+	if configured == LsColorsOff || colorDisabled() {
+		return nil, nil
+	}
+	c := &NameColors{types: map[string]lipgloss.Style{}}
+	c.parse(env)
+	if err := c.parse(configured); err != nil {
+		return nil, fmt.Errorf("ls_colors: %w", err)
+	}
+	if len(c.types) == 0 && len(c.suffixes) == 0 {
+		return nil, nil
+	}
+	return c, nil
+}
+
+// Adds rules, given like "di=01;34:*.tar=01;31", skipping broken ones.
+func (c *NameColors) parse(spec string) error {
+	var errs []error
+	for _, entry := range strings.Split(spec, ":") {
+		if entry == "" {
+			continue
+		}
+		key, sgr, ok := strings.Cut(entry, "=")
+		if sgr == "target" {
+			continue // links are colored as links, targets aren't resolved for that
+		}
+		if !ok || key == "" {
+			errs = append(errs, fmt.Errorf("bad entry %q, expected key=codes", entry))
+			continue
+		}
+		style, set, err := parseSGR(sgr)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", key, err))
+			continue
+		}
+		if !set {
+			continue // reset only, theme colors stay
+		}
+		if suffix, ok := strings.CutPrefix(key, "*"); ok {
+			c.suffixes = append(c.suffixes, suffixStyle{suffix: strings.ToLower(suffix), style: style})
+			continue
+		}
+		c.types[key] = style
+	}
+	return errors.Join(errs...)
+}
+
+// Returns style of SGR codes, e.g. "01;38;5;208". Reports false, when codes set nothing.
+func parseSGR(sgr string) (lipgloss.Style, bool, error) {
+	style := lipgloss.NewStyle()
+	set := false
+	codes := strings.Split(sgr, ";")
+	for i := 0; i < len(codes); i++ {
+		code, err := strconv.Atoi(codes[i])
+		if err != nil {
+			return style, false, fmt.Errorf("bad code %q", codes[i])
+		}
+		changed := true
+		switch {
+		case code == 0:
+			style, changed = lipgloss.NewStyle(), false
+			set = false
+		case code == 1:
+			style = style.Bold(true)
+		case code == 2:
+			style = style.Faint(true)
+		case code == 3:
+			style = style.Italic(true)
+		case code == 4:
+			style = style.Underline(true)
+		case code == 5 || code == 6:
+			style = style.Blink(true)
+		case code == 7:
+			style = style.Reverse(true)
+		case code == 9:
+			style = style.Strikethrough(true)
+		case code >= 30 && code <= 37:
+			style = style.Foreground(lipgloss.Color(strconv.Itoa(code - 30)))
+		case code >= 90 && code <= 97:
+			style = style.Foreground(lipgloss.Color(strconv.Itoa(code - 90 + 8)))
+		case code >= 40 && code <= 47:
+			style = style.Background(lipgloss.Color(strconv.Itoa(code - 40)))
+		case code >= 100 && code <= 107:
+			style = style.Background(lipgloss.Color(strconv.Itoa(code - 100 + 8)))
+		case code == 38 || code == 48:
+			color, n, err := extendedColor(codes[i+1:])
+			if err != nil {
+				return style, false, err
+			}
+			i += n
+			if code == 38 {
+				style = style.Foreground(color)
+			} else {
+				style = style.Background(color)
+			}
+		default:
+			changed = false // default colors and the rest keep theme ones
+		}
+		set = set || changed
+	}
+	return style, set, nil
+}
+
+// Parses color after 38 or 48: 5;n or 2;r;g;b. Returns number of codes taken.
+func extendedColor(codes []string) (lipgloss.Color, int, error) {
+	nums := make([]int, 0, 4)
+	for _, c := range codes[:min(len(codes), 4)] {
+		n, err := strconv.Atoi(c)
+		if err != nil || n < 0 || n > 255 {
+			return "", 0, fmt.Errorf("bad color code %q", c)
+		}
+		nums = append(nums, n)
+	}
+	switch {
+	case len(nums) >= 2 && nums[0] == 5:
+		return lipgloss.Color(strconv.Itoa(nums[1])), 2, nil
+	case len(nums) == 4 && nums[0] == 2:
+		return lipgloss.Color(fmt.Sprintf("#%02x%02x%02x", nums[1], nums[2], nums[3])), 4, nil
+	}
+	return "", 0, errors.New("bad extended color, expected 5;n or 2;r;g;b")
+}
+
+// Returns style of the entry with name and mode. Reports false, when no rule matches.
+func (c *NameColors) Style(name string, mode fs.FileMode) (lipgloss.Style, bool) {
+	if c == nil {
+		return lipgloss.Style{}, false
+	}
+	key := "fi"
+	switch {
+	case mode.IsDir():
+		key = "di"
+		if mode&0o002 != 0 {
+			key = "ow"
+			if mode&fs.ModeSticky != 0 {
+				key = "tw"
+			}
+		} else if mode&fs.ModeSticky != 0 {
+			key = "st"
+		}
+	case mode&fs.ModeSymlink != 0:
+		key = "ln"
+	case mode&fs.ModeNamedPipe != 0:
+		key = "pi"
+	case mode&fs.ModeSocket != 0:
+		key = "so"
+	case mode&fs.ModeCharDevice != 0:
+		key = "cd"
+	case mode&fs.ModeDevice != 0:
+		key = "bd"
+	case mode&fs.ModeSetuid != 0:
+		key = "su"
+	case mode&fs.ModeSetgid != 0:
+		key = "sg"
+	case mode&0o111 != 0:
+		key = "ex"
+	}
+	if style, ok := c.types[key]; ok && key != "fi" {
+		return style, true
+	}
+	if mode.IsDir() {
+		style, ok := c.types["di"]
+		return style, ok
+	}
+	if key == "fi" || key == "su" || key == "sg" || key == "ex" {
+		// suffixes apply to regular files, which have no own rule
+		lower := strings.ToLower(name)
+		for i := len(c.suffixes) - 1; i >= 0; i-- {
+			if strings.HasSuffix(lower, c.suffixes[i].suffix) {
+				return c.suffixes[i].style, true
+			}
+		}
+	}
+	if style, ok := c.types["fi"]; ok && mode.IsRegular() {
+		return style, true
+	}
+	return lipgloss.Style{}, false
+}
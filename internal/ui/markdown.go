@@ -0,0 +1,38 @@
+package ui
+
+import (
+	"path/filepath"
+	"strings"
+
+	"github.com/charmbracelet/glamour"
+)
+
+func isMarkdown(path string) bool {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".md", ".markdown":
+		return true
+	default:
+		return false
+	}
+}
+
+// Renders markdown wrapped to width. Term renderer is cached between calls
+// and recreated only when width changes.
+func (r *Renderer) renderMarkdown(content string, width int) (string, error) {
+	if r.mdRenderer == nil || r.mdWidth != width {
+		mdr, err := glamour.NewTermRenderer(
+			glamour.WithStandardStyle(r.Style.MarkdownStyle),
+			glamour.WithWordWrap(width),
+		)
+		if err != nil {
+			return "", err
+		}
+		r.mdRenderer = mdr
+		r.mdWidth = width
+	}
+	out, err := r.mdRenderer.Render(content)
+	if err != nil {
+		return "", err
+	}
+	return strings.Trim(out, "\n"), nil
+}
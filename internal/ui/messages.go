@@ -0,0 +1,26 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/LeperGnome/bt/internal/i18n"
+	"github.com/LeperGnome/bt/internal/state"
+)
+
+// Renders the message log, newest first, skipping MessageOffset newest ones.
+func (r *Renderer) renderMessages(s *state.State, height, width int) string {
+	if len(s.Messages) == 0 {
+		return r.Style.StatusBar.Render(i18n.T("ui.no-messages"))
+	}
+	lines := make([]string, 0, height)
+	for i := len(s.Messages) - 1 - s.MessageOffset; i >= 0 && len(lines) < height; i-- {
+		m := s.Messages[i]
+		text := sanitize(m.Text)
+		if m.Count > 1 {
+			text += fmt.Sprintf(" (x%d)", m.Count)
+		}
+		lines = append(lines, r.Style.StatusBar.Render(m.Time.Format("15:04:05"))+" "+r.Style.ErrBar.Render(text))
+	}
+	return r.Style.BookmarkPicker.MaxWidth(width).Render(strings.Join(lines, "\n"))
+}
@@ -0,0 +1,49 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/mattn/go-runewidth"
+)
+
+// Draws content in a box of exactly width x height cells, with title embedded into the top border.
+// Border and title look is taken from the stylesheet.
+func (r *Renderer) renderPane(title, content string, width, height int, focused bool) string {
+	border := r.Style.PaneBorder
+	borderStyle := r.Style.PaneBorderColor
+	titleStyle := r.Style.PaneTitle
+	if focused {
+		borderStyle = r.Style.PaneBorderFocusedColor
+		titleStyle = r.Style.PaneTitleFocused
+	}
+	innerWidth := max(width-2, 0)
+	innerHeight := max(height-2, 0)
+
+	title = truncateToWidth(fmt.Sprintf(r.Style.PaneTitleFormat, sanitize(title)), innerWidth)
+	fill := max(innerWidth-runewidth.StringWidth(title), 0)
+	top := borderStyle.Render(border.TopLeft) +
+		titleStyle.Render(title) +
+		borderStyle.Render(strings.Repeat(border.Top, fill)+border.TopRight)
+
+	body := lipgloss.NewStyle().MaxWidth(innerWidth).MaxHeight(innerHeight).Render(content)
+	box := lipgloss.NewStyle().
+		Width(innerWidth).
+		Height(innerHeight).
+		Border(border, false, true, true, true).
+		BorderForeground(borderStyle.GetForeground()).
+		Render(body)
+	return top + "\n" + box
+}
+
+// Joins panes vertically, skipping empty ones.
+func stackPanes(panes ...string) string {
+	nonEmpty := []string{}
+	for _, p := range panes {
+		if p != "" {
+			nonEmpty = append(nonEmpty, p)
+		}
+	}
+	return lipgloss.JoinVertical(lipgloss.Left, nonEmpty...)
+}
@@ -0,0 +1,19 @@
+package ui
+
+import (
+	"strings"
+
+	"github.com/LeperGnome/bt/internal/state"
+)
+
+// Renders recently visited directories, most recent first, keeping cursor in view.
+func (r *Renderer) renderRecent(s *state.State, height, width int) string {
+	lines := make([]string, 0, len(s.Recent))
+	for i, dir := range s.Recent {
+		arrow := r.listCursor(i == s.RecentCursor)
+		lines = append(lines, arrow+dir)
+	}
+	start := max(min(s.RecentCursor-height/2, len(lines)-height), 0)
+	end := min(start+height, len(lines))
+	return r.Style.BookmarkPicker.MaxWidth(width).Render(strings.Join(lines[start:end], "\n"))
+}
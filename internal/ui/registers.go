@@ -0,0 +1,35 @@
+package ui
+
+import (
+	"fmt"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/LeperGnome/bt/internal/i18n"
+	"github.com/LeperGnome/bt/internal/state"
+)
+
+// Renders non-empty registers in order of names, with names of their paths.
+// The register, picked for use, is under the cursor.
+func (r *Renderer) renderRegisters(s *state.State, height, width int) string {
+	if len(s.Registers) == 0 {
+		return r.Style.StatusBar.Render(i18n.T("ui.no-registers"))
+	}
+	names := make([]rune, 0, len(s.Registers))
+	for name := range s.Registers {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	lines := make([]string, 0, len(names))
+	for _, name := range names {
+		paths := s.Registers[name].Paths
+		bases := make([]string, len(paths))
+		for i, p := range paths {
+			bases[i] = sanitize(filepath.Base(p))
+		}
+		arrow := r.listCursor(s.OpBuf == state.RegisterUse && name == s.RegisterName())
+		lines = append(lines, arrow+fmt.Sprintf("%c  ", name)+r.Style.StatusBar.Render(fmt.Sprintf("%d", len(paths)))+"  "+strings.Join(bases, ", "))
+	}
+	return r.Style.BookmarkPicker.MaxWidth(width).Render(strings.Join(lines[:min(height, len(lines))], "\n"))
+}
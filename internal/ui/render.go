@@ -1,78 +1,194 @@
 package ui
 
 import (
+	"errors"
 	"fmt"
-	"math"
 	"os"
+	"path/filepath"
+	"strconv"
 	"strings"
 	"time"
-	"unicode/utf8"
 
+	"github.com/charmbracelet/glamour"
 	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+	"github.com/mattn/go-runewidth"
 
+	"github.com/LeperGnome/bt/internal/config"
+	"github.com/LeperGnome/bt/internal/i18n"
+	"github.com/LeperGnome/bt/internal/preview"
 	"github.com/LeperGnome/bt/internal/state"
 	t "github.com/LeperGnome/bt/internal/tree"
 	"github.com/LeperGnome/bt/pkg/stack"
 )
 
 const (
-	previewBytesLimit int64 = 10_000
+	minHeight     = 10
+	minWidth      = 10
+	minBodyHeight = 5 // panes get at least that, heading shrinks instead
 
-	minHeight = 10
-	minWidth  = 10
-
-	arrow               = " <-"
-	indentParent        = "│  "
-	indentCurrent       = "├─ "
-	indentCurrentLast   = "└─ "
 	indentEmpty         = "   "
 	emptydirContentName = "..."
-
-	tooSmall                 = "too small =("
-	binaryContentPlaceholder = "<binary content>"
-	helpPreview              = "Press ? to toggle help"
 )
 
 type Renderer struct {
 	Style       Stylesheet
 	EdgePadding int
-	offsetMem   int
-	previewBuff [previewBytesLimit]byte
+	// Avoid changing text, that is not caused by user, e.g. ticking counters and progress.
+	ReducedMotion bool
+	Names         *NameColors     // colors of names by type and extension, nil - theme colors only
+	offsetMem     map[*t.Tree]int // scroll offset for each rendered tree
+	rowsMem       map[*t.Tree]*treeLayout
+	mdRenderer    *glamour.TermRenderer
+	mdWidth       int
+	highlightMem  highlightCache
 }
 
 func (r *Renderer) Render(s *state.State, winHeight, winWidth int) string {
 	if winWidth < minWidth || winHeight < minHeight {
-		return tooSmall
+		return i18n.T("ui.too-small")
 	}
 
-	renderedHeading, headLen := r.renderHeading(s, winWidth)
-
-	// section is half a screen, devided vertically
-	// left for tree, right for file preview
-	sectionSize := 1.0
-	if s.PreviewToggle {
-		sectionSize = 0.5
+	h := heading{style: &r.Style, s: s, width: winWidth, reducedMotion: r.ReducedMotion}
+	renderedHeading, headLen := h.Render(winHeight - minBodyHeight - 1)
+	l := computeLayout(s, winHeight-headLen-1, winWidth) // status line is at the bottom
+	status := r.renderStatus(s, winWidth)
+	if s.HelpToggle {
+		help := r.renderPane(
+			i18n.T("ui.pane-help"),
+			r.renderHelp(s, l.height-2, winWidth-2),
+			winWidth, l.height, true,
+		)
+		return renderedHeading + "\n" + help + "\n" + status
 	}
-	sectionWidth := int(math.Floor(sectionSize * float64(winWidth)))
 
-	renderedTree := r.renderTree(s.Tree, winHeight-headLen, sectionWidth)
+	leftTree := s.Tree
+	if s.DualPane {
+		leftTree = s.Panes[0]
+	}
+	var files string
+	if l.right == rightNone && s.ColumnView() {
+		files = r.renderColumns(s, l.height-2, l.leftWidth-2, s.Focus == state.PaneFirstTree)
+	} else {
+		files = r.renderTree(s, leftTree, l.height-2, l.leftWidth-2, s.Focus == state.PaneFirstTree)
+	}
+	renderedTree := r.renderPane(
+		i18n.T("ui.pane-files"),
+		files,
+		l.leftWidth, l.height, s.Focus == state.PaneFirstTree && l.right != rightNone,
+	)
 
 	var rightPane string
-
-	if s.HelpToggle {
-		renderedHelp, helpLen := r.renderHelp(sectionWidth)
-		if s.PreviewToggle {
-			renderedContent := r.renderSelectedFileContent(s.Tree, winHeight-headLen-helpLen, sectionWidth)
-			rightPane = lipgloss.JoinVertical(lipgloss.Left, renderedHelp, renderedContent)
-		} else {
-
-			rightPane = lipgloss.JoinVertical(lipgloss.Left, renderedHelp)
+	rest := l.height
+	switch l.right {
+	case rightBookmarks:
+		rightPane = r.renderPane(i18n.T("ui.bookmarks"), r.renderBookmarks(s), l.rightWidth, l.height, true)
+	case rightBulkRename:
+		rightPane = stackPanes(rightPane, r.renderPane(
+			i18n.T("ui.pane-bulk-rename"),
+			r.renderBulkRename(s.BulkRename, rest-2, l.rightWidth-2),
+			l.rightWidth, rest, false,
+		))
+	case rightRecent:
+		rightPane = r.renderPane(i18n.T("ui.pane-recent"), r.renderRecent(s, l.height-2, l.rightWidth-2), l.rightWidth, l.height, true)
+	case rightMessages:
+		rightPane = r.renderPane(i18n.T("ui.pane-messages"), r.renderMessages(s, l.height-2, l.rightWidth-2), l.rightWidth, l.height, true)
+	case rightDrives:
+		rightPane = r.renderPane(i18n.T("ui.pane-drives"), r.renderDrives(s, l.height-2, l.rightWidth-2), l.rightWidth, l.height, true)
+	case rightJournal:
+		rightPane = r.renderPane(i18n.T("ui.pane-journal"), r.renderJournal(s, l.height-2, l.rightWidth-2), l.rightWidth, l.height, true)
+	case rightRegisters:
+		rightPane = r.renderPane(i18n.T("ui.pane-registers"), r.renderRegisters(s, l.height-2, l.rightWidth-2), l.rightWidth, l.height, true)
+	case rightStaging:
+		rightPane = r.renderPane(fmt.Sprintf(i18n.T("ui.pane-staging"), len(s.Selection)), r.renderStaging(s, l.height-2, l.rightWidth-2), l.rightWidth, l.height, true)
+	case rightBasket:
+		rightPane = stackPanes(rightPane, r.renderPane(
+			fmt.Sprintf(i18n.T("ui.pane-basket"), len(s.Basket.Paths)),
+			r.renderBasket(&s.Basket, rest-2, l.rightWidth-2),
+			l.rightWidth, rest, true,
+		))
+	case rightDeletion:
+		title := fmt.Sprintf(i18n.T("ui.pane-delete"), len(s.Deletion.Paths))
+		if len(s.Deletion.Paths) == 1 {
+			title = fmt.Sprintf(i18n.T("ui.pane-delete-dir"), filepath.Base(s.Deletion.Paths[0]))
 		}
-	} else {
-		if s.PreviewToggle {
-			renderedContent := r.renderSelectedFileContent(s.Tree, winHeight-headLen, sectionWidth)
-			rightPane = renderedContent
+		rightPane = stackPanes(rightPane, r.renderPane(
+			title, r.renderDeletion(s.Deletion, rest-2, l.rightWidth-2),
+			l.rightWidth, rest, true,
+		))
+	case rightCleanup:
+		rightPane = stackPanes(rightPane, r.renderPane(
+			fmt.Sprintf(i18n.T("ui.pane-cleanup"), filepath.Base(s.Cleanup.Root)),
+			r.renderCleanup(s.Cleanup, rest-2, l.rightWidth-2),
+			l.rightWidth, rest, true,
+		))
+	case rightChurn:
+		rightPane = stackPanes(rightPane, r.renderPane(
+			fmt.Sprintf(i18n.T("ui.pane-churn"), filepath.Base(s.Churn.Dir)),
+			r.renderChurn(s.Churn, rest-2, l.rightWidth-2),
+			l.rightWidth, rest, false,
+		))
+	case rightGrep:
+		rightPane = stackPanes(rightPane, r.renderPane(
+			fmt.Sprintf(i18n.T("ui.pane-grep"), s.Grep.Pattern),
+			r.renderGrep(s.Grep, rest-2, l.rightWidth-2),
+			l.rightWidth, rest, true,
+		))
+	case rightShell:
+		rightPane = stackPanes(rightPane, r.renderPane(
+			fmt.Sprintf(i18n.T("ui.pane-shell"), sanitize(s.Shell.Command)),
+			r.renderShell(s.Shell, rest-2, l.rightWidth-2),
+			l.rightWidth, rest, true,
+		))
+	case rightCompare:
+		c := s.Compare
+		content := r.Style.ContentPreview.Render(i18n.T("ui.files-equal"))
+		if c.Diff != "" {
+			content = r.renderDiff(c.Diff, s.PreviewOffset, rest-2, l.rightWidth-2)
+		}
+		rightPane = stackPanes(rightPane, r.renderPane(
+			fmt.Sprintf(i18n.T("ui.pane-compare"), filepath.Base(c.A), r.Style.Glyphs.Compare, filepath.Base(c.B)),
+			content, l.rightWidth, rest, true,
+		))
+	case rightTail:
+		rightPane = stackPanes(rightPane, r.renderPane(
+			fmt.Sprintf(i18n.T("ui.pane-tail"), filepath.Base(s.Tail.Path)),
+			r.renderTail(s.Tail, rest-2, l.rightWidth-2),
+			l.rightWidth, rest, true,
+		))
+	case rightSnapshots:
+		rightPane = stackPanes(rightPane, r.renderPane(
+			i18n.T("ui.pane-snapshots"),
+			r.renderSnapshots(s.TimeTravel, rest-2, l.rightWidth-2),
+			l.rightWidth, rest, true,
+		))
+	case rightSecondTree:
+		title := i18n.T("ui.pane-files")
+		if tt := s.TimeTravel; tt != nil && tt.Active != nil {
+			title = fmt.Sprintf(i18n.T("ui.pane-snapshot"), tt.Active.Name, tt.Active.Time.Format(time.DateTime))
+		}
+		rightPane = stackPanes(rightPane, r.renderPane(
+			title,
+			r.renderTree(s, s.Panes[1], rest-2, l.rightWidth-2, s.Focus == state.PaneSecondTree),
+			l.rightWidth, rest, l.rightFocus,
+		))
+	case rightPreview:
+		if s.PinnedPath != "" {
+			rightPane = stackPanes(rightPane, r.renderPinned(s, rest, l.rightWidth, l.rightFocus))
+			break
+		}
+		title := i18n.T("ui.pane-preview")
+		if selected := s.Tree.GetSelectedChild(); selected != nil {
+			title = fmt.Sprintf(i18n.T("ui.pane-preview-of"), selected.Info.Name())
+			if _, ok := s.PreviewDiff(); ok {
+				title = fmt.Sprintf(i18n.T("ui.pane-diff-of"), selected.Info.Name())
+			}
 		}
+		rightPane = stackPanes(rightPane, r.renderPane(
+			title,
+			r.renderSelectedFileContent(s, rest-2, l.rightWidth-2),
+			l.rightWidth, rest, l.rightFocus,
+		))
 	}
 
 	renderedTreeWithContent := lipgloss.JoinHorizontal(
@@ -81,231 +197,469 @@ func (r *Renderer) Render(s *state.State, winHeight, winWidth int) string {
 		rightPane,
 	)
 
-	return renderedHeading + "\n" + renderedTreeWithContent
+	return renderedHeading + "\n" + renderedTreeWithContent + "\n" + status
 }
 
-func (r *Renderer) renderHeading(s *state.State, width int) (string, int) {
-	selected := s.Tree.GetSelectedChild()
-
-	// NOTE: special case for empty dir
-	path := s.Tree.CurrentDir.Path + "/..."
-	changeTime := "--"
-	size := "0 B"
-	perm := "--"
-
-	if selected != nil {
-		path = selected.Path
-		changeTime = selected.Info.ModTime().Format(time.RFC822)
-		size = formatSize(float64(selected.Info.Size()), 1024.0)
-		perm = selected.Info.Mode().String()
-	}
-
-	markedPath := ""
-	if s.Tree.Marked != nil {
-		markedPath = s.Tree.Marked.Path
-	}
-
-	operationBar := fmt.Sprintf(": %s", s.OpBuf.Repr())
-	if markedPath != "" {
-		operationBar += fmt.Sprintf(" [%s]", markedPath)
-	}
-
-	// if s.OpBuf.IsInput() {
-	// 	operationBar += fmt.Sprintf(" │ %s │", r.Style.OperationBarInput.Render(string(s.InputBuf)))
-	// }
-
-	rawPath := "> " + path
-
-	finfo := fmt.Sprintf(
-		"%s %s %v %s %s",
-		r.Style.FinfoPermissions.Render(perm),
-		r.Style.FinfoSep.Render("│"),
-		r.Style.FinfoLastUpdated.Render(changeTime),
-		r.Style.FinfoSep.Render("│"),
-		r.Style.FinfoSize.Render(size),
-	)
-
-	header := []string{
-		r.Style.SelectedPath.Render(rawPath) +
-			strings.Repeat(
-				" ",
-				max(width-utf8.RuneCountInString(rawPath)-utf8.RuneCountInString(helpPreview), 0),
-			) +
-			r.Style.HelpMsg.Render(helpPreview),
-		finfo,
-		r.Style.OperationBar.Render(operationBar),
-	}
-	if s.OpBuf.IsInput() {
-		header = append(header,
-			r.Style.OperationBar.Render(fmt.Sprintf("-> %s", r.Style.OperationBarInput.Render(string(s.InputBuf)))),
-		)
+func (r *Renderer) renderBookmarks(s *state.State) string {
+	lines := []string{}
+	keys := s.Bookmarks.Keys()
+	if len(keys) == 0 {
+		lines = append(lines, i18n.T("ui.no-bookmarks"))
 	}
-	if s.ErrBuf != "" {
-		header = append(header,
-			r.Style.ErrBar.Render(s.ErrBuf),
-		)
+	for _, k := range keys {
+		if a, ok := s.Bookmarks.Anchor(k); ok {
+			line := fmt.Sprintf("%s  %s:%d", r.Style.BookmarkKey.Render(k), a.Path, a.Line)
+			if a.Note != "" {
+				line += " " + r.Style.Glyphs.Dash + " " + a.Note
+			}
+			lines = append(lines, line)
+			continue
+		}
+		dir, _ := s.Bookmarks.Dir(k)
+		lines = append(lines, fmt.Sprintf("%s  %s", r.Style.BookmarkKey.Render(k), dir))
 	}
-	return strings.Join(header, "\n"), len(header)
+	return r.Style.BookmarkPicker.Render(strings.Join(lines, "\n"))
 }
 
-func (r *Renderer) renderHelp(width int) (string, int) {
-	help := []string{
-		"j / arr down   Select next child",
-		"k / arr up     Select previous child",
-		"h / arr left   Move up a dir",
-		"l / arr right  Enter selected directory",
-		"if / id	    Create file (if) / directory (id) in current directory",
-		"d              Move selected child (then 'p' to paste)",
-		"y              Copy selected child (then 'p' to paste)",
-		"D              Delete selected child",
-		"r              Rename selected child",
-		"e              Edit selected file in $EDITOR",
-		"gg             Go to top most child in current directory",
-		"G              Go to last child in current directory",
-		"enter          Collapse / expand selected directory",
-		"esc            Clear error message / stop current operation",
-		"\"             Toggle file content",
-		"q / ctrl+c     Exit",
-	}
-	return r.Style.
-		HelpContent.
-		MaxWidth(width).
-		MarginRight(width).
-		Render(strings.Join(help, "\n")), len(help) + 1 // +1 for border
-}
-
-func (r *Renderer) renderTree(tree *t.Tree, height, width int) string {
-	renderedTreeLines, selectedRow := r.renderTreeFull(tree, width)
-	croppedTreeLines := r.cropTree(renderedTreeLines, selectedRow, height)
+func (r *Renderer) renderTree(s *state.State, tree *t.Tree, height, width int, focused bool) string {
+	layout := r.treeLayout(tree)
+	rows := layout.rows
+	offset, limit := r.cropTree(tree, len(rows), layout.selectedRow(tree), height)
+	lines := r.renderTreeRows(s, tree, rows, offset, limit, width, focused)
 
 	treeStyle := lipgloss.
 		NewStyle().
 		MaxWidth(width).
 		MarginRight(width)
 
-	return treeStyle.Render(strings.Join(croppedTreeLines, "\n"))
+	return treeStyle.Render(strings.Join(lines, "\n"))
 }
 
-func (r *Renderer) renderSelectedFileContent(tree *t.Tree, height, width int) string {
-	n, err := tree.ReadSelectedChildContent(r.previewBuff[:], previewBytesLimit)
+func (r *Renderer) renderSelectedFileContent(s *state.State, height, width int) string {
+	if diff, ok := s.PreviewDiff(); ok {
+		return r.renderDiff(diff, s.PreviewOffset, height, width)
+	}
+	p, err := s.Preview()
+	if errors.Is(err, state.ErrNoPreview) {
+		return r.renderSelectedDir(s, height, width)
+	}
 	if err != nil {
-		return ""
+		return r.Style.ErrBar.Render(err.Error())
+	}
+	offset := s.PreviewOffset
+	if s.PinnedPath != "" {
+		offset = 0 // scrolling applies to the pinned file
 	}
-	content := r.previewBuff[:n]
+	return r.renderFileContent(s, p, s.Tree.GetSelectedChild().Path, offset, height, width)
+}
 
-	contentStyle := r.Style.ContentPreview.MaxWidth(width - 1) // -1 for border...
+// Renders listing of the selected directory, if a directory is selected.
+func (r *Renderer) renderSelectedDir(s *state.State, height, width int) string {
+	l, err := s.DirPreview()
+	if errors.Is(err, state.ErrNoPreview) {
+		return ""
+	}
+	if err != nil {
+		return r.Style.ErrBar.Render(err.Error())
+	}
+	return r.renderDirPreview(l, s.PreviewOffset, height, width)
+}
 
-	var contentLines []string
-	if !utf8.Valid(content) {
-		contentLines = []string{binaryContentPlaceholder}
-	} else {
-		contentLines = strings.Split(string(content), "\n")
-		contentLines = contentLines[:max(min(height, len(contentLines)), 0)]
+func (r *Renderer) renderPinnedFileContent(s *state.State, height, width int) string {
+	p, err := s.PinnedPreview()
+	if err != nil {
+		return r.Style.ErrBar.Render(err.Error())
 	}
-	return contentStyle.Render(strings.Join(contentLines, "\n"))
+	return r.renderFileContent(s, p, s.PinnedPath, s.PreviewOffset, height, width)
 }
 
-// Crops tree lines, such that current line is visible and view is consistent.
-func (r *Renderer) cropTree(lines []string, currentLine int, height int) []string {
-	linesLen := len(lines)
+// Renders preview of the file at path, starting from offset line.
+func (r *Renderer) renderFileContent(s *state.State, p preview.Preview, path string, offset, height, width int) string {
+	contentStyle := r.Style.ContentPreview
+	if p.Kind == "" {
+		return contentStyle.MaxWidth(width).Render(i18n.T("ui.binary-content"))
+	}
+	if p.Pending() {
+		return contentStyle.MaxWidth(width).Render(i18n.T("ui.preview-pending"))
+	}
+
+	text := p.Text
+	styled := false
+	// numbers are shown for lines of the file, not for rendered markdown or dumps
+	numbers := s.LineNumbers && (p.Kind == config.PreviewText || p.Kind == config.PreviewHighlight)
+	switch {
+	case p.Kind == config.PreviewText && !s.MarkdownRaw && isMarkdown(path):
+		if md, err := r.renderMarkdown(text, width); err == nil {
+			text = md
+			styled = true
+			numbers = false
+		}
+	case p.Kind == config.PreviewHighlight:
+		if hl, ok := r.highlight(text, p.Lexer); ok {
+			text = hl
+			styled = true
+			contentStyle = lipgloss.NewStyle() // colors come from the highlighter
+		}
+	case p.Kind == config.PreviewCommand && strings.Contains(text, "\x1b["):
+		styled = true // command colors its output itself, e.g. image to ANSI art
+		contentStyle = lipgloss.NewStyle()
+	}
+	contentLines := strings.Split(text, "\n")
+	// text is a window of a huge file, starting with its first line
+	first := p.FirstLine()
+	offset = max(min(offset-first, len(contentLines)), 0)
+	gutter := 0
+	if numbers {
+		gutter = len(strconv.Itoa(first+len(contentLines))) + 1
+	}
+	textWidth := max(width-gutter, 1)
+	rows := make([]string, 0, height)
+	for i, line := range contentLines[offset:] {
+		if len(rows) >= height {
+			break
+		}
+		if !styled {
+			line = expandTabs(line)
+		}
+		parts := []string{line}
+		switch {
+		case s.WrapLines && ansi.StringWidth(line) > textWidth:
+			parts = strings.Split(ansi.Hardwrap(line, textWidth, true), "\n")
+			if styled {
+				// colors of a token, split by the wrap, don't leak out of its row
+				for k := range parts {
+					parts[k] += "\x1b[m"
+				}
+			}
+		case styled:
+			parts[0] = ansi.Truncate(line, textWidth, r.Style.Glyphs.Ellipsis)
+		default:
+			parts[0] = truncateMarked(line, textWidth, r.Style.Glyphs.Ellipsis)
+		}
+		for j, part := range parts {
+			row := contentStyle.Render(part)
+			if numbers {
+				num := ""
+				if j == 0 {
+					num = strconv.Itoa(first + offset + i + 1)
+				}
+				row = r.Style.PreviewLineNumber.Render(fmt.Sprintf("%*s ", gutter-1, num)) + row
+			}
+			rows = append(rows, row)
+		}
+	}
+	return lipgloss.NewStyle().MaxWidth(width).Render(strings.Join(rows[:min(len(rows), height)], "\n"))
+}
 
+// Returns window of rows to show, such that current row is visible and view is consistent.
+func (r *Renderer) cropTree(tree *t.Tree, rowsLen int, currentLine int, height int) (int, int) {
 	// determining offset and limit based on selected row
-	offset := r.offsetMem
-	limit := linesLen
+	if r.offsetMem == nil {
+		r.offsetMem = map[*t.Tree]int{}
+	}
+	offset := r.offsetMem[tree]
 
 	// cursor is out for 'top' boundary
 	if currentLine+1 > height+offset-r.EdgePadding {
-		offset = max(min(currentLine+1-height+r.EdgePadding, linesLen-height), 0)
+		offset = max(min(currentLine+1-height+r.EdgePadding, rowsLen-height), 0)
 	}
 	// cursor is out for 'bottom' boundary
 	if currentLine < r.EdgePadding+offset {
 		offset = max(currentLine-r.EdgePadding, 0)
 	}
-	r.offsetMem = offset
-	limit = min(height+offset, linesLen)
-	return lines[offset:limit]
+	r.offsetMem[tree] = offset
+	return offset, min(height+offset, rowsLen)
 }
 
-// Returns lines as slice and index of selected line.
-func (r *Renderer) renderTreeFull(tree *t.Tree, width int) ([]string, int) {
-	linen := -1
-	currentLine := 0
+// Rows of the expanded tree, laid out once per tree generation, so moving the cursor
+// through a huge tree doesn't walk all of it.
+type treeLayout struct {
+	generation int
+	current    *t.Node
+	rows       []treeRow
+	index      map[*t.Node]int // row of each node
+	empty      int             // row of the empty current directory placeholder, 0 - none
+}
 
-	type stackEl struct {
-		*t.Node
-		string
-		bool
+// Returns rows of the tree, laid out again only, when the tree has changed.
+func (r *Renderer) treeLayout(tree *t.Tree) *treeLayout {
+	if r.rowsMem == nil {
+		r.rowsMem = map[*t.Tree]*treeLayout{}
 	}
-	lines := []string{}
-	s := stack.NewStack(stackEl{tree.Root, "", false})
+	l := r.rowsMem[tree]
+	if l != nil && l.generation == tree.Generation() && l.current == tree.CurrentDir {
+		return l
+	}
+	rows, empty := treeRows(tree, r.Style.Glyphs)
+	l = &treeLayout{generation: tree.Generation(), current: tree.CurrentDir, rows: rows, index: make(map[*t.Node]int, len(rows)), empty: empty}
+	for i, row := range rows {
+		if row.node != nil {
+			l.index[row.node] = i
+		}
+	}
+	r.rowsMem[tree] = l
+	return l
+}
+
+// Returns index of the selected row.
+func (l *treeLayout) selectedRow(tree *t.Tree) int {
+	if selected := tree.GetSelectedChild(); selected != nil {
+		return l.index[selected]
+	}
+	return l.empty
+}
+
+// Row of the expanded tree: node, group header or placeholder of empty current directory.
+// Rows are laid out for the whole tree, but only visible ones are styled.
+type treeRow struct {
+	node   *t.Node // nil for header and placeholder
+	header string
+	parent int  // index of the parent directory row, -1 for root
+	last   bool // last among its siblings
+}
+
+// Returns rows of the expanded tree in display order and index of the empty current directory
+// placeholder, 0 if there's none.
+func treeRows(tree *t.Tree, g Glyphs) ([]treeRow, int) {
+	rows := []treeRow{}
+	empty := 0
+	s := stack.NewStack(treeRow{node: tree.Root, parent: -1})
 
 	for s.Len() > 0 {
-		el := s.Pop()
-		linen += 1
-
-		node := el.Node
-		isLast := el.bool
-		parentIndent := el.string
-
-		var indent string
-		if node == tree.Root {
-			indent = ""
-		} else if isLast {
-			indent = parentIndent + indentCurrentLast
-			parentIndent = parentIndent + indentEmpty
+		row := s.Pop()
+		rows = append(rows, row)
+		node := row.node
+		if node == nil {
+			continue
+		}
+		linen := len(rows) - 1
+		if node.Children == nil {
+			continue
+		}
+		children := make([]*t.Node, 0, len(node.Children))
+		for _, ch := range node.Children {
+			if tree.Visible(ch) {
+				children = append(children, ch)
+			}
+		}
+		// current directory is empty (or everything is filtered out)
+		if len(children) == 0 && tree.CurrentDir == node {
+			rows = append(rows, treeRow{parent: linen, last: true})
+			empty = linen + 1
+		}
+		items := make([]treeRow, 0, len(children))
+		if tree.Grouped() {
+			for _, h := range groupHeaders(tree, node, g) {
+				items = append(items, treeRow{header: h.label, parent: linen})
+				for _, ch := range children {
+					if t.GroupOf(ch) == h.group {
+						items = append(items, treeRow{node: ch, parent: linen})
+					}
+				}
+			}
+		} else {
+			for _, ch := range children {
+				items = append(items, treeRow{node: ch, parent: linen})
+			}
+		}
+		for i := len(items) - 1; i >= 0; i-- {
+			items[i].last = i == len(items)-1
+			s.Push(items[i])
+		}
+	}
+	return rows, empty
+}
+
+// Returns indentation of the row, built from its ancestors.
+func rowIndent(rows []treeRow, i int, g Glyphs) string {
+	row := rows[i]
+	if row.parent < 0 {
+		return ""
+	}
+	indent := g.IndentCurrent
+	if row.last {
+		indent = g.IndentCurrentLast
+	}
+	for p := row.parent; rows[p].parent >= 0; p = rows[p].parent {
+		if rows[p].last {
+			indent = indentEmpty + indent
 		} else {
-			indent = parentIndent + indentCurrent
-			parentIndent = parentIndent + indentParent
+			indent = g.IndentParent + indent
 		}
+	}
+	return indent
+}
+
+// Renders rows[offset:limit] of the tree.
+func (r *Renderer) renderTreeRows(st *state.State, tree *t.Tree, rows []treeRow, offset, limit, width int, focused bool) []string {
+	arrowStyle := r.Style.TreeSelectionArrow
+	if !focused {
+		arrowStyle = r.Style.TreeSelectionArrowUnfocused
+	}
+	nameWidth := width
+	details := st.DetailToggle && width-detailsWidth >= minDetailsNameWidth
+	if details {
+		nameWidth = width - detailsWidth
+	}
+
+	reserved := len("...") + runewidth.StringWidth(r.Style.Glyphs.Cursor) // ellipsis and cursor after cut names
+	lines := make([]string, 0, limit-offset)
+	dups := tree.Duplicates()
+	selected := tree.GetSelectedChild()
 
+	for i := offset; i < limit; i++ {
+		row := rows[i]
+		indent := rowIndent(rows, i, r.Style.Glyphs)
+		node := row.node
+
+		if row.header != "" {
+			lines = append(lines, cutLeft(r.Style.TreeIndent.Render(indent)+r.Style.TreeGroupHeader.Render(row.header), st.TreeScroll))
+			continue
+		}
 		if node == nil {
+			emptyIndent := r.Style.TreeIndent.Render(indent)
+			lines = append(lines, cutLeft(emptyIndent+emptydirContentName+arrowStyle.Render(r.Style.Glyphs.Cursor), st.TreeScroll))
 			continue
 		}
 
-		name := node.Info.Name()
-		nameRuneCountNoStyle := utf8.RuneCountInString(name)
-		indentRuneCount := utf8.RuneCountInString(indent)
+		name := sanitize(node.Info.Name())
+		nameCells := runewidth.StringWidth(name)
+		indentCells := runewidth.StringWidth(indent)
 
-		if nameRuneCountNoStyle+indentRuneCount > width-6 { // 6 = len([]rune{"... <-"})
-			name = string([]rune(name)[:max(0, width-indentRuneCount-6)]) + "..."
+		ellipsis := ""
+		// scrolled tree shows names in full, so they can be inspected
+		if st.TreeScroll == 0 && nameCells+indentCells > nameWidth-reserved {
+			name = truncateToWidth(name, max(0, nameWidth-indentCells-reserved))
+			ellipsis = "..."
 		}
 
 		indent = r.Style.TreeIndent.Render(indent)
 
-		if node.Info.IsDir() {
-			name = r.Style.TreeDirecotryName.Render(name)
-		} else if node.Info.Mode()&os.ModeSymlink == os.ModeSymlink {
-			name = r.Style.TreeLinkName.Render(name)
+		nameStyle := r.nameStyle(node)
+		if start, end := st.SearchMatch(name); start >= 0 {
+			runes := []rune(name)
+			name = nameStyle.Render(string(runes[:start])) +
+				r.Style.TreeSearchMatch.Render(string(runes[start:end])) +
+				nameStyle.Render(string(runes[end:])+ellipsis)
 		} else {
-			name = r.Style.TreeRegularFileName.Render(name)
+			name = nameStyle.Render(name + ellipsis)
 		}
 
-		if tree.Marked == node {
-			name = r.Style.TreeMarkedNode.Render(name)
+		name = r.markName(st, tree, node, name)
+		if total, ok := st.DirSizes[node.Path]; ok {
+			name += r.Style.TreeDirSize.Render(" " + formatSize(float64(total), 1024.0))
+		}
+		if node.Unreadable {
+			name += r.Style.TreeUnreadableName.Render(r.Style.Glyphs.Unreadable)
+		} else if node.Unwritable {
+			name += r.Style.TreeLockedIndicator.Render(r.Style.Glyphs.Locked)
+		}
+		if node.Loop {
+			name += r.Style.TreeLoopIndicator.Render(r.Style.Glyphs.Loop)
+		} else if first, ok := dups[node]; ok {
+			name += r.Style.TreeSameAs.Render(" " + fmt.Sprintf(i18n.T("ui.same-as"), sanitize(first.Path)))
 		}
 
 		repr := indent + name
 
-		if tree.GetSelectedChild() == node {
-			repr += r.Style.TreeSelectionArrow.Render(arrow)
-			currentLine = linen
+		if selected == node {
+			repr += arrowStyle.Render(r.Style.Glyphs.Cursor)
+		}
+		repr = cutLeft(repr, st.TreeScroll)
+		if details {
+			repr = lipgloss.NewStyle().Width(nameWidth).MaxWidth(nameWidth).Render(repr) + r.renderDetails(node)
 		}
 		lines = append(lines, repr)
+	}
+	return lines
+}
 
-		if node.Children != nil {
-			// current directory is empty
-			if len(node.Children) == 0 && tree.CurrentDir == node {
-				emptyIndent := r.Style.TreeIndent.Render(parentIndent + indentCurrentLast)
-				lines = append(lines, emptyIndent+emptydirContentName+r.Style.TreeSelectionArrow.Render(arrow))
-				currentLine = linen + 1
-			}
-			for i := len(node.Children) - 1; i >= 0; i-- {
-				ch := node.Children[i]
-				s.Push(stackEl{ch, parentIndent, i == len(node.Children)-1})
-			}
+// Returns style of the node name by its kind.
+func (r *Renderer) nameStyle(node *t.Node) lipgloss.Style {
+	if node.Unreadable {
+		return r.Style.TreeUnreadableName
+	}
+	if node.Artifact {
+		return r.Style.TreeArtifactName
+	}
+	if style, ok := r.Names.Style(node.Info.Name(), node.Info.Mode()); ok {
+		return style
+	}
+	if node.Info.IsDir() {
+		return r.Style.TreeDirecotryName
+	}
+	if node.Info.Mode()&os.ModeSymlink == os.ModeSymlink {
+		return r.Style.TreeLinkName
+	}
+	return r.Style.TreeRegularFileName
+}
+
+// Styles rendered name of the node, if it's tossed, selected or marked.
+func (r *Renderer) markName(st *state.State, tree *t.Tree, node *t.Node, name string) string {
+	g := r.Style.Glyphs
+	if st.Basket.Contains(node.Path) {
+		name = g.TossedMark + r.Style.TreeTossedNode.Render(name)
+	}
+	if st.IsSelected(node.Path) {
+		name = g.SelectedMark + r.Style.TreeSelectedNode.Render(name)
+	}
+	if tree.Marked == node {
+		name = g.MarkedMark + r.Style.TreeMarkedNode.Render(name)
+	}
+	return name
+}
+
+var groupNames = map[t.Group]string{
+	t.GroupDirectories: "ui.group-dirs",
+	t.GroupCode:        "ui.group-code",
+	t.GroupImages:      "ui.group-images",
+	t.GroupDocuments:   "ui.group-documents",
+	t.GroupOther:       "ui.group-other",
+}
+
+type groupHeader struct {
+	group t.Group
+	label string
+}
+
+// Returns headers for groups, present among entries of dir, in display order.
+// Entries of a folded group are counted, though not shown.
+func groupHeaders(tree *t.Tree, dir *t.Node, gl Glyphs) []groupHeader {
+	counts := map[t.Group]int{}
+	for _, ch := range dir.Children {
+		counts[t.GroupOf(ch)]++
+	}
+	headers := []groupHeader{}
+	for g := t.GroupDirectories; g <= t.GroupOther; g++ {
+		if counts[g] == 0 {
+			continue
+		}
+		marker := gl.GroupOpen
+		if tree.Folded(dir, g) {
+			marker = gl.GroupFolded
 		}
+		headers = append(headers, groupHeader{g, fmt.Sprintf("%s%s (%d)", marker, i18n.T(groupNames[g]), counts[g])})
 	}
-	return lines, currentLine
+	return headers
+}
+
+const (
+	detailsWidth        = 52 // see renderDetails
+	minDetailsNameWidth = 20
+)
+
+// Renders ls -l like columns: permissions, owner, group, size and modification time.
+func (r *Renderer) renderDetails(node *t.Node) string {
+	owner, group := t.Owner(node.Info)
+	perm := node.Info.Mode().String()
+	return r.Style.TreeDetails.Render(fmt.Sprintf(
+		" %s %s %s %9s %s",
+		perm[len(perm)-10:], // only the last type letter
+		fitWidth(owner, 8),
+		fitWidth(group, 8),
+		formatSize(float64(node.Info.Size()), 1024.0),
+		node.Info.ModTime().Format("Jan 02 15:04"),
+	))
 }
 
 var sizes = [...]string{"B", "KiB", "MiB", "GiB", "TiB", "PiB", "EiB"}
@@ -323,3 +677,25 @@ func formatSize(s float64, base float64) string {
 	}
 	return fmt.Sprintf(f, s, sizes[i])
 }
+
+// Renders pinned file, with transient preview of the selected file below, if it's on.
+func (r *Renderer) renderPinned(s *state.State, height, width int, focused bool) string {
+	pinnedHeight := height
+	if s.ShowTransient() {
+		pinnedHeight = height / 2
+	}
+	pinned := r.renderPane(
+		fmt.Sprintf(i18n.T("ui.pane-pinned"), filepath.Base(s.PinnedPath)),
+		r.renderPinnedFileContent(s, pinnedHeight-2, width-2),
+		width, pinnedHeight, focused,
+	)
+	if !s.ShowTransient() {
+		return pinned
+	}
+	transient := r.renderPane(
+		fmt.Sprintf(i18n.T("ui.pane-preview-of"), s.Tree.GetSelectedChild().Info.Name()),
+		r.renderSelectedFileContent(s, height-pinnedHeight-2, width-2),
+		width, height-pinnedHeight, false,
+	)
+	return stackPanes(pinned, transient)
+}
@@ -0,0 +1,31 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/x/ansi"
+
+	"github.com/LeperGnome/bt/internal/i18n"
+	"github.com/LeperGnome/bt/internal/state"
+)
+
+// Renders exit status and output of the shell command from its offset.
+func (r *Renderer) renderShell(run *state.ShellRun, height, width int) string {
+	status := r.Style.GrepLocation.Render(fmt.Sprintf(i18n.T("ui.shell-exit"), run.Exit))
+	switch {
+	case run.Running:
+		status = i18n.T("ui.shell-running")
+	case run.Exit != 0:
+		status = r.Style.ErrBar.Render(fmt.Sprintf(i18n.T("ui.shell-exit"), run.Exit))
+	}
+	// status line stays on top
+	rows := max(height-1, 0)
+	start := max(min(run.Offset, len(run.Output)-rows), 0)
+	end := min(start+rows, len(run.Output))
+	lines := []string{status}
+	for _, l := range run.Output[start:end] {
+		lines = append(lines, sanitize(expandTabs(ansi.Strip(strings.TrimSuffix(l, "\r")))))
+	}
+	return r.Style.GrepContent.MaxWidth(width).Render(strings.Join(lines, "\n"))
+}
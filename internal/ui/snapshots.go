@@ -0,0 +1,31 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/LeperGnome/bt/internal/i18n"
+	"github.com/LeperGnome/bt/internal/state"
+)
+
+// Renders snapshot picker, newest first, keeping cursor in view.
+func (r *Renderer) renderSnapshots(tt *state.TimeTravelSession, height, width int) string {
+	if tt.Snapshots == nil {
+		return r.Style.SnapshotContent.Render(i18n.T("ui.snapshots-loading"))
+	}
+	lines := []string{}
+	for i, snap := range tt.Snapshots {
+		arrow := r.listCursor(i == tt.Cursor)
+		lines = append(lines, fmt.Sprintf(
+			"%s%s  %s  %s",
+			arrow,
+			snap.Time.Format(time.DateTime),
+			r.Style.SnapshotProvider.Render(fmt.Sprintf("%-11s", snap.Provider)),
+			snap.Name,
+		))
+	}
+	start := max(min(tt.Cursor-height/2, len(lines)-height), 0)
+	end := min(start+height, len(lines))
+	return r.Style.SnapshotContent.MaxWidth(width).Render(strings.Join(lines[start:end], "\n"))
+}
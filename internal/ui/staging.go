@@ -0,0 +1,26 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/LeperGnome/bt/internal/i18n"
+	"github.com/LeperGnome/bt/internal/state"
+)
+
+// Renders staged paths with their sizes, keeping cursor in view.
+func (r *Renderer) renderStaging(s *state.State, height, width int) string {
+	lines := []string{i18n.T("ui.staging-hint")}
+	for i, p := range s.SelectedPaths() {
+		arrow := r.listCursor(i == s.StagingCursor)
+		size := ""
+		if n, ok := s.SelectedSize(p); ok {
+			size = formatSize(float64(n), 1024.0)
+		}
+		lines = append(lines, fmt.Sprintf("%s%9s  %s", arrow, size, sanitize(p)))
+	}
+	cursorLine := s.StagingCursor + 1
+	start := max(min(cursorLine-height/2, len(lines)-height), 0)
+	end := min(start+height, len(lines))
+	return r.Style.CleanupContent.MaxWidth(width).Render(strings.Join(lines[start:end], "\n"))
+}
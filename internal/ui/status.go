@@ -0,0 +1,57 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/LeperGnome/bt/internal/disk"
+	"github.com/LeperGnome/bt/internal/i18n"
+	"github.com/LeperGnome/bt/internal/state"
+)
+
+// Renders bottom line with statistics of the current directory: entries, selection and free space.
+func (r *Renderer) renderStatus(s *state.State, width int) string {
+	files, dirs, hidden := 0, 0, 0
+	for _, ch := range s.Tree.CurrentDir.Children {
+		switch {
+		case !s.Tree.Visible(ch):
+			hidden++
+		case ch.IsDir():
+			dirs++
+		default:
+			files++
+		}
+	}
+	items := fmt.Sprintf(i18n.T("ui.status-items"), files, dirs)
+	if hidden > 0 {
+		items += fmt.Sprintf(i18n.T("ui.status-hidden"), hidden)
+	}
+	parts := []string{items}
+	if s.ReadOnly {
+		parts = append([]string{i18n.T("ui.status-read-only")}, parts...)
+	}
+	if len(s.Selection) > 0 {
+		total, partial := s.SelectionSize()
+		size := formatSize(float64(total), 1024.0)
+		if partial {
+			size += "+"
+		}
+		parts = append(parts, fmt.Sprintf(i18n.T("ui.status-selected"), len(s.Selection), size))
+	}
+	if o := s.NameOrder; o.Natural || o.CaseSensitive {
+		order := []string{}
+		if o.Natural {
+			order = append(order, i18n.T("ui.status-natural"))
+		}
+		if o.CaseSensitive {
+			order = append(order, i18n.T("ui.status-case"))
+		}
+		parts = append(parts, strings.Join(order, ", "))
+	}
+	if s.Tree.Local() {
+		if free, ok := disk.Free(s.Tree.CurrentDir.Path); ok {
+			parts = append(parts, fmt.Sprintf(i18n.T("ui.status-free"), formatSize(float64(free), 1024.0)))
+		}
+	}
+	return r.Style.StatusBar.Render(fitWidth(strings.Join(parts, " "+r.Style.Glyphs.Separator+" "), width))
+}
@@ -12,56 +12,179 @@ type Stylesheet struct {
 
 	OperationBar      lipgloss.Style
 	OperationBarInput lipgloss.Style
+	InputCursor       lipgloss.Style
+	FilterIndicator   lipgloss.Style
 
-	ErrBar lipgloss.Style
+	ErrBar    lipgloss.Style
+	StatusBar lipgloss.Style
+
+	TabActive   lipgloss.Style
+	TabInactive lipgloss.Style
 
 	HelpMsg     lipgloss.Style
+	HelpKey     lipgloss.Style
 	HelpContent lipgloss.Style
 
-	TreeRegularFileName lipgloss.Style
-	TreeDirecotryName   lipgloss.Style
-	TreeLinkName        lipgloss.Style
-	TreeMarkedNode      lipgloss.Style
-	TreeSelectionArrow  lipgloss.Style
-	TreeIndent          lipgloss.Style
+	DiffAdded   lipgloss.Style
+	DiffRemoved lipgloss.Style
+	DiffHunk    lipgloss.Style
+	DiffHeader  lipgloss.Style
+
+	ChurnContent lipgloss.Style
+	ChurnBar     lipgloss.Style
+
+	BookmarkPicker lipgloss.Style
+	BookmarkKey    lipgloss.Style
+
+	BulkRenameContent lipgloss.Style
+	BulkRenameNew     lipgloss.Style
+
+	GrepContent  lipgloss.Style
+	GrepLocation lipgloss.Style
+
+	SnapshotContent  lipgloss.Style
+	SnapshotProvider lipgloss.Style
+
+	CleanupContent  lipgloss.Style
+	CleanupGroup    lipgloss.Style
+	CleanupSelected lipgloss.Style
+	CleanupCursor   lipgloss.Style
+
+	JobProgressDone lipgloss.Style
+	JobProgressLeft lipgloss.Style
+
+	TreeRegularFileName         lipgloss.Style
+	TreeDirecotryName           lipgloss.Style
+	TreeLinkName                lipgloss.Style
+	TreeArtifactName            lipgloss.Style
+	TreeUnreadableName          lipgloss.Style
+	TreeLockedIndicator         lipgloss.Style
+	TreeLoopIndicator           lipgloss.Style
+	TreeSameAs                  lipgloss.Style
+	TreeDirSize                 lipgloss.Style
+	TreeGroupHeader             lipgloss.Style
+	TreeDetails                 lipgloss.Style
+	TreeMarkedNode              lipgloss.Style
+	TreeSelectedNode            lipgloss.Style
+	TreeTossedNode              lipgloss.Style
+	TreeSearchMatch             lipgloss.Style
+	TreeSelectionArrow          lipgloss.Style
+	TreeSelectionArrowUnfocused lipgloss.Style
+	TreeIndent                  lipgloss.Style
+
+	ContentPreview    lipgloss.Style
+	PreviewLineNumber lipgloss.Style
+	MarkdownStyle     string // glamour standard style, e.g. "dark"
+	CodeStyle         string // chroma style, empty - no highlighting
+
+	PaneBorder             lipgloss.Border
+	PaneBorderColor        lipgloss.Style // only foreground is used
+	PaneBorderFocusedColor lipgloss.Style // only foreground is used
+	PaneTitle              lipgloss.Style
+	PaneTitleFocused       lipgloss.Style
+	PaneTitleFormat        string // fmt format for pane title, e.g. " %s "
+
+	Glyphs Glyphs
+}
+
+var DefaultStylesheet = NewStylesheet(DefaultTheme)
+
+// Builds all styles from the theme palette.
+func NewStylesheet(t Theme) Stylesheet {
+	fg := func(c lipgloss.TerminalColor) lipgloss.Style {
+		return lipgloss.NewStyle().Foreground(c)
+	}
+	return Stylesheet{
+		SelectedPath: fg(t.Header),
+
+		FinfoPermissions: fg(t.Selection),
+		FinfoLastUpdated: fg(t.Text),
+		FinfoSize:        fg(t.Text),
+		FinfoSep:         fg(t.Separator),
+
+		OperationBar:      fg(t.Text),
+		OperationBarInput: lipgloss.NewStyle().Background(t.Surface),
+		InputCursor:       lipgloss.NewStyle().Reverse(true),
+		FilterIndicator:   fg(t.Selection),
+
+		TabActive:   fg(t.Text).Background(t.Surface).Reverse(isMono(t)),
+		TabInactive: fg(t.Muted),
+
+		ErrBar:      fg(t.Error),
+		StatusBar:   fg(t.Muted),
+		HelpMsg:     fg(t.Selection),
+		HelpKey:     fg(t.HelpKey).Bold(true),
+		HelpContent: fg(t.HelpText),
+
+		DiffAdded:   fg(t.Added),
+		DiffRemoved: fg(t.Error),
+		DiffHunk:    fg(t.Info),
+		DiffHeader:  fg(t.Text).Bold(true),
+
+		ChurnContent: fg(t.Text),
+		ChurnBar:     fg(t.Error),
+
+		BookmarkPicker: fg(t.Text),
+		BookmarkKey:    fg(t.Selection),
+
+		BulkRenameContent: fg(t.Text),
+		BulkRenameNew:     fg(t.Symlink),
+
+		GrepContent:  fg(t.Text),
+		GrepLocation: fg(t.Directory),
+
+		SnapshotContent:  fg(t.Text),
+		SnapshotProvider: fg(t.Selection),
+
+		CleanupContent:  fg(t.Text),
+		CleanupGroup:    fg(t.Selection).Bold(true),
+		CleanupSelected: fg(t.Error),
+		CleanupCursor:   fg(t.Info),
+
+		JobProgressDone: fg(t.Added),
+		JobProgressLeft: fg(t.Muted),
+
+		TreeRegularFileName: fg(t.Text),
+		TreeDirecotryName:   fg(t.Directory).Bold(isMono(t)),
+		TreeLinkName:        fg(t.Symlink).Italic(isMono(t)),
+		TreeArtifactName:    fg(t.Muted).Faint(isMono(t)),
+		TreeUnreadableName:  fg(t.Muted).Faint(true),
+		TreeLockedIndicator: fg(t.Muted),
+		TreeLoopIndicator:   fg(t.Error),
+		TreeSameAs:          fg(t.Muted),
+		TreeDirSize:         fg(t.Muted),
+		TreeDetails:         fg(t.Muted),
+		TreeGroupHeader:     fg(t.Selection).Bold(true),
+		TreeMarkedNode: lipgloss.NewStyle().
+			BorderLeft(true).
+			BorderStyle(lipgloss.InnerHalfBlockBorder()).
+			Background(t.Marked),
+		TreeSelectedNode:            lipgloss.NewStyle().Bold(true).Underline(true),
+		TreeTossedNode:              fg(t.Error).Strikethrough(true),
+		TreeSearchMatch:             fg(t.Selection).Bold(true).Underline(true),
+		TreeSelectionArrow:          fg(t.Selection),
+		TreeSelectionArrowUnfocused: fg(t.Muted),
+		TreeIndent:                  fg(t.Border),
+
+		ContentPreview:    fg(t.Preview).Italic(true),
+		PreviewLineNumber: fg(t.Muted),
+
+		MarkdownStyle: t.MarkdownStyle,
+		CodeStyle:     t.CodeStyle,
+
+		PaneBorder:             lipgloss.RoundedBorder(),
+		PaneBorderColor:        fg(t.Border),
+		PaneBorderFocusedColor: fg(t.Selection),
+		PaneTitle:              fg(t.Muted),
+		PaneTitleFocused:       fg(t.Selection).Bold(isMono(t)),
+		PaneTitleFormat:        " %s ",
 
-	ContentPreview lipgloss.Style
+		Glyphs: DefaultGlyphs,
+	}
 }
 
-var DefaultStylesheet = Stylesheet{
-	SelectedPath: lipgloss.NewStyle().Foreground(lipgloss.Color("#74AC6D")),
-
-	FinfoPermissions: lipgloss.NewStyle().Foreground(lipgloss.Color("#ACA46D")),
-	FinfoLastUpdated: lipgloss.NewStyle().Foreground(lipgloss.Color("#E6E6E6")),
-	FinfoSize:        lipgloss.NewStyle().Foreground(lipgloss.Color("#E6E6E6")),
-	FinfoSep:         lipgloss.NewStyle().Foreground(lipgloss.Color("#2b2b2b")),
-
-	OperationBar:      lipgloss.NewStyle().Foreground(lipgloss.Color("#E6E6E6")),
-	OperationBarInput: lipgloss.NewStyle().Background(lipgloss.Color("#3C3C3C")),
-
-	ErrBar:  lipgloss.NewStyle().Foreground(lipgloss.Color("#AC6D74")),
-	HelpMsg: lipgloss.NewStyle().Foreground(lipgloss.Color("#ACA46D")),
-	HelpContent: lipgloss.NewStyle().
-		Foreground(lipgloss.Color("#8c7ca6")).
-		BorderForeground(lipgloss.Color("#8c7ca6")).
-		BorderStyle(lipgloss.NormalBorder()).
-		BorderBottom(true).
-		BorderLeft(true),
-
-	TreeRegularFileName: lipgloss.NewStyle().Foreground(lipgloss.Color("#E6E6E6")),
-	TreeDirecotryName:   lipgloss.NewStyle().Foreground(lipgloss.Color("#6D74AC")),
-	TreeLinkName:        lipgloss.NewStyle().Foreground(lipgloss.Color("#6DACA4")),
-	TreeMarkedNode: lipgloss.NewStyle().
-		BorderLeft(true).
-		BorderStyle(lipgloss.InnerHalfBlockBorder()).
-		Background(lipgloss.Color("#363636")),
-	TreeSelectionArrow: lipgloss.NewStyle().Foreground(lipgloss.Color("#ACA46D")),
-	TreeIndent:         lipgloss.NewStyle().Foreground(lipgloss.Color("#363636")),
-
-	ContentPreview: lipgloss.NewStyle().
-		Italic(true).
-		Foreground(lipgloss.Color("#a8a8a8")).
-		BorderForeground(lipgloss.Color("#363636")).
-		BorderStyle(lipgloss.NormalBorder()).
-		BorderLeft(true),
+// Returns true for themes without colors, that need text attributes instead.
+func isMono(t Theme) bool {
+	_, ok := t.Selection.(lipgloss.NoColor)
+	return ok
 }
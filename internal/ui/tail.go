@@ -0,0 +1,29 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/x/ansi"
+
+	"github.com/LeperGnome/bt/internal/i18n"
+	"github.com/LeperGnome/bt/internal/state"
+)
+
+// Renders the last lines of the followed file, that fit the pane, or the scrolled back ones.
+func (r *Renderer) renderTail(v *state.TailView, height, width int) string {
+	status := r.Style.GrepLocation.Render(i18n.T("ui.tail-following"))
+	if v.Back > 0 {
+		status = fmt.Sprintf(i18n.T("ui.tail-back"), v.Back)
+	}
+	// status line stays on top
+	rows := max(height-1, 0)
+	shown := v.Shown()
+	end := len(shown) - v.Back
+	start := max(end-rows, 0)
+	lines := []string{status}
+	for _, l := range shown[start:end] {
+		lines = append(lines, sanitize(expandTabs(ansi.Strip(strings.TrimSuffix(l, "\r")))))
+	}
+	return r.Style.GrepContent.MaxWidth(width).Render(strings.Join(lines, "\n"))
+}
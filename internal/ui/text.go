@@ -0,0 +1,110 @@
+package ui
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/mattn/go-runewidth"
+)
+
+const tabWidth = 4
+
+// Replaces tabs with spaces up to the next tab stop, counting display cells, not runes.
+func expandTabs(line string) string {
+	if !strings.ContainsRune(line, '\t') {
+		return line
+	}
+	var b strings.Builder
+	col := 0
+	for _, r := range line {
+		if r == '\t' {
+			n := tabWidth - col%tabWidth
+			b.WriteString(strings.Repeat(" ", n))
+			col += n
+			continue
+		}
+		b.WriteRune(r)
+		col += runewidth.RuneWidth(r)
+	}
+	return b.String()
+}
+
+// Cuts line, so it occupies at most width display cells.
+// Wide characters, that don't fit completely, are dropped.
+func truncateToWidth(line string, width int) string {
+	if runewidth.StringWidth(line) <= width {
+		return line
+	}
+	var b strings.Builder
+	col := 0
+	for _, r := range line {
+		w := runewidth.RuneWidth(r)
+		if col+w > width {
+			break
+		}
+		b.WriteRune(r)
+		col += w
+	}
+	return b.String()
+}
+
+// Cuts line to width like truncateToWidth, ending cut lines with the ellipsis.
+func truncateMarked(line string, width int, ellipsis string) string {
+	if runewidth.StringWidth(line) <= width {
+		return line
+	}
+	return truncateToWidth(line, max(width-runewidth.StringWidth(ellipsis), 0)) + ellipsis
+}
+
+// Drops first n display cells of a styled line, keeping escape sequences,
+// so the remaining part is styled as before. Wide character, cut in half, is replaced by a space.
+func cutLeft(line string, n int) string {
+	if n <= 0 {
+		return line
+	}
+	var b strings.Builder
+	col := 0
+	for i := 0; i < len(line); {
+		if line[i] == '\x1b' {
+			j := i + 1
+			if j < len(line) && line[j] == '[' {
+				j++
+				for j < len(line) && (line[j] < 0x40 || line[j] > 0x7e) {
+					j++
+				}
+			}
+			j = min(j+1, len(line))
+			b.WriteString(line[i:j])
+			i = j
+			continue
+		}
+		r, size := utf8.DecodeRuneInString(line[i:])
+		w := runewidth.RuneWidth(r)
+		switch {
+		case col >= n:
+			b.WriteRune(r)
+		case col+w > n:
+			b.WriteString(strings.Repeat(" ", col+w-n))
+		}
+		col += w
+		i += size
+	}
+	return b.String()
+}
+
+// Replaces control characters, e.g. escape sequences in file names, with '?', like ls does,
+// so they neither break layout nor get interpreted by the terminal.
+func sanitize(s string) string {
+	return strings.Map(func(r rune) rune {
+		if unicode.IsControl(r) {
+			return '?'
+		}
+		return r
+	}, s)
+}
+
+// Pads or cuts s to exactly width display cells.
+func fitWidth(s string, width int) string {
+	return runewidth.FillRight(truncateToWidth(s, width), width)
+}
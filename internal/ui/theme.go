@@ -0,0 +1,170 @@
+package ui
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// Theme is a palette, that all styles are derived from.
+type Theme struct {
+	Text          lipgloss.TerminalColor // file names, regular content
+	Muted         lipgloss.TerminalColor // secondary info: sizes, details, inactive titles
+	Preview       lipgloss.TerminalColor // file content
+	Header        lipgloss.TerminalColor // selected path in heading
+	Selection     lipgloss.TerminalColor // selection arrow, focused pane, key hints
+	Marked        lipgloss.TerminalColor // background of node, marked for move / copy
+	Surface       lipgloss.TerminalColor // background of input and active tab
+	Border        lipgloss.TerminalColor // pane borders, tree indent
+	Separator     lipgloss.TerminalColor // separators in file info
+	Directory     lipgloss.TerminalColor
+	Symlink       lipgloss.TerminalColor
+	Error         lipgloss.TerminalColor // errors, removed lines, loops
+	Added         lipgloss.TerminalColor // added lines
+	Info          lipgloss.TerminalColor // cursors, diff hunks
+	HelpKey       lipgloss.TerminalColor
+	HelpText      lipgloss.TerminalColor
+	MarkdownStyle string // glamour standard style
+	CodeStyle     string // chroma style of highlighted preview, empty - no highlighting
+}
+
+var DefaultTheme = Theme{
+	Text:          lipgloss.Color("#E6E6E6"),
+	Muted:         lipgloss.Color("#5c5c5c"),
+	Preview:       lipgloss.Color("#a8a8a8"),
+	Header:        lipgloss.Color("#74AC6D"),
+	Selection:     lipgloss.Color("#ACA46D"),
+	Marked:        lipgloss.Color("#363636"),
+	Surface:       lipgloss.Color("#3C3C3C"),
+	Border:        lipgloss.Color("#363636"),
+	Separator:     lipgloss.Color("#2b2b2b"),
+	Directory:     lipgloss.Color("#6D74AC"),
+	Symlink:       lipgloss.Color("#6DACA4"),
+	Error:         lipgloss.Color("#AC6D74"),
+	Added:         lipgloss.Color("#6DAC74"),
+	Info:          lipgloss.Color("#6DA0AC"),
+	HelpKey:       lipgloss.Color("#b3a4cc"),
+	HelpText:      lipgloss.Color("#8c7ca6"),
+	MarkdownStyle: "dark",
+	CodeStyle:     "monokai",
+}
+
+var LightTheme = Theme{
+	Text:          lipgloss.Color("#2b2b2b"),
+	Muted:         lipgloss.Color("#8a8a8a"),
+	Preview:       lipgloss.Color("#4a4a4a"),
+	Header:        lipgloss.Color("#3F7A37"),
+	Selection:     lipgloss.Color("#8A7F2E"),
+	Marked:        lipgloss.Color("#DADADA"),
+	Surface:       lipgloss.Color("#E0E0E0"),
+	Border:        lipgloss.Color("#C4C4C4"),
+	Separator:     lipgloss.Color("#D4D4D4"),
+	Directory:     lipgloss.Color("#3A44A0"),
+	Symlink:       lipgloss.Color("#2E8479"),
+	Error:         lipgloss.Color("#A0343E"),
+	Added:         lipgloss.Color("#2E8A3A"),
+	Info:          lipgloss.Color("#2E7089"),
+	HelpKey:       lipgloss.Color("#5A3F8A"),
+	HelpText:      lipgloss.Color("#6E5A93"),
+	MarkdownStyle: "light",
+	CodeStyle:     "github",
+}
+
+// Theme without colors, for NO_COLOR and dumb terminals. Selection is still
+// visible thanks to the arrow and text attributes.
+var MonoTheme = Theme{
+	Text:          lipgloss.NoColor{},
+	Muted:         lipgloss.NoColor{},
+	Preview:       lipgloss.NoColor{},
+	Header:        lipgloss.NoColor{},
+	Selection:     lipgloss.NoColor{},
+	Marked:        lipgloss.NoColor{},
+	Surface:       lipgloss.NoColor{},
+	Border:        lipgloss.NoColor{},
+	Separator:     lipgloss.NoColor{},
+	Directory:     lipgloss.NoColor{},
+	Symlink:       lipgloss.NoColor{},
+	Error:         lipgloss.NoColor{},
+	Added:         lipgloss.NoColor{},
+	Info:          lipgloss.NoColor{},
+	HelpKey:       lipgloss.NoColor{},
+	HelpText:      lipgloss.NoColor{},
+	MarkdownStyle: "notty",
+}
+
+var Themes = map[string]Theme{
+	"default": DefaultTheme,
+	"light":   LightTheme,
+	"mono":    MonoTheme,
+}
+
+var hexColor = regexp.MustCompile(`^#([0-9a-fA-F]{3}|[0-9a-fA-F]{6})$`)
+
+// Returns color fields of the theme by their config names.
+func (t *Theme) colors() map[string]*lipgloss.TerminalColor {
+	return map[string]*lipgloss.TerminalColor{
+		"text":      &t.Text,
+		"muted":     &t.Muted,
+		"preview":   &t.Preview,
+		"header":    &t.Header,
+		"selection": &t.Selection,
+		"marked":    &t.Marked,
+		"surface":   &t.Surface,
+		"border":    &t.Border,
+		"separator": &t.Separator,
+		"directory": &t.Directory,
+		"symlink":   &t.Symlink,
+		"error":     &t.Error,
+		"added":     &t.Added,
+		"info":      &t.Info,
+		"help-key":  &t.HelpKey,
+		"help-text": &t.HelpText,
+	}
+}
+
+// Returns true, if colors should not be used: NO_COLOR is set or terminal is dumb.
+func colorDisabled() bool {
+	return os.Getenv("NO_COLOR") != "" || os.Getenv("TERM") == "dumb"
+}
+
+// Resolves theme preset by name (empty - default) and applies color overrides,
+// given as "#rrggbb" or ANSI color numbers. NO_COLOR wins over everything.
+func ResolveTheme(name string, overrides map[string]string) (Theme, error) {
+	if name == "" {
+		name = "default"
+	}
+	theme, ok := Themes[name]
+	if !ok {
+		names := make([]string, 0, len(Themes))
+		for n := range Themes {
+			names = append(names, n)
+		}
+		sort.Strings(names)
+		return theme, fmt.Errorf("unknown theme %q, available: %s", name, strings.Join(names, ", "))
+	}
+	fields := theme.colors()
+	for k, v := range overrides {
+		field, ok := fields[k]
+		if !ok {
+			return theme, fmt.Errorf("unknown theme color %q", k)
+		}
+		if !hexColor.MatchString(v) && !isANSIColor(v) {
+			return theme, fmt.Errorf("theme color %s: bad value %q, expected #rrggbb or 0-255", k, v)
+		}
+		*field = lipgloss.Color(v)
+	}
+	if colorDisabled() {
+		return MonoTheme, nil
+	}
+	return theme, nil
+}
+
+func isANSIColor(v string) bool {
+	var n int
+	_, err := fmt.Sscanf(v, "%d", &n)
+	return err == nil && fmt.Sprint(n) == v && n >= 0 && n <= 255
+}
@@ -0,0 +1,163 @@
+// Package browser embeds bt's tree browser into other Bubble Tea programs.
+//
+// Browser is a component, not a tea.Model: the host program passes messages to Update,
+// sets the size of the area, given to the browser, and places View into its own layout.
+// Keys are bt's default key bindings, q and ctrl+c quit the program, as they do in bt,
+// so hosts filter them out before Update, when that's not wanted.
+package browser
+
+import (
+	"io"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/LeperGnome/bt/internal/state"
+	"github.com/LeperGnome/bt/internal/tree"
+	"github.com/LeperGnome/bt/internal/ui"
+)
+
+// Options of a browser. Zero value is bt's default look.
+type Options struct {
+	Theme    string                  // default, light or mono
+	Colors   map[string]string       // overrides of theme colors by name, e.g. directory: "#6D74AC"
+	Simple   bool                    // no colors and no Unicode glyphs, for screen readers and old terminals
+	Padding  int                     // lines, kept between the cursor and the edges of the tree
+	Preview  bool                    // show file preview next to the tree
+	ReadOnly bool                    // refuse operations, changing files, like bt -read-only
+	OnSelect func(path string) error // called with paths, selected with space
+	OnMove   func(path string)       // called, when the cursor moves to another path
+	OnChange func(path string)       // called, when a file of the tree or entries of a directory change on disk
+	// Don't read or write bt's data directory (~/.local/share/bt): bookmarks and
+	// job throughput are kept in memory, sessions, saved by bt, aren't offered.
+	Ephemeral bool
+}
+
+// Browser is bt's tree browser, rooted in a local directory.
+type Browser struct {
+	state    *state.State
+	renderer *ui.Renderer
+	onMove   func(path string)
+	onChange func(path string)
+	cursor   string
+	width    int
+	height   int
+}
+
+// Reads root and returns a browser of it. The tree is watched for changes, see Init.
+func New(root string, opts Options) (*Browser, error) {
+	style := ui.NewSimpleStylesheet()
+	if !opts.Simple {
+		theme, err := ui.ResolveTheme(opts.Theme, opts.Colors)
+		if err != nil {
+			return nil, err
+		}
+		style = ui.NewStylesheet(theme)
+	}
+	initState := state.InitStateFS
+	if opts.Ephemeral {
+		initState = state.InitStateEphemeral
+	}
+	s, err := initState(tree.OS, root)
+	if err != nil {
+		return nil, err
+	}
+	// saved sessions belong to bt itself, embedded browsers always start fresh
+	s.OpBuf = state.Noop
+	s.ErrBuf = ""
+	s.PreviewToggle = opts.Preview
+	s.SelectionSink = opts.OnSelect
+	s.ReadOnly = opts.ReadOnly
+	b := &Browser{
+		state:    s,
+		renderer: &ui.Renderer{EdgePadding: opts.Padding, Style: style},
+		onMove:   opts.OnMove,
+		onChange: opts.OnChange,
+	}
+	b.cursor = b.Cursor()
+	return b, nil
+}
+
+// Returns command, delivering changes of the tree on disk to Update.
+func (b *Browser) Init() tea.Cmd {
+	return tea.Batch(b.listen(), b.state.LoadPreview())
+}
+
+func (b *Browser) listen() tea.Cmd {
+	changes := b.state.NodeChanges
+	return func() tea.Msg {
+		return <-changes
+	}
+}
+
+// Handles keys, changes of the tree and results of commands, the browser started.
+// Window size messages are ignored, the host decides the size with SetSize.
+func (b *Browser) Update(msg tea.Msg) tea.Cmd {
+	defer b.notifyMove()
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		return nil
+	case tea.KeyMsg:
+		return b.state.ProcessKey(msg)
+	case tree.NodeChange:
+		if b.onChange != nil {
+			b.onChange(msg.Path)
+		}
+		return tea.Batch(b.state.ProcessNodeChange(msg), b.listen())
+	default:
+		return b.state.ProcessMsg(msg)
+	}
+}
+
+func (b *Browser) notifyMove() {
+	cursor := b.Cursor()
+	if cursor == b.cursor {
+		return
+	}
+	b.cursor = cursor
+	if b.onMove != nil {
+		b.onMove(cursor)
+	}
+}
+
+// Sets the size of the area, taken by the browser.
+func (b *Browser) SetSize(width, height int) {
+	b.width, b.height = width, height
+	b.state.SetWindowSize(height, width)
+}
+
+// Renders the browser for the area, set by SetSize.
+func (b *Browser) View() string {
+	return b.renderer.Render(b.state, b.height, b.width)
+}
+
+// Writes the rendered browser to w, e.g. for snapshots of the view.
+func (b *Browser) Render(w io.Writer) error {
+	_, err := io.WriteString(w, b.View())
+	return err
+}
+
+// Returns path under the cursor, empty in an empty directory.
+func (b *Browser) Cursor() string {
+	if n := b.state.Tree.GetSelectedChild(); n != nil {
+		return n.Path
+	}
+	return ""
+}
+
+// Returns the tree, as the browser has read it: expanded directories and the ones,
+// expanded before, have children, other directories have none.
+func (b *Browser) Tree() Node {
+	return toNode(b.state.Tree.Root)
+}
+
+// Returns paths, selected with space, in order.
+func (b *Browser) Selected() []string {
+	return b.state.SelectedPaths()
+}
+
+// Moves the cursor to path, expanding directories on the way to it.
+// Path outside of the root makes its directory the new root.
+func (b *Browser) Reveal(path string) {
+	defer b.notifyMove()
+	b.state.ProcessMsg(state.RevealRequest{Path: path})
+}
@@ -0,0 +1,54 @@
+package browser_test
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/LeperGnome/bt/pkg/browser"
+)
+
+func ExampleWalk() {
+	root, err := os.MkdirTemp("", "bt-example")
+	if err != nil {
+		panic(err)
+	}
+	defer os.RemoveAll(root)
+	os.MkdirAll(filepath.Join(root, "docs", "api"), 0o755)
+	os.WriteFile(filepath.Join(root, "docs", "index.md"), nil, 0o644)
+	os.WriteFile(filepath.Join(root, "main.go"), nil, 0o644)
+
+	tree, err := browser.Walk(root, 0, nil)
+	if err != nil {
+		panic(err)
+	}
+	tree.Walk(func(n browser.Node, depth int) bool {
+		if depth > 0 {
+			fmt.Println(strings.Repeat("  ", depth-1) + n.Info.Name())
+		}
+		return true
+	})
+	// Output:
+	// docs
+	//   api
+	//   index.md
+	// main.go
+}
+
+// Browser is placed into the host's own model, which passes it messages and its share of the window.
+func ExampleNew() {
+	b, err := browser.New(".", browser.Options{
+		Ephemeral: true,
+		OnMove:    func(path string) { fmt.Println("cursor at", path) },
+	})
+	if err != nil {
+		panic(err)
+	}
+	b.SetSize(80, 24)
+	b.Init() // commands of the browser are returned from the host's Init and Update, along with its own
+	b.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'j'}})
+	b.Render(os.Stdout)
+}
@@ -0,0 +1,51 @@
+package browser
+
+import (
+	"io/fs"
+
+	"github.com/LeperGnome/bt/internal/tree"
+)
+
+// Node is a file or directory of a walked tree. Children are sorted like in bt, directories first.
+type Node struct {
+	Path     string
+	Info     fs.FileInfo
+	Children []Node // nil - a file, or a directory deeper than the walk went
+}
+
+// Reads directory tree down to depth levels (0 - bt's default limit), like bt -print does.
+// Unreadable directories are reported to onErr, if it's set, and left without children.
+// Symlinks to directories are not descended into.
+func Walk(root string, depth int, onErr func(path string, err error)) (Node, error) {
+	n, err := tree.Walk(root, depth, onErr)
+	if err != nil {
+		return Node{}, err
+	}
+	return toNode(n), nil
+}
+
+func toNode(n *tree.Node) Node {
+	node := Node{Path: n.Path, Info: n.Info}
+	if n.Children != nil {
+		node.Children = make([]Node, len(n.Children))
+		for i, ch := range n.Children {
+			node.Children[i] = toNode(ch)
+		}
+	}
+	return node
+}
+
+// Calls fn for the node and its descendants depth first, with depth of each below the node.
+// Children of a node are skipped, when fn returns false for it.
+func (n Node) Walk(fn func(n Node, depth int) bool) {
+	n.walk(fn, 0)
+}
+
+func (n Node) walk(fn func(n Node, depth int) bool, depth int) {
+	if !fn(n, depth) {
+		return
+	}
+	for _, ch := range n.Children {
+		ch.walk(fn, depth+1)
+	}
+}
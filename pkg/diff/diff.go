@@ -0,0 +1,161 @@
+// Package diff compares lines of text with Myers' algorithm and formats unified diffs.
+package diff
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Kinds of edit operations.
+const (
+	Equal  = ' '
+	Delete = '-'
+	Insert = '+'
+)
+
+// Op is a line, kept, deleted from a or inserted from b.
+type Op struct {
+	Kind byte
+	Text string
+}
+
+// Edits, above that, are not searched for: a is replaced with b as a whole.
+const MaxEdits = 2000
+
+// Returns the shortest edit script, turning a into b.
+func Lines(a, b []string) []Op {
+	n, m := len(a), len(b)
+	limit := min(n+m, MaxEdits)
+	offset := limit + 1
+	v := make([]int, 2*limit+3)
+	// values of v for k in [-d, d] after each step d, for backtracking
+	trace := [][]int{}
+	for d := 0; d <= limit; d++ {
+		for k := -d; k <= d; k += 2 {
+			var x int
+			if k == -d || (k != d && v[offset+k-1] < v[offset+k+1]) {
+				x = v[offset+k+1] // down: insertion
+			} else {
+				x = v[offset+k-1] + 1 // right: deletion
+			}
+			y := x - k
+			for x < n && y < m && a[x] == b[y] {
+				x++
+				y++
+			}
+			v[offset+k] = x
+			if x >= n && y >= m {
+				trace = append(trace, append([]int(nil), v[offset-d:offset+d+1]...))
+				return backtrack(a, b, trace)
+			}
+		}
+		trace = append(trace, append([]int(nil), v[offset-d:offset+d+1]...))
+	}
+	ops := make([]Op, 0, n+m)
+	for _, line := range a {
+		ops = append(ops, Op{Delete, line})
+	}
+	for _, line := range b {
+		ops = append(ops, Op{Insert, line})
+	}
+	return ops
+}
+
+func backtrack(a, b []string, trace [][]int) []Op {
+	ops := []Op{}
+	x, y := len(a), len(b)
+	for d := len(trace) - 1; d > 0; d-- {
+		prev := trace[d-1] // k in [-(d-1), d-1] at index k+d-1
+		at := func(k int) int { return prev[k+d-1] }
+		k := x - y
+		prevK := k - 1
+		if k == -d || (k != d && at(k-1) < at(k+1)) {
+			prevK = k + 1
+		}
+		prevX := at(prevK)
+		prevY := prevX - prevK
+		for x > prevX && y > prevY {
+			x--
+			y--
+			ops = append(ops, Op{Equal, a[x]})
+		}
+		if x == prevX {
+			y--
+			ops = append(ops, Op{Insert, b[y]})
+		} else {
+			x--
+			ops = append(ops, Op{Delete, a[x]})
+		}
+	}
+	for x > 0 && y > 0 {
+		x--
+		y--
+		ops = append(ops, Op{Equal, a[x]})
+	}
+	for i, j := 0, len(ops)-1; i < j; i, j = i+1, j-1 {
+		ops[i], ops[j] = ops[j], ops[i]
+	}
+	return ops
+}
+
+// Splits text into lines, final newline doesn't start a new line.
+func Split(text string) []string {
+	if text == "" {
+		return nil
+	}
+	return strings.Split(strings.TrimSuffix(text, "\n"), "\n")
+}
+
+// Returns unified diff of a and b with context lines around changes, empty if they are equal.
+func Unified(nameA, nameB string, a, b []string, context int) string {
+	ops := Lines(a, b)
+	var sb strings.Builder
+	// line numbers in a and b before each op
+	lineA, lineB := make([]int, len(ops)+1), make([]int, len(ops)+1)
+	for i, op := range ops {
+		lineA[i+1], lineB[i+1] = lineA[i], lineB[i]
+		if op.Kind != Insert {
+			lineA[i+1]++
+		}
+		if op.Kind != Delete {
+			lineB[i+1]++
+		}
+	}
+	for i := 0; i < len(ops); {
+		if ops[i].Kind == Equal {
+			i++
+			continue
+		}
+		start := max(i-context, 0)
+		end := i // exclusive, extended while changes are close enough
+		for j := i; j < len(ops) && j < end+2*context+1; j++ {
+			if ops[j].Kind != Equal {
+				end = j + 1
+			}
+		}
+		end = min(end+context, len(ops))
+		if sb.Len() == 0 {
+			fmt.Fprintf(&sb, "--- %s\n+++ %s\n", nameA, nameB)
+		}
+		fmt.Fprintf(&sb, "@@ -%s +%s @@\n",
+			hunkRange(lineA[start], lineA[end]), hunkRange(lineB[start], lineB[end]))
+		for _, op := range ops[start:end] {
+			sb.WriteByte(op.Kind)
+			sb.WriteString(op.Text)
+			sb.WriteByte('\n')
+		}
+		i = end
+	}
+	return sb.String()
+}
+
+// Formats lines [from, to) as "start,count", where start is 1-based or the line before an empty range.
+func hunkRange(from, to int) string {
+	if to == from {
+		return fmt.Sprintf("%d,0", from)
+	}
+	if to-from == 1 {
+		return fmt.Sprint(from + 1)
+	}
+	return fmt.Sprintf("%d,%d", from+1, to-from)
+}
@@ -0,0 +1,78 @@
+// Package paths builds and compares file system paths the same way across bt.
+// Paths are cleaned first, so trailing slashes and ".." elements never change the answer,
+// and the root directory needs no special casing.
+package paths
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// Joins elements to dir and cleans the result. Joining to the root gives "/name", not "//name".
+func Join(dir string, elem ...string) string {
+	return filepath.Join(append([]string{dir}, elem...)...)
+}
+
+// Returns directory, containing p. The root is its own parent.
+func Parent(p string) string {
+	return filepath.Dir(filepath.Clean(p))
+}
+
+// Returns path of p relative to root, if p is root itself or is inside of it.
+// Both paths must be either absolute or relative.
+func Rel(root, p string) (string, bool) {
+	rel, err := filepath.Rel(filepath.Clean(root), filepath.Clean(p))
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", false
+	}
+	return rel, true
+}
+
+// Reports whether p is root itself or is inside of it.
+func Within(root, p string) bool {
+	_, ok := Rel(root, p)
+	return ok
+}
+
+// Returns elements of a relative path, e.g. [a b] for "a/b/". Current directory has none.
+func Split(rel string) []string {
+	rel = filepath.Clean(rel)
+	if rel == "." {
+		return nil
+	}
+	return strings.Split(rel, string(filepath.Separator))
+}
+
+// Returns absolute path of p with symlinks in its directories resolved.
+// The final element is kept as is, so a symlink is not confused with its target.
+// Directories, that don't exist, are kept as well.
+func Resolve(p string) (string, error) {
+	abs, err := filepath.Abs(p)
+	if err != nil {
+		return "", err
+	}
+	dir, name := filepath.Dir(abs), filepath.Base(abs)
+	if dir == abs {
+		return abs, nil // root
+	}
+	// the closest existing ancestor is resolved, the rest is appended
+	missing := []string{name}
+	for {
+		resolved, err := filepath.EvalSymlinks(dir)
+		if err == nil {
+			slices.Reverse(missing)
+			return Join(resolved, missing...), nil
+		}
+		if !os.IsNotExist(err) {
+			return "", err
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return abs, nil
+		}
+		missing = append(missing, filepath.Base(dir))
+		dir = parent
+	}
+}
@@ -0,0 +1,18 @@
+//go:build !windows
+
+package paths
+
+// Returns roots of mounted drives. There are none outside of Windows, everything is under "/".
+func Drives() []string {
+	return nil
+}
+
+// Checks, that a file can be given the name. Any name without separators will do.
+func CheckName(name string) error {
+	return nil
+}
+
+// Returns p, that can be passed to the system. Length of paths isn't limited here.
+func Long(p string) string {
+	return p
+}
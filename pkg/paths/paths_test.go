@@ -0,0 +1,101 @@
+package paths
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+var seeds = []string{"", ".", "..", "/", "//", "a", "a/", "a/b", "a/../b", "../a", "a/./b/", "a//b", "...", ".hidden"}
+
+func FuzzJoinParent(f *testing.F) {
+	for _, s := range seeds {
+		f.Add(s, "name")
+		f.Add(s, s)
+	}
+	f.Fuzz(func(t *testing.T, dir, name string) {
+		dir = "/" + dir
+		joined := Join(dir, name)
+		if joined != filepath.Clean(joined) {
+			t.Errorf("Join(%q, %q) = %q is not clean", dir, name, joined)
+		}
+		if strings.Contains(joined, "//") {
+			t.Errorf("Join(%q, %q) = %q has double separator", dir, name, joined)
+		}
+		// single element goes one level down, its parent is the directory
+		if name != "" && name != "." && name != ".." && !strings.Contains(name, "/") {
+			if p := Parent(joined); p != filepath.Clean(dir) {
+				t.Errorf("Parent(Join(%q, %q)) = %q, want %q", dir, name, p, filepath.Clean(dir))
+			}
+		}
+	})
+}
+
+func FuzzWithin(f *testing.F) {
+	for _, root := range seeds {
+		for _, rel := range seeds {
+			f.Add(root, rel)
+		}
+	}
+	f.Fuzz(func(t *testing.T, root, rel string) {
+		root = "/" + root // bt compares absolute paths
+		p := Join(root, rel)
+		got, ok := Rel(root, p)
+		if ok != Within(root, p) {
+			t.Fatalf("Rel and Within disagree on %q in %q", p, root)
+		}
+		local := rel == "" || filepath.Clean(rel) == "." || filepath.IsLocal(rel)
+		if local && !ok {
+			t.Errorf("%q is not within %q", p, root)
+		}
+		if ok && Join(root, Split(got)...) != p {
+			t.Errorf("Join(%q, Split(%q)) != %q", root, got, p)
+		}
+		// trailing slashes don't matter
+		if Within(root+"/", p+"/") != ok {
+			t.Errorf("trailing slash changes Within(%q, %q)", root, p)
+		}
+		// a sibling with the same prefix is outside
+		if sibling := filepath.Clean(root) + "x"; filepath.Clean(root) != "/" && Within(root, sibling) {
+			t.Errorf("%q is within %q", sibling, root)
+		}
+		if filepath.Clean(root) != "/" && Within(root, Parent(root)) {
+			t.Errorf("parent of %q is within it", root)
+		}
+	})
+}
+
+func TestResolve(t *testing.T) {
+	dir, err := filepath.EvalSymlinks(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	real := filepath.Join(dir, "real")
+	link := filepath.Join(dir, "link")
+	if err := os.Mkdir(real, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink(real, link); err != nil {
+		t.Skip("symlinks are not supported:", err)
+	}
+	cases := map[string]string{
+		link:                            link, // final symlink is kept
+		link + "/":                      link,
+		filepath.Join(link, "f"):        filepath.Join(real, "f"),
+		filepath.Join(link, "a", "b"):   filepath.Join(real, "a", "b"), // missing directories
+		filepath.Join(link, "..", "x"):  filepath.Join(dir, "x"),       // cleaned before resolving
+		filepath.Join(dir, "none", "f"): filepath.Join(dir, "none", "f"),
+		"/":                             "/",
+	}
+	for in, want := range cases {
+		got, err := Resolve(in)
+		if err != nil {
+			t.Errorf("Resolve(%q): %v", in, err)
+			continue
+		}
+		if got != want {
+			t.Errorf("Resolve(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
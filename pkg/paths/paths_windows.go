@@ -0,0 +1,69 @@
+//go:build windows
+
+package paths
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Paths at least this long need the \\?\ prefix to get past MAX_PATH.
+const longPathLen = 248
+
+// Names of devices, reserved in every directory, with any extension.
+var reservedNames = map[string]bool{
+	"CON": true, "PRN": true, "AUX": true, "NUL": true,
+	"COM1": true, "COM2": true, "COM3": true, "COM4": true, "COM5": true, "COM6": true, "COM7": true, "COM8": true, "COM9": true,
+	"LPT1": true, "LPT2": true, "LPT3": true, "LPT4": true, "LPT5": true, "LPT6": true, "LPT7": true, "LPT8": true, "LPT9": true,
+}
+
+// Returns roots of mounted drives, e.g. C:\ and D:\.
+func Drives() []string {
+	drives := []string{}
+	for letter := 'A'; letter <= 'Z'; letter++ {
+		root := string(letter) + `:\`
+		if _, err := os.Stat(root); err == nil {
+			drives = append(drives, root)
+		}
+	}
+	return drives
+}
+
+// Checks, that a file can be given the name: device names, e.g. NUL or com1.txt,
+// characters, forbidden by Windows, and trailing dots or spaces are rejected.
+func CheckName(name string) error {
+	stem, _, _ := strings.Cut(name, ".")
+	if reservedNames[strings.ToUpper(strings.TrimRight(stem, " "))] {
+		return fmt.Errorf("'%s' is a reserved name", name)
+	}
+	if i := strings.IndexAny(name, `<>:"|?*`); i >= 0 {
+		return fmt.Errorf("'%s' contains forbidden character '%c'", name, name[i])
+	}
+	for _, r := range name {
+		if r < ' ' {
+			return fmt.Errorf("'%s' contains a control character", name)
+		}
+	}
+	if name != "." && name != ".." && strings.TrimRight(name, ". ") != name {
+		return fmt.Errorf("'%s' ends with a dot or a space", name)
+	}
+	return nil
+}
+
+// Returns p, that can be passed to the system even past MAX_PATH: long paths are made absolute
+// and get the \\?\ prefix. Go adds it on its own for absolute paths only.
+func Long(p string) string {
+	if len(p) < longPathLen || strings.HasPrefix(p, `\\?\`) {
+		return p
+	}
+	abs, err := filepath.Abs(p)
+	if err != nil {
+		return p
+	}
+	if rest, ok := strings.CutPrefix(abs, `\\`); ok {
+		return `\\?\UNC\` + rest
+	}
+	return `\\?\` + abs
+}